@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const jsonSchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// jsonSchema renders a JSON Schema document. When goPackage is set, the
+// document is rendered as a Go source file of the package.
+type jsonSchema struct {
+	goPackage string
+}
+
+type schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 interface{}        `json:"type,omitempty"`
+	Properties           map[string]*schema `json:"properties,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"`
+	Items                *schema            `json:"items,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Default              interface{}        `json:"default,omitempty"`
+	Deprecated           bool               `json:"deprecated,omitempty"`
+}
+
+func (r jsonSchema) render(docs []doc) {
+	root := &schema{
+		Schema:               jsonSchemaDraft,
+		Title:                "Kismatic plan file",
+		Type:                 "object",
+		Properties:           map[string]*schema{},
+		AdditionalProperties: false,
+	}
+	for _, d := range docs {
+		props := strings.Split(d.property, ".")
+		parent := root
+		for _, p := range props[:len(props)-1] {
+			parent = parent.Properties[p]
+			// the properties of the elements of a list are the properties of the items
+			if parent.Items != nil {
+				parent = parent.Items
+			}
+		}
+		name := props[len(props)-1]
+		s, err := schemaForDoc(d)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error rendering property %s: %v\n", d.property, err)
+			os.Exit(1)
+		}
+		parent.Properties[name] = s
+		if d.required {
+			parent.Required = append(parent.Required, name)
+		}
+	}
+
+	b, err := json.MarshalIndent(root, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error marshalling JSON schema: %v\n", err)
+		os.Exit(1)
+	}
+	if r.goPackage == "" {
+		fmt.Println(string(b))
+		return
+	}
+	fmt.Println("// Code generated by gen-kismatic-ref-docs. DO NOT EDIT.")
+	fmt.Println()
+	fmt.Printf("package %s\n", r.goPackage)
+	fmt.Println()
+	fmt.Println("// PlanJSONSchema is the JSON Schema of the plan file")
+	fmt.Printf("const PlanJSONSchema = %s\n", strconv.Quote(string(b)+"\n"))
+}
+
+func schemaForDoc(d doc) (*schema, error) {
+	s := schemaForType(d.propertyType)
+	s.Description = strings.TrimSpace(d.description)
+	s.Deprecated = d.deprecated
+	if len(d.options) > 0 {
+		for _, o := range d.options {
+			v, err := typedValue(d.propertyType, o)
+			if err != nil {
+				return nil, err
+			}
+			s.Enum = append(s.Enum, v)
+		}
+		// optional fields can be left empty
+		if !d.required && d.propertyType == "string" {
+			s.Enum = append(s.Enum, "")
+		}
+	}
+	if d.defaultValue != "" {
+		v, err := typedValue(d.propertyType, d.defaultValue)
+		if err != nil {
+			return nil, err
+		}
+		s.Default = v
+	}
+	return s, nil
+}
+
+// schemaForType returns the schema of a value of the type. Lists, maps and
+// objects can be null, as they are when the field is left empty in the plan file.
+func schemaForType(t string) *schema {
+	switch {
+	case t == "string":
+		return &schema{Type: "string"}
+	case t == "int":
+		return &schema{Type: "integer"}
+	case t == "bool":
+		return &schema{Type: "boolean"}
+	case strings.HasPrefix(t, "[]"):
+		return &schema{Type: []string{"array", "null"}, Items: schemaForType(t[2:])}
+	case strings.HasPrefix(t, "map["):
+		return &schema{Type: []string{"object", "null"}, AdditionalProperties: schemaForType(t[strings.Index(t, "]")+1:])}
+	case strings.Contains(t, "."):
+		// types of other packages, e.g. yaml.MapSlice, are not documented
+		return &schema{Type: []string{"object", "null"}}
+	default:
+		return &schema{Type: []string{"object", "null"}, Properties: map[string]*schema{}, AdditionalProperties: false}
+	}
+}
+
+func typedValue(t string, v string) (interface{}, error) {
+	switch t {
+	case "int":
+		return strconv.Atoi(v)
+	case "bool":
+		return strconv.ParseBool(v)
+	default:
+		return v, nil
+	}
+}
@@ -34,26 +34,30 @@ func main() {
 	file := flag.Arg(0)
 	typeName := flag.Arg(1)
 
+	fset := token.NewFileSet()
+	m := make(map[string]*ast.File)
+
+	f, err := parser.ParseFile(fset, file, nil, parser.ParseComments)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error parsing file: %v\n", err)
+		os.Exit(1)
+	}
+
 	var r renderer
 	switch *output {
 	case "markdown":
 		r = markdown{}
 	case "markdown-table":
 		r = markdownTable{}
+	case "jsonschema":
+		r = jsonSchema{}
+	case "jsonschema-go":
+		r = jsonSchema{goPackage: f.Name.Name}
 	default:
 		fmt.Fprintf(os.Stderr, "unknown output type: %s\n", *output)
 		os.Exit(1)
 	}
 
-	fset := token.NewFileSet()
-	m := make(map[string]*ast.File)
-
-	f, err := parser.ParseFile(fset, file, nil, parser.ParseComments)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "error parsing file: %v\n", err)
-		os.Exit(1)
-	}
-
 	m[file] = f
 	apkg, _ := ast.NewPackage(fset, m, nil, nil) // error deliberately ignored
 	pkgDoc := godoc.New(apkg, "", 0)
@@ -124,7 +128,13 @@ func docForType(typeName string, allTypes []*godoc.Type, parentFieldName string)
 					// In the case of an array type, use []+typeName as the type
 					// Recurse if it is an array of non-basic types.
 					case *ast.ArrayType:
-						typeName = x.Elt.(*ast.Ident).Name
+						switch elt := x.Elt.(type) {
+						case *ast.SelectorExpr:
+							// Types of other packages, e.g. yaml.MapSlice
+							typeName = elt.X.(*ast.Ident).Name + "." + elt.Sel.Name
+						default:
+							typeName = elt.(*ast.Ident).Name
+						}
 						d, err := parseDoc(fieldName, "[]"+typeName, f.Doc.Text())
 						if err != nil {
 							panic(err)
@@ -209,4 +219,5 @@ var basicTypes = map[string]bool{
 	"int":               true,
 	"string":            true,
 	"map[string]string": true,
+	"map[string]bool":   true,
 }
@@ -8,6 +8,7 @@ import (
 
 	"github.com/apprenda/kismatic/pkg/cli"
 	"github.com/apprenda/kismatic/pkg/install"
+	"github.com/apprenda/kismatic/pkg/ssh"
 	"github.com/apprenda/kismatic/pkg/util"
 )
 
@@ -26,7 +27,10 @@ func main() {
 		util.PrintColor(os.Stderr, util.Red, "Error initializing command: %v\n", err)
 		os.Exit(1)
 	}
-	if err := cmd.Execute(); err != nil {
+	err = cmd.Execute()
+	// close the SSH connections, and stop the SSH agent serving encrypted keys
+	ssh.CloseConnections()
+	if err != nil {
 		util.PrintColor(os.Stderr, util.Red, "%v\n", err)
 		os.Exit(1)
 	}
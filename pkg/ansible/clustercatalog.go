@@ -24,6 +24,7 @@ type ClusterCatalog struct {
 	KubeSchedulerOptions         map[string]string `yaml:"kube_scheduler_option_overrides"`
 	KubeProxyOptions             map[string]string `yaml:"kube_proxy_option_overrides"`
 	KubeletOptions               map[string]string `yaml:"kubelet_overrides"`
+	FeatureGates                 string            `yaml:"kubernetes_feature_gates"`
 
 	ConfigureDockerWithPrivateRegistry bool   `yaml:"configure_docker_with_private_registry"`
 	DockerRegistryCAPath               string `yaml:"docker_certificates_ca_path"`
@@ -46,6 +47,10 @@ type ClusterCatalog struct {
 
 	WorkerNode string `yaml:"worker_node"`
 
+	NewEtcdNode string `yaml:"new_etcd_node"`
+
+	NodeToRemove string `yaml:"node_to_remove"`
+
 	NFSVolumes []NFSVolume `yaml:"nfs_volumes"`
 
 	EnableGluster bool `yaml:"configure_storage"`
@@ -78,6 +83,27 @@ type ClusterCatalog struct {
 	CloudProvider string `yaml:"cloud_provider"`
 	CloudConfig   string `yaml:"cloud_config_local"`
 
+	APIServerAudit struct {
+		Enabled           bool
+		PolicyFile        string `yaml:"policy_file_local"`
+		LogPath           string `yaml:"log_path"`
+		LogMaxAge         int    `yaml:"log_max_age"`
+		LogMaxBackups     int    `yaml:"log_max_backups"`
+		WebhookConfigFile string `yaml:"webhook_config_file_local"`
+		WebhookMode       string `yaml:"webhook_mode"`
+	} `yaml:"kube_apiserver_audit"`
+
+	SecretsEncryptionConfig string `yaml:"secrets_encryption_config_local"`
+
+	APIServerOIDC struct {
+		Enabled       bool
+		IssuerURL     string `yaml:"issuer_url"`
+		ClientID      string `yaml:"client_id"`
+		UsernameClaim string `yaml:"username_claim"`
+		GroupsClaim   string `yaml:"groups_claim"`
+		CAFile        string `yaml:"ca_file_local"`
+	} `yaml:"kube_apiserver_oidc"`
+
 	DNS struct {
 		Enabled bool
 	}
@@ -147,6 +173,31 @@ func (c *ClusterCatalog) EnableRestart() {
 	c.ForceDockerRestart = true
 }
 
+// RestartedServices returns the services that are forcefully restarted
+// when running a playbook with the catalog
+func (c *ClusterCatalog) RestartedServices() []string {
+	flags := []struct {
+		service string
+		force   bool
+	}{
+		{"etcd", c.ForceEtcdRestart},
+		{"kube-apiserver", c.ForceAPIServerRestart},
+		{"kube-controller-manager", c.ForceControllerManagerRestart},
+		{"kube-scheduler", c.ForceSchedulerRestart},
+		{"kube-proxy", c.ForceProxyRestart},
+		{"kubelet", c.ForceKubeletRestart},
+		{"calico-node", c.ForceCalicoNodeRestart},
+		{"docker", c.ForceDockerRestart},
+	}
+	services := []string{}
+	for _, f := range flags {
+		if f.force {
+			services = append(services, f.service)
+		}
+	}
+	return services
+}
+
 func (c *ClusterCatalog) ToYAML() ([]byte, error) {
 	bytez, marshalErr := yaml.Marshal(c)
 	if marshalErr != nil {
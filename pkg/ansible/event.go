@@ -10,7 +10,8 @@ type namedEvent struct {
 	Name string
 }
 
-type runnerResult struct {
+// RunnerResult is the result of running a task on a host
+type RunnerResult struct {
 	// Command is the command that was run
 	Command []string `json:"cmd"`
 	// Stdout captured when the command was run
@@ -30,7 +31,7 @@ type runnerResult struct {
 
 type runnerResultEvent struct {
 	Host         string
-	Result       runnerResult
+	Result       RunnerResult
 	IgnoreErrors bool
 }
 
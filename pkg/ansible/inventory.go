@@ -3,6 +3,7 @@ package ansible
 import (
 	"bytes"
 	"fmt"
+	"strings"
 )
 
 // Inventory is a collection of Nodes, keyed by role.
@@ -32,6 +33,12 @@ type Node struct {
 	SSHPort int
 	// SSHUser is the SSH user for logging into the node
 	SSHUser string
+	// SSHProxyCommand is the command used to tunnel the SSH connection to the node,
+	// when the node is accessed through a bastion host
+	SSHProxyCommand string
+	// SSHKnownHostsFile is the known_hosts file used for verifying the host key
+	// of the node. The host key is not verified when empty.
+	SSHKnownHostsFile string
 }
 
 // ToINI converts the inventory into INI format
@@ -44,9 +51,42 @@ func (i Inventory) ToINI() []byte {
 			if n.InternalIP != "" {
 				internalIP = n.InternalIP
 			}
-			fmt.Fprintf(w, "%q ansible_host=%q internal_ipv4=%q ansible_ssh_private_key_file=%q ansible_port=%d ansible_user=%q\n", n.Host, n.PublicIP, internalIP, n.SSHPrivateKey, n.SSHPort, n.SSHUser)
+			fmt.Fprintf(w, "%q ansible_host=%q internal_ipv4=%q", n.Host, n.PublicIP, internalIP)
+			// authenticate with the keys of the SSH agent when there is no key
+			if n.SSHPrivateKey != "" {
+				fmt.Fprintf(w, " ansible_ssh_private_key_file=%q", n.SSHPrivateKey)
+			}
+			fmt.Fprintf(w, " ansible_port=%d ansible_user=%q", n.SSHPort, n.SSHUser)
+			if args := n.sshCommonArgs(); len(args) > 0 {
+				fmt.Fprintf(w, " ansible_ssh_common_args=%q", strings.Join(args, " "))
+			}
+			fmt.Fprintln(w)
 		}
 	}
 
 	return w.Bytes()
 }
+
+// hostKeyChecking returns true if the host keys of the nodes must be verified
+func (i Inventory) hostKeyChecking() bool {
+	for _, role := range i.Roles {
+		for _, n := range role.Nodes {
+			if n.SSHKnownHostsFile != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sshCommonArgs returns the arguments added to the ssh commands run by Ansible
+func (n Node) sshCommonArgs() []string {
+	args := []string{}
+	if n.SSHProxyCommand != "" {
+		args = append(args, fmt.Sprintf("-o ProxyCommand=%q", n.SSHProxyCommand))
+	}
+	if n.SSHKnownHostsFile != "" {
+		args = append(args, "-o StrictHostKeyChecking=yes", fmt.Sprintf("-o UserKnownHostsFile=%q", n.SSHKnownHostsFile))
+	}
+	return args
+}
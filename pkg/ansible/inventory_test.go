@@ -70,3 +70,97 @@ func TestInventoryINIGeneration(t *testing.T) {
 	}
 
 }
+
+func TestInventoryINIGenerationWithProxyCommand(t *testing.T) {
+	inv := Inventory{
+		Roles: []Role{
+			{
+				Name: "etcd",
+				Nodes: []Node{
+					{
+						Host:            "etcd01",
+						PublicIP:        "10.0.0.1",
+						InternalIP:      "192.168.0.11",
+						SSHPrivateKey:   "id_rsa",
+						SSHPort:         22,
+						SSHUser:         "alice",
+						SSHProxyCommand: "ssh -W %h:%p 'alice@bastion'",
+					},
+				},
+			},
+		},
+	}
+
+	ini := string(inv.ToINI())
+
+	expected := `[etcd]
+"etcd01" ansible_host="10.0.0.1" internal_ipv4="192.168.0.11" ansible_ssh_private_key_file="id_rsa" ansible_port=22 ansible_user="alice" ansible_ssh_common_args="-o ProxyCommand=\"ssh -W %h:%p 'alice@bastion'\""
+`
+
+	if ini != expected {
+		t.Errorf("expected format differs from obtained format. Expected: \n%s\nGot: \n%s\n", expected, ini)
+	}
+}
+
+func TestInventoryINIGenerationWithKnownHostsFile(t *testing.T) {
+	inv := Inventory{
+		Roles: []Role{
+			{
+				Name: "etcd",
+				Nodes: []Node{
+					{
+						Host:              "etcd01",
+						PublicIP:          "10.0.0.1",
+						InternalIP:        "192.168.0.11",
+						SSHPrivateKey:     "id_rsa",
+						SSHPort:           22,
+						SSHUser:           "alice",
+						SSHKnownHostsFile: "/generated/known_hosts",
+					},
+				},
+			},
+		},
+	}
+	if !inv.hostKeyChecking() {
+		t.Errorf("expected host key checking to be enabled")
+	}
+
+	ini := string(inv.ToINI())
+
+	expected := `[etcd]
+"etcd01" ansible_host="10.0.0.1" internal_ipv4="192.168.0.11" ansible_ssh_private_key_file="id_rsa" ansible_port=22 ansible_user="alice" ansible_ssh_common_args="-o StrictHostKeyChecking=yes -o UserKnownHostsFile=\"/generated/known_hosts\""
+`
+
+	if ini != expected {
+		t.Errorf("expected format differs from obtained format. Expected: \n%s\nGot: \n%s\n", expected, ini)
+	}
+}
+
+func TestInventoryINIGenerationWithoutKey(t *testing.T) {
+	inv := Inventory{
+		Roles: []Role{
+			{
+				Name: "etcd",
+				Nodes: []Node{
+					{
+						Host:       "etcd01",
+						PublicIP:   "10.0.0.1",
+						InternalIP: "192.168.0.11",
+						SSHPort:    22,
+						SSHUser:    "alice",
+					},
+				},
+			},
+		},
+	}
+
+	ini := string(inv.ToINI())
+
+	expected := `[etcd]
+"etcd01" ansible_host="10.0.0.1" internal_ipv4="192.168.0.11" ansible_port=22 ansible_user="alice"
+`
+
+	if ini != expected {
+		t.Errorf("expected format differs from obtained format. Expected: \n%s\nGot: \n%s\n", expected, ini)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
@@ -49,10 +50,14 @@ type runner struct {
 	runDir       string
 	waitPlaybook func() error
 	namedPipe    string
+	// sshAuthSock is the socket of the SSH agent used by Ansible. The
+	// SSH_AUTH_SOCK of the current environment is used when empty.
+	sshAuthSock string
 }
 
 // NewRunner returns a new runner for running Ansible playbooks.
-func NewRunner(out, errOut io.Writer, ansibleDir string, runDir string) (Runner, error) {
+// Ansible authenticates with the SSH agent listening on sshAuthSock, if set.
+func NewRunner(out, errOut io.Writer, ansibleDir string, runDir string, sshAuthSock string) (Runner, error) {
 	// Ansible depends on python 2.7 being installed and on the path as "python".
 	// Validate that it is available
 	if _, err := exec.LookPath("python"); err != nil {
@@ -65,11 +70,12 @@ func NewRunner(out, errOut io.Writer, ansibleDir string, runDir string) (Runner,
 	}
 
 	return &runner{
-		out:        out,
-		errOut:     errOut,
-		pythonPath: ppath,
-		ansibleDir: ansibleDir,
-		runDir:     runDir,
+		out:         out,
+		errOut:      errOut,
+		pythonPath:  ppath,
+		ansibleDir:  ansibleDir,
+		runDir:      runDir,
+		sshAuthSock: sshAuthSock,
 	}, nil
 }
 
@@ -161,6 +167,9 @@ func (r *runner) startPlaybook(playbookFile string, inv Inventory, cc ClusterCat
 	os.Setenv("ANSIBLE_CALLBACK_WHITELIST", "json_lines")
 	os.Setenv("ANSIBLE_CONFIG", filepath.Join(r.ansibleDir, "playbooks", "ansible.cfg"))
 	os.Setenv("ANSIBLE_JSON_LINES_PIPE", r.namedPipe)
+	// Host key checking is disabled in the ansible config, unless the inventory
+	// has the known hosts files to verify the host keys against
+	os.Setenv("ANSIBLE_HOST_KEY_CHECKING", strconv.FormatBool(inv.hostKeyChecking()))
 
 	// Print Ansible command
 	fmt.Fprintf(r.out, "export PYTHONPATH=%v\n", os.Getenv("PYTHONPATH"))
@@ -168,7 +177,12 @@ func (r *runner) startPlaybook(playbookFile string, inv Inventory, cc ClusterCat
 	fmt.Fprintf(r.out, "export ANSIBLE_CALLBACK_WHITELIST=%v\n", os.Getenv("ANSIBLE_CALLBACK_WHITELIST"))
 	fmt.Fprintf(r.out, "export ANSIBLE_CONFIG=%v\n", os.Getenv("ANSIBLE_CONFIG"))
 	fmt.Fprintf(r.out, "export ANSIBLE_JSON_LINES_PIPE=%v\n", os.Getenv("ANSIBLE_JSON_LINES_PIPE"))
+	fmt.Fprintf(r.out, "export ANSIBLE_HOST_KEY_CHECKING=%v\n", os.Getenv("ANSIBLE_HOST_KEY_CHECKING"))
+	if r.sshAuthSock != "" {
+		fmt.Fprintf(r.out, "export SSH_AUTH_SOCK=%v\n", r.sshAuthSock)
+	}
 	fmt.Fprintln(r.out, strings.Join(cmd.Args, " "))
+	cmd.Env = r.commandEnv()
 
 	// Starts async execution of ansible, which will block until
 	// we start reading from the named pipe
@@ -187,6 +201,16 @@ func (r *runner) startPlaybook(playbookFile string, inv Inventory, cc ClusterCat
 	return eventStream, nil
 }
 
+// commandEnv returns the environment of the ansible-playbook command, or nil
+// for the current environment. The SSH agent socket of the runner is only set
+// in the environment of the command, not in the environment of the process.
+func (r *runner) commandEnv() []string {
+	if r.sshAuthSock == "" {
+		return nil
+	}
+	return append(os.Environ(), "SSH_AUTH_SOCK="+r.sshAuthSock)
+}
+
 // create a named pipe for getting json events out of ansible.
 // add random int to file name to avoid collision.
 func createTempNamedPipe() (string, error) {
@@ -2,11 +2,12 @@ package ansible
 
 import (
 	"io/ioutil"
+	"os"
 	"testing"
 )
 
 func TestWaitPlaybook(t *testing.T) {
-	r, err := NewRunner(ioutil.Discard, ioutil.Discard, "", "/tmp")
+	r, err := NewRunner(ioutil.Discard, ioutil.Discard, "", "/tmp", "")
 	if err != nil {
 		t.Fatalf("Error creating runner: %v", err)
 	}
@@ -15,3 +16,19 @@ func TestWaitPlaybook(t *testing.T) {
 		t.Error("Did not get the expected error when calling WaitPlaybook")
 	}
 }
+
+func TestCommandEnvSSHAuthSock(t *testing.T) {
+	r := &runner{}
+	if env := r.commandEnv(); env != nil {
+		t.Errorf("expected the current environment, but got %v", env)
+	}
+	before := os.Getenv("SSH_AUTH_SOCK")
+	r.sshAuthSock = "/tmp/kismatic-ssh-agent/agent.sock"
+	env := r.commandEnv()
+	if len(env) == 0 || env[len(env)-1] != "SSH_AUTH_SOCK=/tmp/kismatic-ssh-agent/agent.sock" {
+		t.Errorf("expected SSH_AUTH_SOCK to be set in the command environment, but got %v", env)
+	}
+	if after := os.Getenv("SSH_AUTH_SOCK"); after != before {
+		t.Errorf("expected the SSH_AUTH_SOCK of the process to remain %q, but got %q", before, after)
+	}
+}
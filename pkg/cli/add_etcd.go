@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/apprenda/kismatic/pkg/install"
+	"github.com/apprenda/kismatic/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+// NewCmdAddEtcd returns the command for adding etcd nodes to the cluster
+func NewCmdAddEtcd(out io.Writer, installOpts *installOpts) *cobra.Command {
+	opts := &addNodeOpts{}
+	cmd := &cobra.Command{
+		Use:   "add-etcd ETCD_NAME ETCD_IP [ETCD_INTERNAL_IP]",
+		Short: "add an Etcd node to an existing Kubernetes cluster",
+		Long: `Add an Etcd node to an existing Kubernetes cluster.
+
+The node joins the existing etcd clusters as a new member. The components that
+connect to etcd are reconfigured to include the new member.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 2 || len(args) > 3 {
+				return cmd.Usage()
+			}
+			newEtcd := newNodeFromArgs(args)
+			opts.nodeSSHOpts.apply(&newEtcd)
+			return doAddEtcd(out, installOpts.planFilename, opts, newEtcd)
+		},
+	}
+	addNodeFlags(cmd, opts)
+	return cmd
+}
+
+func doAddEtcd(out io.Writer, planFile string, opts *addNodeOpts, newEtcd install.Node) error {
+	planner := &install.FilePlanner{File: planFile}
+	if !planner.PlanExists() {
+		return planFileNotFoundErr{filename: planFile}
+	}
+	executor, err := newAddNodeExecutor(out, opts)
+	if err != nil {
+		return err
+	}
+	// With the json output format, stdout is reserved for the json records
+	out = util.MessageWriter(out, os.Stderr, opts.OutputFormat)
+	plan, err := planner.Read()
+	if err != nil {
+		return fmt.Errorf("failed to read plan file: %v", err)
+	}
+	if err = validateNewNode(out, plan, newEtcd, "etcd", opts.GeneratedAssetsDirectory); err != nil {
+		return err
+	}
+	if err = ensureNodeIsNew(plan.Etcd.Nodes, newEtcd, "etcd"); err != nil {
+		return err
+	}
+	if !opts.SkipPreFlight {
+		util.PrintHeader(out, "Running Pre-Flight Checks On New Etcd Node", '=')
+		if err = executor.RunNewEtcdPreFlightCheck(*plan, newEtcd); err != nil {
+			return err
+		}
+	}
+	updatedPlan, err := executor.AddEtcd(plan, newEtcd)
+	if err != nil {
+		return err
+	}
+	if err := planner.Write(updatedPlan); err != nil {
+		return fmt.Errorf("error updating plan file to include new etcd node: %v", err)
+	}
+	return nil
+}
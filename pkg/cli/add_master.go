@@ -0,0 +1,151 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/apprenda/kismatic/pkg/install"
+	"github.com/apprenda/kismatic/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+type addNodeOpts struct {
+	GeneratedAssetsDirectory string
+	RestartServices          bool
+	OutputFormat             string
+	Verbose                  bool
+	SkipPreFlight            bool
+	nodeSSHOpts
+}
+
+// nodeSSHOpts are the SSH settings of a new node, that override the ones of the cluster
+type nodeSSHOpts struct {
+	SSHUser string
+	SSHKey  string
+	SSHPort int
+}
+
+// NewCmdAddMaster returns the command for adding masters to the cluster
+func NewCmdAddMaster(out io.Writer, installOpts *installOpts) *cobra.Command {
+	opts := &addNodeOpts{}
+	cmd := &cobra.Command{
+		Use:   "add-master MASTER_NAME MASTER_IP [MASTER_INTERNAL_IP]",
+		Short: "add a Master node to an existing Kubernetes cluster",
+		Long: `Add a Master node to an existing Kubernetes cluster.
+
+The API server on every master is reconfigured to account for the new master.
+The new master must be added to the load balancer in front of the masters
+once this command completes.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 2 || len(args) > 3 {
+				return cmd.Usage()
+			}
+			newMaster := newNodeFromArgs(args)
+			opts.nodeSSHOpts.apply(&newMaster)
+			return doAddMaster(out, installOpts.planFilename, opts, newMaster)
+		},
+	}
+	addNodeFlags(cmd, opts)
+	return cmd
+}
+
+func doAddMaster(out io.Writer, planFile string, opts *addNodeOpts, newMaster install.Node) error {
+	planner := &install.FilePlanner{File: planFile}
+	if !planner.PlanExists() {
+		return planFileNotFoundErr{filename: planFile}
+	}
+	executor, err := newAddNodeExecutor(out, opts)
+	if err != nil {
+		return err
+	}
+	// With the json output format, stdout is reserved for the json records
+	out = util.MessageWriter(out, os.Stderr, opts.OutputFormat)
+	plan, err := planner.Read()
+	if err != nil {
+		return fmt.Errorf("failed to read plan file: %v", err)
+	}
+	if err = validateNewNode(out, plan, newMaster, "master", opts.GeneratedAssetsDirectory); err != nil {
+		return err
+	}
+	if err = ensureNodeIsNew(plan.Master.Nodes, newMaster, "master"); err != nil {
+		return err
+	}
+	if !opts.SkipPreFlight {
+		util.PrintHeader(out, "Running Pre-Flight Checks On New Master", '=')
+		if err = executor.RunNewMasterPreFlightCheck(*plan, newMaster); err != nil {
+			return err
+		}
+	}
+	updatedPlan, err := executor.AddMaster(plan, newMaster)
+	if err != nil {
+		return err
+	}
+	if err := planner.Write(updatedPlan); err != nil {
+		return fmt.Errorf("error updating plan file to include new master node: %v", err)
+	}
+	util.PrettyPrintWarn(out, "Add the new master %q to the load balancer that fronts the masters (%s)\n", newMaster.Host, updatedPlan.Master.LoadBalancedFQDN)
+	return nil
+}
+
+func newNodeFromArgs(args []string) install.Node {
+	n := install.Node{
+		Host: args[0],
+		IP:   args[1],
+	}
+	if len(args) == 3 {
+		n.InternalIP = args[2]
+	}
+	return n
+}
+
+func addNodeFlags(cmd *cobra.Command, opts *addNodeOpts) {
+	cmd.Flags().StringVar(&opts.GeneratedAssetsDirectory, "generated-assets-dir", "generated", "path to the directory where assets generated during the installation process will be stored")
+	cmd.Flags().BoolVar(&opts.RestartServices, "restart-services", false, "force restart clusters services (Use with care)")
+	cmd.Flags().BoolVar(&opts.Verbose, "verbose", false, "enable verbose logging from the installation")
+	cmd.Flags().StringVarP(&opts.OutputFormat, "output", "o", "simple", "installation output format (options \"simple\"|\"raw\"|\"json\")")
+	cmd.Flags().BoolVar(&opts.SkipPreFlight, "skip-preflight", false, "skip pre-flight checks, useful when rerunning kismatic")
+	addNodeSSHFlags(cmd, &opts.nodeSSHOpts)
+}
+
+func addNodeSSHFlags(cmd *cobra.Command, opts *nodeSSHOpts) {
+	cmd.Flags().StringVar(&opts.SSHUser, "ssh-user", "", "user for accessing the new node via SSH, if different from the cluster SSH user")
+	cmd.Flags().StringVar(&opts.SSHKey, "ssh-key", "", "absolute path of the SSH key for accessing the new node, if different from the cluster SSH key")
+	cmd.Flags().IntVar(&opts.SSHPort, "ssh-port", 0, "port on which the new node is listening for SSH connections, if different from the cluster SSH port")
+}
+
+// apply the SSH settings to the node
+func (opts nodeSSHOpts) apply(n *install.Node) {
+	n.SSHUser = opts.SSHUser
+	n.SSHKey = opts.SSHKey
+	n.SSHPort = opts.SSHPort
+}
+
+func newAddNodeExecutor(out io.Writer, opts *addNodeOpts) (install.Executor, error) {
+	execOpts := install.ExecutorOptions{
+		GeneratedAssetsDirectory: opts.GeneratedAssetsDirectory,
+		RestartServices:          opts.RestartServices,
+		OutputFormat:             opts.OutputFormat,
+		Verbose:                  opts.Verbose,
+	}
+	return install.NewExecutor(out, os.Stderr, execOpts)
+}
+
+// validates the new node, the plan and the SSH connection to the new node
+func validateNewNode(out io.Writer, plan *install.Plan, newNode install.Node, role string, generatedAssetsDir string) error {
+	if _, errs := install.ValidateNode(&newNode); errs != nil {
+		util.PrintValidationErrors(out, errs)
+		return fmt.Errorf("information provided about the new %s node is invalid", role)
+	}
+	if _, errs := install.ValidatePlan(plan); errs != nil {
+		util.PrintValidationErrors(out, errs)
+		return errors.New("the plan file failed validation")
+	}
+	sshCon := plan.NodeSSHConnection(newNode)
+	if _, errs := install.ValidateSSHConnection(sshCon, generatedAssetsDir, fmt.Sprintf("New %s node", role)); errs != nil {
+		util.PrintValidationErrors(out, errs)
+		return errors.New("could not establish SSH connection to the new node")
+	}
+	return nil
+}
@@ -1,7 +1,6 @@
 package cli
 
 import (
-	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -19,6 +18,7 @@ type addWorkerOpts struct {
 	OutputFormat             string
 	Verbose                  bool
 	SkipPreFlight            bool
+	nodeSSHOpts
 }
 
 // NewCmdAddWorker returns the command for adding workers to the cluster
@@ -38,6 +38,7 @@ func NewCmdAddWorker(out io.Writer, installOpts *installOpts) *cobra.Command {
 			if len(args) == 3 {
 				newWorker.InternalIP = args[2]
 			}
+			opts.nodeSSHOpts.apply(&newWorker)
 			if len(opts.NodeLabels) > 0 {
 				newWorker.Labels = make(map[string]string)
 				for _, l := range opts.NodeLabels {
@@ -55,8 +56,9 @@ func NewCmdAddWorker(out io.Writer, installOpts *installOpts) *cobra.Command {
 	cmd.Flags().StringVar(&opts.GeneratedAssetsDirectory, "generated-assets-dir", "generated", "path to the directory where assets generated during the installation process will be stored")
 	cmd.Flags().BoolVar(&opts.RestartServices, "restart-services", false, "force restart clusters services (Use with care)")
 	cmd.Flags().BoolVar(&opts.Verbose, "verbose", false, "enable verbose logging from the installation")
-	cmd.Flags().StringVarP(&opts.OutputFormat, "output", "o", "simple", "installation output format (options \"simple\"|\"raw\")")
+	cmd.Flags().StringVarP(&opts.OutputFormat, "output", "o", "simple", "installation output format (options \"simple\"|\"raw\"|\"json\")")
 	cmd.Flags().BoolVar(&opts.SkipPreFlight, "skip-preflight", false, "skip pre-flight checks, useful when rerunning kismatic")
+	addNodeSSHFlags(cmd, &opts.nodeSSHOpts)
 	return cmd
 }
 
@@ -75,27 +77,16 @@ func doAddWorker(out io.Writer, planFile string, opts *addWorkerOpts, newWorker
 	if err != nil {
 		return err
 	}
+	// With the json output format, stdout is reserved for the json records
+	out = util.MessageWriter(out, os.Stderr, opts.OutputFormat)
 	plan, err := planner.Read()
 	if err != nil {
 		return fmt.Errorf("failed to read plan file: %v", err)
 	}
-	if _, errs := install.ValidateNode(&newWorker); errs != nil {
-		util.PrintValidationErrors(out, errs)
-		return errors.New("information provided about the new worker node is invalid")
-	}
-	if _, errs := install.ValidatePlan(plan); errs != nil {
-		util.PrintValidationErrors(out, errs)
-		return errors.New("the plan file failed validation")
-	}
-	workerSSHCon := &install.SSHConnection{
-		SSHConfig: &plan.Cluster.SSH,
-		Node:      &newWorker,
-	}
-	if _, errs := install.ValidateSSHConnection(workerSSHCon, "New worker node"); errs != nil {
-		util.PrintValidationErrors(out, errs)
-		return errors.New("could not establish SSH connection to the new node")
+	if err = validateNewNode(out, plan, newWorker, "worker", opts.GeneratedAssetsDirectory); err != nil {
+		return err
 	}
-	if err = ensureNodeIsNew(*plan, newWorker); err != nil {
+	if err = ensureNodeIsNew(plan.Worker.Nodes, newWorker, "worker"); err != nil {
 		return err
 	}
 	if !opts.SkipPreFlight {
@@ -114,18 +105,18 @@ func doAddWorker(out io.Writer, planFile string, opts *addWorkerOpts, newWorker
 	return nil
 }
 
-// returns an error if the plan contains a worker that is "equivalent"
-// to the new worker that is being added
-func ensureNodeIsNew(plan install.Plan, newWorker install.Node) error {
-	for _, n := range plan.Worker.Nodes {
-		if n.Host == newWorker.Host {
-			return fmt.Errorf("according to the plan file, the host name of the new node is already being used by another worker node")
+// returns an error if the plan contains a node in the group that is "equivalent"
+// to the new node that is being added
+func ensureNodeIsNew(nodes []install.Node, newNode install.Node, role string) error {
+	for _, n := range nodes {
+		if n.Host == newNode.Host {
+			return fmt.Errorf("according to the plan file, the host name of the new node is already being used by another %s node", role)
 		}
-		if n.IP == newWorker.IP {
-			return fmt.Errorf("according to the plan file, the IP of the new node is already being used by another worker node")
+		if n.IP == newNode.IP {
+			return fmt.Errorf("according to the plan file, the IP of the new node is already being used by another %s node", role)
 		}
-		if newWorker.InternalIP != "" && n.InternalIP == newWorker.InternalIP {
-			return fmt.Errorf("according to the plan file, the internal IP of the new node is already being used by another worker node")
+		if newNode.InternalIP != "" && n.InternalIP == newNode.InternalIP {
+			return fmt.Errorf("according to the plan file, the internal IP of the new node is already being used by another %s node", role)
 		}
 	}
 	return nil
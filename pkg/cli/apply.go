@@ -19,6 +19,8 @@ type applyCmd struct {
 	verbose            bool
 	outputFormat       string
 	skipPreFlight      bool
+	resume             bool
+	dryRun             bool
 }
 
 type applyOpts struct {
@@ -27,6 +29,8 @@ type applyOpts struct {
 	verbose            bool
 	outputFormat       string
 	skipPreFlight      bool
+	resume             bool
+	dryRun             bool
 }
 
 // NewCmdApply creates a cluter using the plan file
@@ -45,6 +49,8 @@ func NewCmdApply(out io.Writer, installOpts *installOpts) *cobra.Command {
 				RestartServices:          applyOpts.restartServices,
 				OutputFormat:             applyOpts.outputFormat,
 				Verbose:                  applyOpts.verbose,
+				Resume:                   applyOpts.resume,
+				DryRun:                   applyOpts.dryRun,
 			}
 			executor, err := install.NewExecutor(out, os.Stderr, executorOpts)
 			if err != nil {
@@ -60,6 +66,8 @@ func NewCmdApply(out io.Writer, installOpts *installOpts) *cobra.Command {
 				verbose:            applyOpts.verbose,
 				outputFormat:       applyOpts.outputFormat,
 				skipPreFlight:      applyOpts.skipPreFlight,
+				resume:             applyOpts.resume,
+				dryRun:             applyOpts.dryRun,
 			}
 			return applyCmd.run()
 		},
@@ -69,19 +77,26 @@ func NewCmdApply(out io.Writer, installOpts *installOpts) *cobra.Command {
 	cmd.Flags().StringVar(&applyOpts.generatedAssetsDir, "generated-assets-dir", "generated", "path to the directory where assets generated during the installation process will be stored")
 	cmd.Flags().BoolVar(&applyOpts.restartServices, "restart-services", false, "force restart cluster services (Use with care)")
 	cmd.Flags().BoolVar(&applyOpts.verbose, "verbose", false, "enable verbose logging from the installation")
-	cmd.Flags().StringVarP(&applyOpts.outputFormat, "output", "o", "simple", "installation output format (options \"simple\"|\"raw\")")
+	cmd.Flags().StringVarP(&applyOpts.outputFormat, "output", "o", "simple", "installation output format (options \"simple\"|\"raw\"|\"json\")")
 	cmd.Flags().BoolVar(&applyOpts.skipPreFlight, "skip-preflight", false, "skip pre-flight checks, useful when rerunning kismatic")
+	cmd.Flags().BoolVar(&applyOpts.resume, "resume", false, "continue the previous installation from the first play that did not complete. Pre-flight checks are skipped")
+	cmd.Flags().BoolVar(&applyOpts.dryRun, "dry-run", false, "record the inventory, variables and targeted nodes of each installation task in the runs directory, without connecting to the nodes. SSH validation and pre-flight checks are skipped, and the certificates and other generated assets are not written")
 
 	return cmd
 }
 
 func (c *applyCmd) run() error {
-	// Validate and run pre-flight
+	out := util.MessageWriter(c.out, os.Stderr, c.outputFormat)
+	// Validate and run pre-flight. The nodes of a partially installed
+	// cluster fail the pre-flight checks, so they are skipped when resuming.
+	// A dry run must not connect to the nodes, so the SSH connections are not
+	// validated and pre-flight is skipped as well.
 	opts := &validateOpts{
 		planFile:           c.planFile,
 		verbose:            c.verbose,
 		outputFormat:       c.outputFormat,
-		skipPreFlight:      c.skipPreFlight,
+		skipPreFlight:      c.skipPreFlight || c.resume || c.dryRun,
+		skipSSH:            c.dryRun,
 		generatedAssetsDir: c.generatedAssetsDir,
 	}
 	err := doValidate(c.out, c.planner, opts)
@@ -93,18 +108,26 @@ func (c *applyCmd) run() error {
 		return fmt.Errorf("error reading plan file: %v", err)
 	}
 
-	// Generate certificates
-	if err := c.executor.GenerateCertificates(plan, false); err != nil {
-		return fmt.Errorf("error installing: %v", err)
-	}
+	// The generated assets are left untouched during a dry run
+	if !c.dryRun {
+		// Generate certificates
+		if err := c.executor.GenerateCertificates(plan, false); err != nil {
+			return fmt.Errorf("error installing: %v", err)
+		}
 
-	// Generate kubeconfig
-	util.PrintHeader(c.out, "Generating Kubeconfig File", '=')
-	err = install.GenerateKubeconfig(plan, c.generatedAssetsDir)
-	if err != nil {
-		return fmt.Errorf("error generating kubeconfig file: %v", err)
+		// Generate kubeconfig
+		util.PrintHeader(out, "Generating Kubeconfig File", '=')
+		err = install.GenerateKubeconfig(plan, c.generatedAssetsDir)
+		if err != nil {
+			return fmt.Errorf("error generating kubeconfig file: %v", err)
+		}
+		util.PrettyPrintOk(out, "Generated kubeconfig file in the %q directory", c.generatedAssetsDir)
+
+		// Generate the secrets encryption key
+		if err := install.GenerateSecretsEncryptionConfig(plan, c.generatedAssetsDir); err != nil {
+			return fmt.Errorf("error generating secrets encryption config: %v", err)
+		}
 	}
-	util.PrettyPrintOk(c.out, "Generated kubeconfig file in the %q directory", c.generatedAssetsDir)
 
 	// Perform the installation
 	if err := c.executor.Install(plan); err != nil {
@@ -119,16 +142,22 @@ func (c *applyCmd) run() error {
 		}
 	}
 
-	util.PrintColor(c.out, util.Green, "\nThe cluster was installed successfully!\n")
-	fmt.Fprintln(c.out)
+	if c.dryRun {
+		util.PrintColor(out, util.Green, "\nDry run complete. The tasks that would be run were recorded in the runs directory.\n")
+		util.PrintColor(out, util.Blue, "- To review the recorded tasks: \"./kismatic runs list\"\n\n")
+		return nil
+	}
+
+	util.PrintColor(out, util.Green, "\nThe cluster was installed successfully!\n")
+	fmt.Fprintln(out)
 
 	msg := "- To use the generated kubeconfig file with kubectl:" +
 		"\n    * use \"./kubectl --kubeconfig %s/kubeconfig\"" +
 		"\n    * or copy the config file \"cp %[1]s/kubeconfig ~/.kube/config\"\n"
-	util.PrintColor(c.out, util.Blue, msg, c.generatedAssetsDir)
-	util.PrintColor(c.out, util.Blue, "- To view the Kubernetes dashboard: \"./kismatic dashboard\"\n")
-	util.PrintColor(c.out, util.Blue, "- To SSH into a cluster node: \"./kismatic ssh etcd|master|worker|storage|$node.host\"\n")
-	fmt.Fprintln(c.out)
+	util.PrintColor(out, util.Blue, msg, c.generatedAssetsDir)
+	util.PrintColor(out, util.Blue, "- To view the Kubernetes dashboard: \"./kismatic dashboard\"\n")
+	util.PrintColor(out, util.Blue, "- To SSH into a cluster node: \"./kismatic ssh etcd|master|worker|storage|$node.host\"\n")
+	fmt.Fprintln(out)
 
 	return nil
 }
@@ -17,6 +17,8 @@ func NewCmdCertificates(out io.Writer) *cobra.Command {
 	}
 
 	cmd.AddCommand(NewCmdGenerate(out))
+	cmd.AddCommand(NewCmdRotate(out))
+	cmd.AddCommand(NewCmdStatus(out))
 
 	return cmd
 }
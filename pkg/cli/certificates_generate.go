@@ -17,6 +17,7 @@ type certificatesGenerateOpts struct {
 	organizations      []string
 	overwrite          bool
 	generatedAssetsDir string
+	planFile           string
 }
 
 // NewCmdGenerate creates a new certificates generate command
@@ -52,6 +53,7 @@ func NewCmdGenerate(out io.Writer) *cobra.Command {
 	cmd.Flags().StringSliceVar(&opts.organizations, "organizations", []string{}, "comma-separated list of names that should be included in the certificate's organization field.")
 	cmd.Flags().BoolVar(&opts.overwrite, "overwrite", false, "overwrite existing certificate if it already exists in the target directory.")
 	cmd.Flags().StringVar(&opts.generatedAssetsDir, "generated-assets-dir", "generated", "path to the directory where assets generated during the installation process will be stored")
+	addPlanFileFlag(cmd.Flags(), &opts.planFile)
 
 	return cmd
 }
@@ -64,7 +66,18 @@ func doCertificatesGenerate(name string, opts *certificatesGenerateOpts, out io.
 		GeneratedCertsDirectory: certsDir,
 		Log: out,
 	}
-	ca, err := pki.GetClusterCA()
+	// The plan file is optional, and determines how the certificate is signed,
+	// along with its key and subject
+	plan := &install.Plan{}
+	planner := &install.FilePlanner{File: opts.planFile}
+	if planner.PlanExists() {
+		p, err := planner.Read()
+		if err != nil {
+			return fmt.Errorf("error reading plan file: %v", err)
+		}
+		plan = p
+	}
+	ca, err := pki.GetClusterCA(plan)
 	if err != nil {
 		return err
 	}
@@ -73,7 +86,7 @@ func doCertificatesGenerate(name string, opts *certificatesGenerateOpts, out io.
 		commonName = name
 	}
 	validityPeriod := fmt.Sprintf("%dh", opts.validityPeriod*24)
-	exists, err := pki.GenerateCertificate(name, validityPeriod, commonName, opts.subjAltNames, opts.organizations, ca, opts.overwrite)
+	exists, err := pki.GenerateCertificate(plan, name, validityPeriod, commonName, opts.subjAltNames, opts.organizations, ca, opts.overwrite)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/apprenda/kismatic/pkg/install"
+	"github.com/apprenda/kismatic/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+type certificatesRotateOpts struct {
+	planFile           string
+	generatedAssetsDir string
+	verbose            bool
+	outputFormat       string
+}
+
+// NewCmdRotate creates a new certificates rotate command
+func NewCmdRotate(out io.Writer) *cobra.Command {
+	opts := &certificatesRotateOpts{}
+
+	cmd := &cobra.Command{
+		Use:   "rotate",
+		Short: "Rotate the cluster certificates, expects 'ca.pem' and 'ca-key.pem' to be in the --generated-assets-dir",
+		Long: `Rotate the cluster certificates.
+
+New certificates are generated for the nodes and the cluster using the existing
+Certificate Authority, and the previous certificates are archived. The new
+certificates are then deployed one node at a time, and the components that use
+them are restarted. The health of the components is verified before moving on
+to the next node.
+
+Nodes in the cluster are updated in the following order:
+
+1. Etcd nodes
+2. Master nodes
+3. Worker nodes (regardless of specialization)
+
+If the certificates cannot be deployed to a node, running the command again resumes
+the rotation: the certificates are not generated again, and the nodes that already
+received them are skipped.
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return fmt.Errorf("Unexpected args: %v", args)
+			}
+			return doCertificatesRotate(out, opts)
+		},
+	}
+
+	addPlanFileFlag(cmd.Flags(), &opts.planFile)
+	cmd.Flags().StringVar(&opts.generatedAssetsDir, "generated-assets-dir", "generated", "path to the directory where assets generated during the installation process will be stored")
+	cmd.Flags().BoolVar(&opts.verbose, "verbose", false, "enable verbose logging from the installation")
+	cmd.Flags().StringVarP(&opts.outputFormat, "output", "o", "simple", "installation output format (options \"simple\"|\"raw\"|\"json\")")
+
+	return cmd
+}
+
+func doCertificatesRotate(stdout io.Writer, opts *certificatesRotateOpts) error {
+	planner := &install.FilePlanner{File: opts.planFile}
+	executorOpts := install.ExecutorOptions{
+		GeneratedAssetsDirectory: opts.generatedAssetsDir,
+		OutputFormat:             opts.outputFormat,
+		Verbose:                  opts.verbose,
+	}
+	executor, err := install.NewExecutor(stdout, os.Stderr, executorOpts)
+	if err != nil {
+		return err
+	}
+	valOpts := &validateOpts{
+		planFile:           opts.planFile,
+		verbose:            opts.verbose,
+		outputFormat:       opts.outputFormat,
+		skipPreFlight:      true,
+		generatedAssetsDir: opts.generatedAssetsDir,
+	}
+	if err := doValidate(stdout, planner, valOpts); err != nil {
+		return err
+	}
+	plan, err := planner.Read()
+	if err != nil {
+		return fmt.Errorf("error reading plan file: %v", err)
+	}
+
+	if err := executor.RotateCertificates(*plan); err != nil {
+		return fmt.Errorf("error rotating certificates: %v", err)
+	}
+
+	// The kubeconfig file embeds the admin certificate, which was rotated
+	out := util.MessageWriter(stdout, os.Stderr, opts.outputFormat)
+	util.PrintHeader(out, "Generating Kubeconfig File", '=')
+	if _, err := install.RegenerateKubeconfig(plan, opts.generatedAssetsDir); err != nil {
+		return fmt.Errorf("error generating kubeconfig file: %v", err)
+	}
+	util.PrettyPrintOk(out, "Generated kubeconfig file in the %q directory", opts.generatedAssetsDir)
+
+	util.PrintColor(out, util.Green, "\nThe cluster certificates were rotated successfully\n\n")
+	return nil
+}
@@ -0,0 +1,145 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/apprenda/kismatic/pkg/install"
+	"github.com/apprenda/kismatic/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+type certificatesStatusOpts struct {
+	planFile           string
+	generatedAssetsDir string
+	outputFormat       string
+	expiresWithin      int
+	localOnly          bool
+}
+
+// NewCmdStatus creates a new certificates status command
+func NewCmdStatus(out io.Writer) *cobra.Command {
+	opts := &certificatesStatusOpts{}
+
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Report the certificates of the cluster and their expiration dates",
+		Long: `Report the certificates of the cluster and their expiration dates.
+
+The certificates in the --generated-assets-dir are listed, along with the certificates
+deployed on each node, which are read via SSH. Certificates that expire within the
+--expires-within window, and certificates deployed on a node that are not the same
+as the ones in the --generated-assets-dir are flagged.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return fmt.Errorf("Unexpected args: %v", args)
+			}
+			if opts.outputFormat != "table" && opts.outputFormat != "json" {
+				return fmt.Errorf("output format %q is not supported", opts.outputFormat)
+			}
+			if opts.expiresWithin < 0 {
+				return fmt.Errorf("--expires-within must be greater or equal to 0")
+			}
+			return doCertificatesStatus(out, opts)
+		},
+	}
+
+	addPlanFileFlag(cmd.Flags(), &opts.planFile)
+	cmd.Flags().StringVar(&opts.generatedAssetsDir, "generated-assets-dir", "generated", "path to the directory where assets generated during the installation process will be stored")
+	cmd.Flags().StringVarP(&opts.outputFormat, "output", "o", "table", `output format (options "table"|"json")`)
+	cmd.Flags().IntVar(&opts.expiresWithin, "expires-within", 30, "flag the certificates that expire within this number of days")
+	cmd.Flags().BoolVar(&opts.localOnly, "local", false, "only report the certificates in the --generated-assets-dir, without connecting to the nodes")
+
+	return cmd
+}
+
+func doCertificatesStatus(out io.Writer, opts *certificatesStatusOpts) error {
+	planner := &install.FilePlanner{File: opts.planFile}
+	if !planner.PlanExists() {
+		return fmt.Errorf("plan does not exist")
+	}
+	plan, err := planner.Read()
+	if err != nil {
+		return fmt.Errorf("error reading plan file: %v", err)
+	}
+
+	window := time.Duration(opts.expiresWithin) * 24 * time.Hour
+	certsDir := filepath.Join(opts.generatedAssetsDir, "keys")
+	statuses, err := install.LocalCertificatesStatus(plan, certsDir, window)
+	if err != nil {
+		return fmt.Errorf("error reading certificates: %v", err)
+	}
+	if !opts.localOnly {
+		if ok, errs := install.ValidatePlanSSHConnections(plan, opts.generatedAssetsDir); !ok {
+			util.PrintValidationErrors(out, errs)
+			return fmt.Errorf("error connecting to the cluster nodes")
+		}
+		nodeStatuses, err := install.NodeCertificatesStatus(plan, opts.generatedAssetsDir, window)
+		if err != nil {
+			return fmt.Errorf("error reading certificates from the cluster nodes: %v", err)
+		}
+		statuses = append(statuses, nodeStatuses...)
+	}
+
+	if err := printCertificatesStatus(out, statuses, opts.outputFormat); err != nil {
+		return err
+	}
+	flagged := 0
+	for _, s := range statuses {
+		if s.NeedsAttention() {
+			flagged++
+		}
+	}
+	if flagged > 0 {
+		return fmt.Errorf("%d certificate(s) require attention", flagged)
+	}
+	return nil
+}
+
+func printCertificatesStatus(out io.Writer, statuses []install.CertificateStatus, format string) error {
+	if format == "json" {
+		b, err := json.MarshalIndent(statuses, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error marshalling certificates status: %v", err)
+		}
+		fmt.Fprintln(out, string(b))
+		return nil
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprint(w, "Node\tFile\tCommon Name\tSubject Alternate Names\tOrganizations\tIssuer\tExpiry\tStatus\n")
+	for _, s := range statuses {
+		node := s.Node
+		if node == "" {
+			node = "local"
+		}
+		expiry := ""
+		if !s.Expiry.IsZero() {
+			expiry = s.Expiry.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", node, s.File, s.CommonName, strings.Join(s.SubjectAlternateNames, ","), strings.Join(s.Organizations, ","), s.Issuer, expiry, certificateStatusSummary(s))
+	}
+	return w.Flush()
+}
+
+// certificateStatusSummary returns the problems found with the certificate, or OK
+func certificateStatusSummary(s install.CertificateStatus) string {
+	if s.Error != "" {
+		return s.Error
+	}
+	problems := []string{}
+	if s.Expiring {
+		problems = append(problems, "EXPIRING")
+	}
+	if s.Mismatch {
+		problems = append(problems, "MISMATCH")
+	}
+	if len(problems) == 0 {
+		return "OK"
+	}
+	return strings.Join(problems, ",")
+}
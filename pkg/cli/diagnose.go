@@ -11,9 +11,10 @@ import (
 )
 
 type diagsOpts struct {
-	planFilename string
-	verbose      bool
-	outputFormat string
+	planFilename       string
+	generatedAssetsDir string
+	verbose            bool
+	outputFormat       string
 }
 
 // NewCmdDiagnostic collects diagnostic data on remote nodes
@@ -34,8 +35,9 @@ func NewCmdDiagnostic(out io.Writer) *cobra.Command {
 
 	// PersistentFlags
 	addPlanFileFlag(cmd.PersistentFlags(), &opts.planFilename)
+	cmd.Flags().StringVar(&opts.generatedAssetsDir, "generated-assets-dir", "generated", "path to the directory where assets generated during the installation process will be stored")
 	cmd.Flags().BoolVar(&opts.verbose, "verbose", false, "enable verbose logging from the installation")
-	cmd.Flags().StringVarP(&opts.outputFormat, "output", "o", "simple", "installation output format (options \"simple\"|\"raw\")")
+	cmd.Flags().StringVarP(&opts.outputFormat, "output", "o", "simple", "installation output format (options \"simple\"|\"raw\"|\"json\")")
 
 	return cmd
 }
@@ -59,7 +61,7 @@ func doDiagnostics(out io.Writer, opts *diagsOpts) error {
 	}
 
 	// Validate SSH connectivity to nodes
-	if ok, errs := install.ValidatePlanSSHConnections(plan); !ok {
+	if ok, errs := install.ValidatePlanSSHConnections(plan, opts.generatedAssetsDir); !ok {
 		util.PrettyPrintErr(out, "Validate SSH connectivity to nodes")
 		util.PrintValidationErrors(out, errs)
 		return fmt.Errorf("SSH connectivity validation errors found")
@@ -68,8 +70,9 @@ func doDiagnostics(out io.Writer, opts *diagsOpts) error {
 
 	// Get diagnostics from nodes
 	options := install.ExecutorOptions{
-		OutputFormat: opts.outputFormat,
-		Verbose:      opts.verbose,
+		GeneratedAssetsDirectory: opts.generatedAssetsDir,
+		OutputFormat:             opts.outputFormat,
+		Verbose:                  opts.verbose,
 	}
 	executor, err := install.NewDiagnosticsExecutor(out, os.Stderr, options)
 	if err != nil {
@@ -35,6 +35,18 @@ func (fe *fakeExecutor) AddWorker(p *install.Plan, newWorker install.Node) (*ins
 	return nil, nil
 }
 
+func (fe *fakeExecutor) AddMaster(p *install.Plan, newMaster install.Node) (*install.Plan, error) {
+	return nil, nil
+}
+
+func (fe *fakeExecutor) AddEtcd(p *install.Plan, newEtcd install.Node) (*install.Plan, error) {
+	return nil, nil
+}
+
+func (fe *fakeExecutor) RemoveNode(p *install.Plan, node install.Node) (*install.Plan, error) {
+	return nil, nil
+}
+
 func (fe *fakeExecutor) GenerateCertificates(*install.Plan, bool) error {
 	return nil
 }
@@ -52,6 +64,14 @@ func (fe *fakeExecutor) RunNewWorkerPreFlightCheck(install.Plan, install.Node) e
 	return nil
 }
 
+func (fe *fakeExecutor) RunNewMasterPreFlightCheck(install.Plan, install.Node) error {
+	return nil
+}
+
+func (fe *fakeExecutor) RunNewEtcdPreFlightCheck(install.Plan, install.Node) error {
+	return nil
+}
+
 func (fe *fakeExecutor) RunUpgradePreFlightCheck(*install.Plan, install.ListableNode) error {
 	return nil
 }
@@ -72,6 +92,14 @@ func (fe *fakeExecutor) UpgradeClusterServices(install.Plan) error {
 	return nil
 }
 
+func (fe *fakeExecutor) RotateCertificates(install.Plan) error {
+	return nil
+}
+
+func (fe *fakeExecutor) RotateEncryptionKey(install.Plan) error {
+	return nil
+}
+
 func (fe *fakeExecutor) RunSmokeTest(p *install.Plan) error {
 	return nil
 }
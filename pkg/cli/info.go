@@ -13,8 +13,9 @@ import (
 )
 
 type infoOpts struct {
-	planFilename string
-	outputFormat string
+	planFilename       string
+	generatedAssetsDir string
+	outputFormat       string
 }
 
 // NewCmdInfo returns the info command
@@ -31,6 +32,7 @@ This will be retrieved by connecting to each node via ssh`,
 		},
 	}
 	cmd.Flags().StringVarP(&opts.planFilename, "plan-file", "f", "kismatic-cluster.yaml", "path to the installation plan file")
+	cmd.Flags().StringVar(&opts.generatedAssetsDir, "generated-assets-dir", "generated", "path to the directory where assets generated during the installation process will be stored")
 	cmd.Flags().StringVarP(&opts.outputFormat, "output", "o", "simple", `output format (options "simple"|"json")`)
 	return cmd
 }
@@ -53,12 +55,12 @@ func list(out io.Writer, opts *infoOpts) error {
 	}
 
 	// Validate SSH connections
-	if ok, errs := install.ValidatePlanSSHConnections(plan); !ok {
+	if ok, errs := install.ValidatePlanSSHConnections(plan, opts.generatedAssetsDir); !ok {
 		util.PrintValidationErrors(out, errs)
 		return fmt.Errorf("error getting info from cluster nodes")
 	}
 
-	lv, err := install.ListVersions(plan)
+	lv, err := install.ListVersions(plan, opts.generatedAssetsDir)
 	if err != nil {
 		return fmt.Errorf("error getting version: %v", err)
 	}
@@ -27,6 +27,9 @@ func NewCmdInstall(in io.Reader, out io.Writer) *cobra.Command {
 	cmd.AddCommand(NewCmdValidate(out, opts))
 	cmd.AddCommand(NewCmdApply(out, opts))
 	cmd.AddCommand(NewCmdAddWorker(out, opts))
+	cmd.AddCommand(NewCmdAddMaster(out, opts))
+	cmd.AddCommand(NewCmdAddEtcd(out, opts))
+	cmd.AddCommand(NewCmdRemoveNode(in, out, opts))
 	cmd.AddCommand(NewCmdStep(out, opts))
 
 	// PersistentFlags
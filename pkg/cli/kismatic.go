@@ -30,7 +30,10 @@ more documentation is available at https://github.com/apprenda/kismatic`,
 	cmd.AddCommand(NewCmdUpgrade(in, out))
 	cmd.AddCommand(NewCmdDiagnostic(out))
 	cmd.AddCommand(NewCmdCertificates(out))
+	cmd.AddCommand(NewCmdKubeconfig(out))
+	cmd.AddCommand(NewCmdSecrets(out))
 	cmd.AddCommand(NewCmdSeedRegistry(out, stderr))
+	cmd.AddCommand(NewCmdRuns(out))
 
 	return cmd, nil
 }
@@ -0,0 +1,23 @@
+package cli
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+)
+
+// NewCmdKubeconfig creates a new kubeconfig command
+func NewCmdKubeconfig(out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "kubeconfig",
+		Short: "Manage kubeconfig files for the users of the cluster",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(NewCmdKubeconfigCreate(out))
+	cmd.AddCommand(NewCmdKubeconfigMerge(out))
+
+	return cmd
+}
@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/apprenda/kismatic/pkg/data"
+	"github.com/apprenda/kismatic/pkg/install"
+	"github.com/apprenda/kismatic/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+	clusterRoleRegexp = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9.:_-]*$`)
+	namespaceRegexp   = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+)
+
+type kubeconfigCreateOpts struct {
+	planFile           string
+	generatedAssetsDir string
+	groups             []string
+	expiry             string
+	overwrite          bool
+	clusterRole        string
+	namespace          string
+}
+
+// NewCmdKubeconfigCreate creates a new kubeconfig create command
+func NewCmdKubeconfigCreate(out io.Writer) *cobra.Command {
+	opts := &kubeconfigCreateOpts{}
+
+	cmd := &cobra.Command{
+		Use:   "create <user> [options]",
+		Short: "Issue a client certificate for a user and create a kubeconfig file that uses it",
+		Long: `Issue a client certificate for a user and create a kubeconfig file that uses it.
+
+The certificate is signed by the cluster CA, with <user> as the common name and the
+--groups as the organizations, which Kubernetes uses as the user name and groups of
+the user. The kubeconfig file is written to the --generated-assets-dir, and points at
+the load balanced FQDN of the master nodes.
+
+When --cluster-role is set, the cluster role is bound to the user with a cluster role
+binding, or with a role binding in the --namespace when set.`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 || args[0] == "" {
+				cmd.Help()
+				return fmt.Errorf("no valid <user> argument provided")
+			}
+			if len(args) != 1 {
+				cmd.Help()
+				return fmt.Errorf("invalid arguments provided: %v", args)
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if opts.expiry != "" {
+				if _, err := time.ParseDuration(opts.expiry); err != nil {
+					return fmt.Errorf("--expiry %q is not a valid duration", opts.expiry)
+				}
+			}
+			if opts.clusterRole != "" && !clusterRoleRegexp.MatchString(opts.clusterRole) {
+				return fmt.Errorf("--cluster-role %q is not a valid role name", opts.clusterRole)
+			}
+			if opts.namespace != "" {
+				if opts.clusterRole == "" {
+					return fmt.Errorf("--namespace can only be used with --cluster-role")
+				}
+				if !namespaceRegexp.MatchString(opts.namespace) {
+					return fmt.Errorf("--namespace %q is not a valid namespace", opts.namespace)
+				}
+			}
+			return doKubeconfigCreate(out, args[0], opts)
+		},
+	}
+
+	addPlanFileFlag(cmd.Flags(), &opts.planFile)
+	cmd.Flags().StringVar(&opts.generatedAssetsDir, "generated-assets-dir", "generated", "path to the directory where assets generated during the installation process will be stored")
+	cmd.Flags().StringSliceVar(&opts.groups, "groups", []string{}, "comma-separated list of groups the user belongs to, which are included in the certificate's organization field.")
+	cmd.Flags().StringVar(&opts.expiry, "expiry", "", "validity period of the certificate, e.g. 720h. If left blank, will use the expiry of the cluster certificates")
+	cmd.Flags().BoolVar(&opts.overwrite, "overwrite", false, "overwrite the certificate and kubeconfig file of the user if they already exist.")
+	cmd.Flags().StringVar(&opts.clusterRole, "cluster-role", "", "name of a cluster role to bind to the user")
+	cmd.Flags().StringVar(&opts.namespace, "namespace", "", "create a role binding in this namespace instead of a cluster role binding")
+
+	return cmd
+}
+
+func doKubeconfigCreate(out io.Writer, user string, opts *kubeconfigCreateOpts) error {
+	planner := &install.FilePlanner{File: opts.planFile}
+	if !planner.PlanExists() {
+		return fmt.Errorf("plan does not exist")
+	}
+	plan, err := planner.Read()
+	if err != nil {
+		return fmt.Errorf("error reading plan file: %v", err)
+	}
+	pki := &install.LocalPKI{
+		CACsr:                   filepath.Join("ansible", "playbooks", "tls", "ca-csr.json"),
+		GeneratedCertsDirectory: filepath.Join(opts.generatedAssetsDir, "keys"),
+		Log:                     out,
+	}
+	if err := install.GenerateUserKubeconfig(plan, pki, opts.generatedAssetsDir, user, opts.groups, opts.expiry, opts.overwrite); err != nil {
+		return fmt.Errorf("error creating kubeconfig for %q: %v", user, err)
+	}
+	util.PrettyPrintOk(out, "Kubeconfig file for %q created at %q", user, install.UserKubeconfigFile(opts.generatedAssetsDir, user))
+
+	if opts.clusterRole == "" {
+		return nil
+	}
+	client, err := plan.GetSSHClient(plan.Master.Nodes[0].Host, opts.generatedAssetsDir)
+	if err != nil {
+		return fmt.Errorf("error getting SSH client: %v", err)
+	}
+	kubeClient := data.RemoteKubectl{SSHClient: client}
+	return createUserRoleBinding(out, kubeClient, user, opts.clusterRole, opts.namespace)
+}
+
+// createUserRoleBinding binds the cluster role to the user, in the namespace
+// when it is not empty
+func createUserRoleBinding(out io.Writer, c data.RoleBindingCreator, user, clusterRole, namespace string) error {
+	name := fmt.Sprintf("kismatic:user:%s:%s", user, clusterRole)
+	if err := c.CreateRoleBinding(namespace, name, clusterRole, user); err != nil {
+		return err
+	}
+	if namespace == "" {
+		util.PrettyPrintOk(out, "Cluster role %q bound to %q", clusterRole, user)
+	} else {
+		util.PrettyPrintOk(out, "Cluster role %q bound to %q in namespace %q", clusterRole, user, namespace)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+)
+
+type fakeRoleBindingCreator struct {
+	namespace   string
+	name        string
+	clusterRole string
+	user        string
+}
+
+func (f *fakeRoleBindingCreator) CreateRoleBinding(namespace, name, clusterRole, user string) error {
+	f.namespace = namespace
+	f.name = name
+	f.clusterRole = clusterRole
+	f.user = user
+	return nil
+}
+
+func TestCreateUserRoleBinding(t *testing.T) {
+	tests := []struct {
+		namespace string
+	}{
+		{namespace: ""},
+		{namespace: "dev"},
+	}
+	for _, test := range tests {
+		c := &fakeRoleBindingCreator{}
+		if err := createUserRoleBinding(&bytes.Buffer{}, c, "alice", "edit", test.namespace); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if c.namespace != test.namespace {
+			t.Errorf("expected namespace %q, but got %q", test.namespace, c.namespace)
+		}
+		if c.name != "kismatic:user:alice:edit" {
+			t.Errorf("unexpected binding name %q", c.name)
+		}
+		if c.clusterRole != "edit" || c.user != "alice" {
+			t.Errorf("expected edit to be bound to alice, but got %q bound to %q", c.clusterRole, c.user)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/apprenda/kismatic/pkg/install"
+	"github.com/apprenda/kismatic/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+type kubeconfigMergeOpts struct {
+	planFile           string
+	generatedAssetsDir string
+	kubeconfig         string
+	setCurrent         bool
+	remove             bool
+}
+
+// NewCmdKubeconfigMerge creates a new kubeconfig merge command
+func NewCmdKubeconfigMerge(out io.Writer) *cobra.Command {
+	opts := &kubeconfigMergeOpts{}
+
+	cmd := &cobra.Command{
+		Use:   "merge [user] [options]",
+		Short: "Merge the kubeconfig file of the cluster into your kubeconfig file",
+		Long: `Merge the kubeconfig file of the cluster into your kubeconfig file.
+
+The cluster, user and context entries of the admin kubeconfig file, or of the kubeconfig
+file created for [user] with "kismatic kubeconfig create", are added to the --kubeconfig
+file. The entries are named after the cluster name found in the plan file, and entries
+with the same names are replaced. All other entries are left untouched.
+
+The --kubeconfig file defaults to the first file in the KUBECONFIG environment variable,
+or to ~/.kube/config. Use --remove to remove the merged entries, and to restore the
+entries and the current context that were replaced by the merge.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 1 {
+				cmd.Help()
+				return fmt.Errorf("invalid arguments provided: %v", args)
+			}
+			if opts.remove && opts.setCurrent {
+				return fmt.Errorf("--set-current cannot be used with --remove")
+			}
+			user := "admin"
+			if len(args) == 1 && args[0] != "" {
+				user = args[0]
+			}
+			return doKubeconfigMerge(out, user, opts)
+		},
+	}
+
+	addPlanFileFlag(cmd.Flags(), &opts.planFile)
+	cmd.Flags().StringVar(&opts.generatedAssetsDir, "generated-assets-dir", "generated", "path to the directory where assets generated during the installation process will be stored")
+	cmd.Flags().StringVar(&opts.kubeconfig, "kubeconfig", "", "path to the kubeconfig file to merge into. If left blank, will use the first file in $KUBECONFIG or ~/.kube/config")
+	cmd.Flags().BoolVar(&opts.setCurrent, "set-current", false, "set the merged context as the current context")
+	cmd.Flags().BoolVar(&opts.remove, "remove", false, "remove the entries of the cluster from the kubeconfig file")
+
+	return cmd
+}
+
+func doKubeconfigMerge(out io.Writer, user string, opts *kubeconfigMergeOpts) error {
+	planner := &install.FilePlanner{File: opts.planFile}
+	if !planner.PlanExists() {
+		return fmt.Errorf("plan does not exist")
+	}
+	plan, err := planner.Read()
+	if err != nil {
+		return fmt.Errorf("error reading plan file: %v", err)
+	}
+	target := opts.kubeconfig
+	if target == "" {
+		target = defaultKubeconfigFile()
+	}
+	_, _, context := install.KubeconfigEntryNames(plan, user)
+
+	if opts.remove {
+		if err := install.RemoveMergedKubeconfig(plan, target, user); err != nil {
+			return err
+		}
+		util.PrettyPrintOk(out, "Context %q removed from %q", context, target)
+		return nil
+	}
+
+	source := filepath.Join(opts.generatedAssetsDir, "kubeconfig")
+	if user != "admin" {
+		source = install.UserKubeconfigFile(opts.generatedAssetsDir, user)
+	}
+	if err := install.MergeKubeconfig(plan, source, target, user, opts.setCurrent); err != nil {
+		return err
+	}
+	util.PrettyPrintOk(out, "Context %q merged into %q", context, target)
+	if opts.setCurrent {
+		util.PrettyPrintOk(out, "Current context set to %q", context)
+	}
+	return nil
+}
+
+// defaultKubeconfigFile returns the kubeconfig file used by kubectl by default
+func defaultKubeconfigFile() string {
+	for _, f := range filepath.SplitList(os.Getenv("KUBECONFIG")) {
+		if f != "" {
+			return f
+		}
+	}
+	return filepath.Join(os.Getenv("HOME"), ".kube", "config")
+}
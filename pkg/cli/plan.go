@@ -23,6 +23,10 @@ func NewCmdPlan(in io.Reader, out io.Writer, options *installOpts) *cobra.Comman
 		},
 	}
 
+	// Subcommands
+	cmd.AddCommand(NewCmdPlanMigrate(out, options))
+	cmd.AddCommand(NewCmdPlanSchema(out))
+
 	return cmd
 }
 
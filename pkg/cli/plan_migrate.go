@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/apprenda/kismatic/pkg/install"
+	"github.com/spf13/cobra"
+)
+
+// NewCmdPlanMigrate creates a new install plan migrate command
+func NewCmdPlanMigrate(out io.Writer, options *installOpts) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "upgrade the plan file to the plan version of this release",
+		Long: `Upgrade the plan file to the plan version of this release.
+
+The fields that were deprecated by previous releases are moved to the fields
+that replaced them, and the plan file is replaced. The previous plan file is
+kept in a backup file next to the plan file.
+
+Plan files with a plan version newer than the one supported by this release
+are not modified.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return fmt.Errorf("Unexpected args: %v", args)
+			}
+			planner := &install.FilePlanner{File: options.planFilename}
+			return doPlanMigrate(out, planner)
+		},
+	}
+
+	return cmd
+}
+
+func doPlanMigrate(out io.Writer, planner *install.FilePlanner) error {
+	if !planner.PlanExists() {
+		return planFileNotFoundErr{filename: planner.File}
+	}
+	m, err := install.MigratePlanFile(planner)
+	if err != nil {
+		return fmt.Errorf("error migrating plan file: %v", err)
+	}
+	if m.FromVersion == m.ToVersion {
+		fmt.Fprintf(out, "Plan file %q is already at version %d\n", planner.File, m.ToVersion)
+		return nil
+	}
+	fmt.Fprintf(out, "Migrated plan file %q from version %d to version %d\n", planner.File, m.FromVersion, m.ToVersion)
+	for _, c := range m.Changes {
+		fmt.Fprintf(out, "- %s\n", c)
+	}
+	fmt.Fprintf(out, "The previous plan file was saved to %q\n", m.BackupFile)
+	return nil
+}
@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/apprenda/kismatic/pkg/install"
+)
+
+func TestPlanMigrateCmd(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "test-plan-migrate")
+	if err != nil {
+		t.Fatalf("error creating tmp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	planner := &install.FilePlanner{File: filepath.Join(tmpDir, "kismatic-cluster.yaml")}
+
+	out := &bytes.Buffer{}
+	if err := doPlanMigrate(out, planner); err == nil {
+		t.Error("expected an error when the plan file does not exist")
+	}
+
+	plan := "cluster:\n  name: test\n  allow_package_installation: true\n"
+	if err := ioutil.WriteFile(planner.File, []byte(plan), 0644); err != nil {
+		t.Fatalf("error writing plan file: %v", err)
+	}
+	if err := doPlanMigrate(out, planner); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, s := range []string{"from version 0 to version 1", "- cluster.allow_package_installation renamed to cluster.disable_package_installation", planner.File + ".v0.bak"} {
+		if !strings.Contains(out.String(), s) {
+			t.Errorf("expected output to contain %q, but got:\n%s", s, out.String())
+		}
+	}
+
+	out.Reset()
+	if err := doPlanMigrate(out, planner); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), "is already at version 1") {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+}
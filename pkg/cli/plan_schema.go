@@ -0,0 +1,33 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/apprenda/kismatic/pkg/install"
+	"github.com/spf13/cobra"
+)
+
+// NewCmdPlanSchema creates a new install plan schema command
+func NewCmdPlanSchema(out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "schema",
+		Short: "print the JSON Schema of the plan file",
+		Long: `Print the JSON Schema of the plan file.
+
+The schema describes the fields of the plan file supported by this release,
+including the required fields, the default values and the valid options. It can
+be used by editors and CI pipelines to validate and autocomplete the plan file.`,
+		Example: `  # Write the schema to a file
+  kismatic install plan schema > kismatic-cluster.schema.json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return fmt.Errorf("Unexpected args: %v", args)
+			}
+			_, err := fmt.Fprint(out, install.PlanJSONSchema)
+			return err
+		},
+	}
+
+	return cmd
+}
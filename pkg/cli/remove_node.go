@@ -0,0 +1,147 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/apprenda/kismatic/pkg/data"
+	"github.com/apprenda/kismatic/pkg/install"
+	"github.com/apprenda/kismatic/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+type removeNodeOpts struct {
+	GeneratedAssetsDirectory string
+	OutputFormat             string
+	Verbose                  bool
+	IgnoreSafetyChecks       bool
+}
+
+// NewCmdRemoveNode returns the command for removing nodes from the cluster
+func NewCmdRemoveNode(in io.Reader, out io.Writer, installOpts *installOpts) *cobra.Command {
+	opts := &removeNodeOpts{}
+	cmd := &cobra.Command{
+		Use:   "remove-node HOST",
+		Short: "remove a node from an existing Kubernetes cluster",
+		Long: `Remove a node from an existing Kubernetes cluster.
+
+Before removing the node, Kismatic will run the same safety checks that are
+performed during an online upgrade. If any unsafe condition is detected, a report
+will be printed, and the user will be asked to confirm the removal.
+
+The node is drained of workloads and deleted from the cluster. If the node is an
+etcd node, it is removed from the etcd clusters. The services running on the node
+are stopped, the Kismatic packages are removed, and the node's certificates are
+archived in the generated assets directory.
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return cmd.Usage()
+			}
+			return doRemoveNode(in, out, installOpts.planFilename, opts, args[0])
+		},
+	}
+	cmd.Flags().StringVar(&opts.GeneratedAssetsDirectory, "generated-assets-dir", "generated", "path to the directory where assets generated during the installation process will be stored")
+	cmd.Flags().BoolVar(&opts.Verbose, "verbose", false, "enable verbose logging from the installation")
+	cmd.Flags().StringVarP(&opts.OutputFormat, "output", "o", "simple", "installation output format (options \"simple\"|\"raw\"|\"json\")")
+	cmd.Flags().BoolVar(&opts.IgnoreSafetyChecks, "ignore-safety-checks", false, "ignore safety checks and continue with the removal")
+	return cmd
+}
+
+func doRemoveNode(in io.Reader, out io.Writer, planFile string, opts *removeNodeOpts, host string) error {
+	planner := &install.FilePlanner{File: planFile}
+	if !planner.PlanExists() {
+		return planFileNotFoundErr{filename: planFile}
+	}
+	execOpts := install.ExecutorOptions{
+		GeneratedAssetsDirectory: opts.GeneratedAssetsDirectory,
+		OutputFormat:             opts.OutputFormat,
+		Verbose:                  opts.Verbose,
+	}
+	executor, err := install.NewExecutor(out, os.Stderr, execOpts)
+	if err != nil {
+		return err
+	}
+	// With the json output format, stdout is reserved for the json records
+	out = util.MessageWriter(out, os.Stderr, opts.OutputFormat)
+	plan, err := planner.Read()
+	if err != nil {
+		return fmt.Errorf("failed to read plan file: %v", err)
+	}
+	node, err := findNodeWithHost(*plan, host)
+	if err != nil {
+		return err
+	}
+	if err = checkNodeRemovalSafety(in, out, *plan, *node, opts.GeneratedAssetsDirectory, opts.IgnoreSafetyChecks); err != nil {
+		return err
+	}
+	updatedPlan, err := executor.RemoveNode(plan, *node)
+	if err != nil {
+		return err
+	}
+	if err := planner.Write(updatedPlan); err != nil {
+		return fmt.Errorf("error updating plan file to remove node: %v", err)
+	}
+	util.PrintColor(out, util.Green, "The node %q was removed from the cluster.\n", host)
+	return nil
+}
+
+// returns the node in the plan that has the given host name
+func findNodeWithHost(plan install.Plan, host string) (*install.Node, error) {
+	for _, n := range plan.GetUniqueNodes() {
+		if n.Host == host {
+			return &n, nil
+		}
+	}
+	return nil, fmt.Errorf("node %q was not found in the plan file", host)
+}
+
+func checkNodeRemovalSafety(in io.Reader, out io.Writer, plan install.Plan, node install.Node, generatedAssetsDir string, ignoreSafetyChecks bool) error {
+	util.PrintHeader(out, "Validate Node Removal", '=')
+	// Use a master node that is not being removed for running kubectl
+	var master *install.Node
+	for _, m := range plan.Master.Nodes {
+		if m.IP != node.IP {
+			master = &m
+			break
+		}
+	}
+	if master == nil {
+		return errors.New("This is the only master node in the cluster, and it cannot be removed.")
+	}
+	client, err := plan.GetSSHClient(master.Host, generatedAssetsDir)
+	if err != nil {
+		return fmt.Errorf("error getting SSH client: %v", err)
+	}
+	kubeClient := data.RemoteKubectl{SSHClient: client}
+	util.PrettyPrint(out, "%s %v", node.Host, plan.GetRolesForIP(node.IP))
+	errs := install.DetectNodeRemovalSafety(plan, node, kubeClient)
+	if len(errs) == 0 {
+		util.PrintOkln(out)
+		return nil
+	}
+	if ignoreSafetyChecks {
+		util.PrintWarn(out)
+	} else {
+		util.PrintError(out)
+	}
+	fmt.Fprintln(out)
+	for _, err := range errs {
+		fmt.Fprintln(out, "-", err.Error())
+	}
+	if !ignoreSafetyChecks {
+		fmt.Fprintln(out)
+		ans, err := util.PromptForString(in, out, "Unsafe conditions detected, continue with the removal anyway?", "N", []string{"N", "y"})
+		if err != nil {
+			return fmt.Errorf("error getting user response: %v", err)
+		}
+		if strings.ToLower(ans) != "y" {
+			return errors.New("Unable to remove the node due to the unsafe conditions detected.")
+		}
+	}
+	util.PrettyPrintWarn(out, "\nIgnoring safety checks and continuing with the removal")
+	return nil
+}
@@ -0,0 +1,230 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/apprenda/kismatic/pkg/install"
+	"github.com/apprenda/kismatic/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+const runTimeFormat = "2006-01-02 15:04:05"
+
+type runsOpts struct {
+	runsDir      string
+	outputFormat string
+}
+
+// NewCmdRuns creates a new runs command
+func NewCmdRuns(out io.Writer) *cobra.Command {
+	opts := &runsOpts{}
+	cmd := &cobra.Command{
+		Use:   "runs",
+		Short: "Inspect the history of operations performed on the cluster",
+		Long: `Inspect the history of operations performed on the cluster.
+
+Every operation performed by Kismatic is recorded in the runs directory,
+along with the plan file that was used and the ansible log.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+	cmd.PersistentFlags().StringVar(&opts.runsDir, "runs-dir", "runs", "path to the directory where the runs are recorded")
+	cmd.PersistentFlags().StringVarP(&opts.outputFormat, "output", "o", "simple", `output format (options "simple"|"json")`)
+
+	cmd.AddCommand(NewCmdRunsList(out, opts))
+	cmd.AddCommand(NewCmdRunsShow(out, opts))
+
+	return cmd
+}
+
+// NewCmdRunsList creates a new command for listing runs
+func NewCmdRunsList(out io.Writer, opts *runsOpts) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the operations performed on the cluster",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return cmd.Usage()
+			}
+			return doRunsList(out, opts)
+		},
+	}
+}
+
+// NewCmdRunsShow creates a new command for showing the details of a run
+func NewCmdRunsShow(out io.Writer, opts *runsOpts) *cobra.Command {
+	return &cobra.Command{
+		Use:   "show RUN",
+		Short: "Show the details of an operation performed on the cluster",
+		Long: `Show the details of an operation performed on the cluster.
+
+RUN is the ID of the run as printed by "kismatic runs list", or the timestamp of the run.
+The failed hosts and tasks are listed, along with the changes made to the plan file
+since the previous run.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return cmd.Usage()
+			}
+			return doRunsShow(out, opts, args[0])
+		},
+	}
+}
+
+func doRunsList(out io.Writer, opts *runsOpts) error {
+	if err := validateRunsOutput(opts.outputFormat); err != nil {
+		return err
+	}
+	runs, err := install.ListRuns(opts.runsDir)
+	if err != nil {
+		return err
+	}
+	if opts.outputFormat == "json" {
+		return printJSON(out, runs)
+	}
+	if len(runs) == 0 {
+		fmt.Fprintf(out, "No runs were found in %q.\n", opts.runsDir)
+		return nil
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprint(w, "ID\tOperation\tStart\tEnd\tResult\n")
+	for _, r := range runs {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", r.ID, r.Metadata.Operation, formatRunTime(&r.Metadata.Start), formatRunTime(r.Metadata.End), r.Metadata.Result)
+	}
+	return w.Flush()
+}
+
+// runDetails is the json representation of a run
+type runDetails struct {
+	install.Run
+	PreviousRun string          `json:"previousRun,omitempty"`
+	PlanDiff    []util.DiffLine `json:"planDiff,omitempty"`
+}
+
+func doRunsShow(out io.Writer, opts *runsOpts, id string) error {
+	if err := validateRunsOutput(opts.outputFormat); err != nil {
+		return err
+	}
+	run, err := install.GetRun(opts.runsDir, id)
+	if err != nil {
+		return err
+	}
+	prev, err := install.PreviousRun(opts.runsDir, *run)
+	if err != nil {
+		return err
+	}
+	var diff []util.DiffLine
+	if prev != nil {
+		if diff, err = diffRunPlans(*prev, *run); err != nil {
+			return err
+		}
+	}
+
+	if opts.outputFormat == "json" {
+		d := runDetails{Run: *run, PlanDiff: diff}
+		if prev != nil {
+			d.PreviousRun = prev.ID
+		}
+		return printJSON(out, d)
+	}
+
+	meta := run.Metadata
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintf(w, "ID:\t%s\n", run.ID)
+	fmt.Fprintf(w, "Operation:\t%s\n", meta.Operation)
+	if meta.Playbook != "" {
+		fmt.Fprintf(w, "Playbook:\t%s\n", meta.Playbook)
+	}
+	if len(meta.Limit) > 0 {
+		fmt.Fprintf(w, "Limit:\t%s\n", strings.Join(meta.Limit, ","))
+	}
+	fmt.Fprintf(w, "Start:\t%s\n", formatRunTime(&meta.Start))
+	fmt.Fprintf(w, "End:\t%s\n", formatRunTime(meta.End))
+	fmt.Fprintf(w, "Result:\t%s\n", meta.Result)
+	if meta.Error != "" {
+		fmt.Fprintf(w, "Error:\t%s\n", meta.Error)
+	}
+	if len(meta.FailedHosts) > 0 {
+		fmt.Fprintf(w, "Failed Hosts:\t%s\n", strings.Join(meta.FailedHosts, ","))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if len(meta.FailedTasks) > 0 {
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Failed Tasks:")
+		w = tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+		fmt.Fprint(w, "Host\tTask\tMessage\n")
+		for _, t := range meta.FailedTasks {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", t.Host, t.Task, t.Message)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintln(out)
+	if prev == nil {
+		fmt.Fprintln(out, "There is no previous run to compare the plan file with.")
+		return nil
+	}
+	if !util.HasChanges(diff) {
+		fmt.Fprintf(out, "The plan file has not changed since the previous run (%s).\n", prev.ID)
+		return nil
+	}
+	fmt.Fprintf(out, "Changes to the plan file since the previous run (%s):\n", prev.ID)
+	for _, l := range diff {
+		if l.Op == " " {
+			continue
+		}
+		fmt.Fprintf(out, "%s %s\n", l.Op, l.Text)
+	}
+	return nil
+}
+
+// returns the diff between the plan files of the two runs
+func diffRunPlans(prev, run install.Run) ([]util.DiffLine, error) {
+	before, err := ioutil.ReadFile(prev.PlanFile())
+	if err != nil {
+		return nil, fmt.Errorf("error reading plan file of run %q: %v", prev.ID, err)
+	}
+	after, err := ioutil.ReadFile(run.PlanFile())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading plan file of run %q: %v", run.ID, err)
+	}
+	return util.DiffLines(string(before), string(after)), nil
+}
+
+func validateRunsOutput(format string) error {
+	if format != "simple" && format != "json" {
+		return fmt.Errorf("output format %q is not supported", format)
+	}
+	return nil
+}
+
+func formatRunTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format(runTimeFormat)
+}
+
+func printJSON(out io.Writer, v interface{}) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshalling struct: %v", err)
+	}
+	fmt.Fprintln(out, string(b))
+	return nil
+}
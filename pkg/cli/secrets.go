@@ -0,0 +1,22 @@
+package cli
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+)
+
+// NewCmdSecrets creates a new secrets command
+func NewCmdSecrets(out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "secrets",
+		Short: "Manage the encryption of the Kubernetes secrets",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(NewCmdRotateKey(out))
+
+	return cmd
+}
@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/apprenda/kismatic/pkg/install"
+	"github.com/apprenda/kismatic/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+type secretsRotateKeyOpts struct {
+	planFile           string
+	generatedAssetsDir string
+	verbose            bool
+	outputFormat       string
+}
+
+// NewCmdRotateKey creates a new secrets rotate-key command
+func NewCmdRotateKey(out io.Writer) *cobra.Command {
+	opts := &secretsRotateKeyOpts{}
+
+	cmd := &cobra.Command{
+		Use:   "rotate-key",
+		Short: "Replace the key used to encrypt the Kubernetes secrets",
+		Long: `Replace the key used to encrypt the Kubernetes secrets.
+
+A new key is generated for the encryption provider of the plan file, and is
+added to the encryption config of the API servers. Once all the API servers
+can decrypt the secrets with the new key, it becomes the encryption key, and
+all the secrets are re-encrypted. The previous keys are then removed.
+
+The encryption config is stored in the --generated-assets-dir, and is deployed
+to one master node at a time.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return fmt.Errorf("Unexpected args: %v", args)
+			}
+			return doSecretsRotateKey(out, opts)
+		},
+	}
+
+	addPlanFileFlag(cmd.Flags(), &opts.planFile)
+	cmd.Flags().StringVar(&opts.generatedAssetsDir, "generated-assets-dir", "generated", "path to the directory where assets generated during the installation process will be stored")
+	cmd.Flags().BoolVar(&opts.verbose, "verbose", false, "enable verbose logging from the installation")
+	cmd.Flags().StringVarP(&opts.outputFormat, "output", "o", "simple", "installation output format (options \"simple\"|\"raw\"|\"json\")")
+
+	return cmd
+}
+
+func doSecretsRotateKey(stdout io.Writer, opts *secretsRotateKeyOpts) error {
+	planner := &install.FilePlanner{File: opts.planFile}
+	executorOpts := install.ExecutorOptions{
+		GeneratedAssetsDirectory: opts.generatedAssetsDir,
+		OutputFormat:             opts.outputFormat,
+		Verbose:                  opts.verbose,
+	}
+	executor, err := install.NewExecutor(stdout, os.Stderr, executorOpts)
+	if err != nil {
+		return err
+	}
+	valOpts := &validateOpts{
+		planFile:           opts.planFile,
+		verbose:            opts.verbose,
+		outputFormat:       opts.outputFormat,
+		skipPreFlight:      true,
+		generatedAssetsDir: opts.generatedAssetsDir,
+	}
+	if err := doValidate(stdout, planner, valOpts); err != nil {
+		return err
+	}
+	plan, err := planner.Read()
+	if err != nil {
+		return fmt.Errorf("error reading plan file: %v", err)
+	}
+
+	if err := executor.RotateEncryptionKey(*plan); err != nil {
+		return fmt.Errorf("error rotating the encryption key: %v", err)
+	}
+
+	out := util.MessageWriter(stdout, os.Stderr, opts.outputFormat)
+	util.PrintColor(out, util.Green, "\nThe encryption key of the secrets was rotated successfully\n\n")
+	return nil
+}
@@ -5,18 +5,17 @@ import (
 	"io"
 	"strings"
 
-	"github.com/apprenda/kismatic/pkg/ssh"
-
 	"github.com/apprenda/kismatic/pkg/install"
 	"github.com/apprenda/kismatic/pkg/util"
 	"github.com/spf13/cobra"
 )
 
 type sshOpts struct {
-	planFilename string
-	host         string
-	pty          bool
-	arguments    []string
+	planFilename       string
+	generatedAssetsDir string
+	host               string
+	pty                bool
+	arguments          []string
 }
 
 // NewCmdSSH returns an ssh shell
@@ -58,6 +57,7 @@ HOST must be one of the following:
 	}
 
 	cmd.Flags().StringVarP(&opts.planFilename, "plan-file", "f", "kismatic-cluster.yaml", "path to the installation plan file")
+	cmd.Flags().StringVar(&opts.generatedAssetsDir, "generated-assets-dir", "generated", "path to the directory where assets generated during the installation process will be stored")
 	cmd.Flags().BoolVarP(&opts.pty, "pty", "t", false, "force PTY \"-t\" flag on the SSH connection")
 
 	return cmd
@@ -76,15 +76,15 @@ func doSSH(out io.Writer, planner install.Planner, opts *sshOpts) error {
 	}
 
 	// validate SSH access to node
-	ok, errs := install.ValidateSSHConnection(con, "")
+	ok, errs := install.ValidateSSHConnection(con, opts.generatedAssetsDir, "")
 	if !ok {
 		util.PrintValidationErrors(out, errs)
 		return fmt.Errorf("cannot validate SSH connection to node %q", opts.host)
 	}
 
-	client, err := ssh.NewClient(con.Node.IP, con.SSHConfig.Port, con.SSHConfig.User, con.SSHConfig.Key)
+	client, err := plan.GetSSHClient(opts.host, opts.generatedAssetsDir)
 	if err != nil {
-		return fmt.Errorf("error creating SSH client: %v", err)
+		return err
 	}
 
 	if err = client.Shell(opts.pty, opts.arguments...); err != nil {
@@ -52,7 +52,7 @@ Nodes in the cluster are upgraded in the following order:
 
 	cmd.PersistentFlags().StringVar(&opts.generatedAssetsDir, "generated-assets-dir", "generated", "path to the directory where assets generated during the installation process will be stored")
 	cmd.PersistentFlags().BoolVar(&opts.verbose, "verbose", false, "enable verbose logging from the installation")
-	cmd.PersistentFlags().StringVarP(&opts.outputFormat, "output", "o", "simple", "installation output format (options \"simple\"|\"raw\")")
+	cmd.PersistentFlags().StringVarP(&opts.outputFormat, "output", "o", "simple", "installation output format (options \"simple\"|\"raw\"|\"json\")")
 	cmd.PersistentFlags().BoolVar(&opts.skipPreflight, "skip-preflight", false, "skip upgrade pre-flight checks")
 	cmd.PersistentFlags().BoolVar(&opts.restartServices, "restart-services", false, "force restart cluster services (Use with care)")
 	cmd.PersistentFlags().BoolVar(&opts.partialAllowed, "partial-ok", false, "allow the upgrade of ready nodes, and skip nodes that have been deemed unready for upgrade")
@@ -135,6 +135,8 @@ func doUpgrade(in io.Reader, out io.Writer, opts *upgradeOpts) error {
 	if err != nil {
 		return err
 	}
+	// With the json output format, stdout is reserved for the json records
+	out = util.MessageWriter(out, os.Stderr, opts.outputFormat)
 	util.PrintHeader(out, "Computing upgrade plan", '=')
 
 	// Read plan file
@@ -154,7 +156,7 @@ func doUpgrade(in io.Reader, out io.Writer, opts *upgradeOpts) error {
 		return err
 	}
 
-	if err = validateSSHConnectivity(out, plan); err != nil {
+	if err = validateSSHConnectivity(out, plan, opts.generatedAssetsDir); err != nil {
 		return err
 	}
 
@@ -163,6 +165,14 @@ func doUpgrade(in io.Reader, out io.Writer, opts *upgradeOpts) error {
 		return err
 	}
 
+	// The secrets encryption key is not generated during a dry run, so that
+	// encryption can still be disabled afterwards
+	if !opts.dryRun {
+		if err = install.GenerateSecretsEncryptionConfig(plan, opts.generatedAssetsDir); err != nil {
+			return fmt.Errorf("error generating secrets encryption config: %v", err)
+		}
+	}
+
 	util.PrintHeader(out, "Generating Kubeconfig File", '=')
 	isDiff, err := install.RegenerateKubeconfig(plan, opts.generatedAssetsDir)
 	if err != nil {
@@ -176,7 +186,7 @@ func doUpgrade(in io.Reader, out io.Writer, opts *upgradeOpts) error {
 	}
 
 	// Get the cluster and node versions
-	cv, err := install.ListVersions(plan)
+	cv, err := install.ListVersions(plan, opts.generatedAssetsDir)
 	if err != nil {
 		return fmt.Errorf("error listing cluster versions: %v", err)
 	}
@@ -249,7 +259,7 @@ func upgradeNodes(in io.Reader, out io.Writer, plan install.Plan, opts upgradeOp
 	if opts.online {
 		util.PrintHeader(out, "Validate Online Upgrade", '=')
 		// Use the first master node for running kubectl
-		client, err := plan.GetSSHClient(plan.Master.Nodes[0].Host)
+		client, err := plan.GetSSHClient(plan.Master.Nodes[0].Host, opts.generatedAssetsDir)
 		if err != nil {
 			return fmt.Errorf("error getting SSH client: %v", err)
 		}
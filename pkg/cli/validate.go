@@ -18,6 +18,7 @@ type validateOpts struct {
 	verbose            bool
 	outputFormat       string
 	skipPreFlight      bool
+	skipSSH            bool
 }
 
 // NewCmdValidate creates a new install validate command
@@ -37,12 +38,13 @@ func NewCmdValidate(out io.Writer, installOpts *installOpts) *cobra.Command {
 	}
 	cmd.Flags().StringVar(&opts.generatedAssetsDir, "generated-assets-dir", "generated", "path to the directory where assets generated during the installation process will be stored")
 	cmd.Flags().BoolVar(&opts.verbose, "verbose", false, "enable verbose logging from the installation")
-	cmd.Flags().StringVarP(&opts.outputFormat, "output", "o", "simple", "installation output format (options simple|raw)")
+	cmd.Flags().StringVarP(&opts.outputFormat, "output", "o", "simple", "installation output format (options simple|raw|json)")
 	cmd.Flags().BoolVar(&opts.skipPreFlight, "skip-preflight", false, "skip pre-flight checks")
 	return cmd
 }
 
-func doValidate(out io.Writer, planner install.Planner, opts *validateOpts) error {
+func doValidate(stdout io.Writer, planner install.Planner, opts *validateOpts) error {
+	out := util.MessageWriter(stdout, os.Stderr, opts.outputFormat)
 	util.PrintHeader(out, "Validating", '=')
 	// Check if plan file exists
 	if !planner.PlanExists() {
@@ -62,9 +64,18 @@ func doValidate(out io.Writer, planner install.Planner, opts *validateOpts) erro
 		return err
 	}
 
+	// Validate secrets encryption
+	if ok, errs := install.ValidateSecretsEncryption(plan, opts.generatedAssetsDir); !ok {
+		util.PrettyPrintErr(out, "Validating secrets encryption")
+		util.PrintValidationErrors(out, errs)
+		return fmt.Errorf("Secrets encryption validation error prevents installation from proceeding")
+	}
+
 	// Validate SSH connections
-	if err := validateSSHConnectivity(out, plan); err != nil {
-		return err
+	if !opts.skipSSH {
+		if err := validateSSHConnectivity(out, plan, opts.generatedAssetsDir); err != nil {
+			return err
+		}
 	}
 
 	// get a new pki
@@ -85,10 +96,11 @@ func doValidate(out io.Writer, planner install.Planner, opts *validateOpts) erro
 	}
 	// Run pre-flight
 	options := install.ExecutorOptions{
-		OutputFormat: opts.outputFormat,
-		Verbose:      opts.verbose,
+		GeneratedAssetsDirectory: opts.generatedAssetsDir,
+		OutputFormat:             opts.outputFormat,
+		Verbose:                  opts.verbose,
 	}
-	e, err := install.NewPreFlightExecutor(out, os.Stderr, options)
+	e, err := install.NewPreFlightExecutor(stdout, os.Stderr, options)
 	if err != nil {
 		return err
 	}
@@ -121,8 +133,8 @@ func validatePlan(out io.Writer, plan *install.Plan) error {
 	return nil
 }
 
-func validateSSHConnectivity(out io.Writer, plan *install.Plan) error {
-	ok, errs := install.ValidatePlanSSHConnections(plan)
+func validateSSHConnectivity(out io.Writer, plan *install.Plan, generatedAssetsDir string) error {
+	ok, errs := install.ValidatePlanSSHConnections(plan, generatedAssetsDir)
 	if !ok {
 		util.PrettyPrintErr(out, "Validating SSH connectivity to nodes")
 		util.PrintValidationErrors(out, errs)
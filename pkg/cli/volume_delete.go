@@ -41,7 +41,7 @@ WARNING all data in the volume will be lost.`,
 		},
 	}
 	cmd.Flags().BoolVar(&opts.verbose, "verbose", false, "enable verbose logging")
-	cmd.Flags().StringVarP(&opts.outputFormat, "output", "o", "simple", `output format (options simple|raw)`)
+	cmd.Flags().StringVarP(&opts.outputFormat, "output", "o", "simple", `output format (options simple|raw|json)`)
 	cmd.Flags().StringVar(&opts.generatedAssetsDir, "generated-assets-dir", "generated", "path to the directory where assets generated during the installation process will be stored")
 	cmd.Flags().BoolVar(&opts.force, "force", false, `do not prompt`)
 	return cmd
@@ -68,7 +68,7 @@ func doVolumeDelete(out io.Writer, opts volumeDeleteOptions, planFile string, ar
 		// Need to refactor executor code... this will do for now as we don't need the generated assets dir in this command
 		GeneratedAssetsDirectory: opts.generatedAssetsDir,
 	}
-	exec, err := install.NewExecutor(out, out, execOpts)
+	exec, err := install.NewExecutor(out, os.Stderr, execOpts)
 	if err != nil {
 		return err
 	}
@@ -88,6 +88,8 @@ func doVolumeDelete(out io.Writer, opts volumeDeleteOptions, planFile string, ar
 	if err := doValidate(out, planner, vopts); err != nil {
 		return err
 	}
+	// With the json output format, stdout is reserved for the json records
+	out = util.MessageWriter(out, os.Stderr, opts.outputFormat)
 
 	if err := exec.DeleteVolume(plan, volumeName); err != nil {
 		return fmt.Errorf("error deleting volume: %v", err)
@@ -13,7 +13,8 @@ import (
 )
 
 type volumeListOptions struct {
-	outputFormat string
+	outputFormat       string
+	generatedAssetsDir string
 }
 
 // NewCmdVolumeList returns the command for listgin storage volumes
@@ -30,6 +31,7 @@ This function requires a target cluster that has storage nodes.`,
 	}
 
 	cmd.Flags().StringVarP(&opts.outputFormat, "output", "o", "simple", `output format (options "simple"|"json")`)
+	cmd.Flags().StringVar(&opts.generatedAssetsDir, "generated-assets-dir", "generated", "path to the directory where assets generated during the installation process will be stored")
 	return cmd
 }
 
@@ -51,14 +53,14 @@ func doVolumeList(out io.Writer, opts volumeListOptions, planFile string, args [
 	}
 
 	// find storage node
-	clientStorage, err := plan.GetSSHClient("storage")
+	clientStorage, err := plan.GetSSHClient("storage", opts.generatedAssetsDir)
 	if err != nil {
 		return err
 	}
 	glusterClient := data.RemoteGlusterCLI{SSHClient: clientStorage}
 
 	// find master node
-	clientMaster, err := plan.GetSSHClient("master")
+	clientMaster, err := plan.GetSSHClient("master", opts.generatedAssetsDir)
 	if err != nil {
 		return err
 	}
@@ -48,6 +48,11 @@ type StatefulSetGetter interface {
 	GetStatefulSet(namespace, name string) (*StatefulSet, error)
 }
 
+// RoleBindingCreator creates role bindings
+type RoleBindingCreator interface {
+	CreateRoleBinding(namespace, name, clusterRole, user string) error
+}
+
 type KubernetesClient interface {
 	PodLister
 	PVLister
@@ -210,6 +215,20 @@ func (k RemoteKubectl) GetStatefulSet(namespace, name string) (*StatefulSet, err
 	return &s, nil
 }
 
+// CreateRoleBinding binds the cluster role to the user. A cluster role binding
+// is created when the namespace is empty, otherwise a role binding is created in
+// the namespace. An existing binding with the same name is replaced.
+func (k RemoteKubectl) CreateRoleBinding(namespace, name, clusterRole, user string) error {
+	create := fmt.Sprintf("sudo kubectl create clusterrolebinding %s --clusterrole=%s --user=%s --dry-run -o yaml", name, clusterRole, user)
+	if namespace != "" {
+		create = fmt.Sprintf("sudo kubectl create rolebinding %s --namespace=%s --clusterrole=%s --user=%s --dry-run -o yaml", name, namespace, clusterRole, user)
+	}
+	if _, err := k.SSHClient.Output(true, create+" | sudo kubectl apply -f -"); err != nil {
+		return fmt.Errorf("error creating role binding %s: %v", name, err)
+	}
+	return nil
+}
+
 // kubectl will print this message when no resources are returned
 func isNoResourcesResponse(s string) bool {
 	if strings.Contains(strings.TrimSpace(s), "No resources found") {
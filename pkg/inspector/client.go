@@ -16,7 +16,10 @@ type Client struct {
 	TargetNode string
 	// TargetNodeRole is the role of the node we are inspecting
 	TargetNodeFacts []string
-	engine          *rule.Engine
+	// Dial is used to connect to the inspector server. When nil, the
+	// connection is established directly.
+	Dial   func(network, addr string) (net.Conn, error)
+	engine *rule.Engine
 }
 
 // NewClient returns an inspector client for running checks against remote nodes.
@@ -45,7 +48,8 @@ func (c Client) ExecuteRules(rules []rule.Rule) ([]rule.Result, error) {
 	if err != nil {
 		return nil, fmt.Errorf("error marshaling check request: %v", err)
 	}
-	resp, err := http.Post(fmt.Sprintf("http://%s%s", c.TargetNode, executeEndpoint), "application/json", bytes.NewReader(d))
+	httpClient := c.httpClient()
+	resp, err := httpClient.Post(fmt.Sprintf("http://%s%s", c.TargetNode, executeEndpoint), "application/json", bytes.NewReader(d))
 	if err != nil {
 		return nil, fmt.Errorf("error posting request to server: %v", err)
 	}
@@ -78,7 +82,7 @@ func (c Client) ExecuteRules(rules []rule.Rule) ([]rule.Result, error) {
 	results = append(results, remoteResults...)
 
 	endpoint := fmt.Sprintf("http://%s%s", c.TargetNode, closeEndpoint)
-	resp, err = http.Get(endpoint)
+	resp, err = httpClient.Get(endpoint)
 	if err != nil {
 		return nil, fmt.Errorf("GET request to %q failed. You might have to restart the inspector server. Error was: %v", endpoint, err)
 	}
@@ -86,6 +90,13 @@ func (c Client) ExecuteRules(rules []rule.Rule) ([]rule.Result, error) {
 	return results, nil
 }
 
+func (c Client) httpClient() *http.Client {
+	if c.Dial == nil {
+		return http.DefaultClient
+	}
+	return &http.Client{Transport: &http.Transport{Dial: c.Dial}}
+}
+
 func getServerSideRules(rules []rule.Rule) []rule.Rule {
 	localRules := []rule.Rule{}
 	for _, r := range rules {
@@ -4,8 +4,12 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net"
+	"strconv"
+	"strings"
 
 	"github.com/apprenda/kismatic/pkg/inspector"
+	"github.com/apprenda/kismatic/pkg/ssh"
 	"github.com/spf13/cobra"
 )
 
@@ -15,6 +19,8 @@ type clientOpts struct {
 	rulesFile          string
 	targetNode         string
 	useUpgradeDefaults bool
+	bastion            string
+	bastionKey         string
 }
 
 var clientExample = `# Run the inspector against an etcd node
@@ -24,7 +30,10 @@ kismatic-inspector client 10.0.1.24:9090 --node-roles etcd
 kismatic-inspector client 10.0.1.24:9090 --node-roles etcd -o json
 
 # Run the inspector against a remote node using a custom rules file
-kismatic-inspector client 10.0.1.24:9090 -f inspector-rules.yaml --node-roles etcd`
+kismatic-inspector client 10.0.1.24:9090 -f inspector-rules.yaml --node-roles etcd
+
+# Run the inspector against a remote node that is only reachable through a bastion host
+kismatic-inspector client 10.0.1.24:9090 --node-roles etcd --bastion ubuntu@52.12.4.3:22 --bastion-key ~/.ssh/id_rsa`
 
 // NewCmdClient returns the "client" command
 func NewCmdClient(out io.Writer) *cobra.Command {
@@ -46,6 +55,8 @@ func NewCmdClient(out io.Writer) *cobra.Command {
 	cmd.Flags().StringVar(&opts.nodeRoles, "node-roles", "", "comma-separated list of the node's roles. Valid roles are 'etcd', 'master', 'worker'")
 	cmd.Flags().StringVarP(&opts.rulesFile, "file", "f", "", "the path to an inspector rules file. If blank, the inspector uses the default rules")
 	cmd.Flags().BoolVarP(&opts.useUpgradeDefaults, "upgrade", "u", false, "use defaults for upgrade, rather than install")
+	cmd.Flags().StringVar(&opts.bastion, "bastion", "", "USER@HOST[:PORT] of a bastion host through which the requests to the inspector server are tunneled over SSH")
+	cmd.Flags().StringVar(&opts.bastionKey, "bastion-key", "", "path to the SSH private key of the bastion host")
 	return cmd
 }
 
@@ -64,6 +75,18 @@ func runClient(out io.Writer, opts clientOpts) error {
 	if err != nil {
 		return fmt.Errorf("error creating inspector client: %v", err)
 	}
+	if opts.bastion != "" {
+		bastion, err := parseBastion(opts.bastion, opts.bastionKey)
+		if err != nil {
+			return err
+		}
+		sshClient, err := ssh.NewNativeClient(bastion.Host, bastion.Port, bastion.User, bastion.Key, ssh.NativeClientOptions{})
+		if err != nil {
+			return fmt.Errorf("error creating SSH client for bastion host: %v", err)
+		}
+		defer ssh.CloseConnections()
+		c.Dial = sshClient.Dial
+	}
 	rules, err := getRulesFromFileOrDefault(out, opts.rulesFile, opts.useUpgradeDefaults)
 	if err != nil {
 		return err
@@ -83,3 +106,24 @@ func runClient(out io.Writer, opts clientOpts) error {
 	}
 	return nil
 }
+
+// parseBastion parses a bastion host of the form USER@HOST[:PORT]
+func parseBastion(s string, key string) (*ssh.Bastion, error) {
+	if key == "" {
+		return nil, fmt.Errorf("--bastion-key is required when using a bastion host")
+	}
+	parts := strings.SplitN(s, "@", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("invalid bastion %q: must be of the form USER@HOST[:PORT]", s)
+	}
+	b := &ssh.Bastion{User: parts[0], Host: parts[1], Port: 22, Key: key}
+	if host, port, err := net.SplitHostPort(parts[1]); err == nil {
+		p, err := strconv.Atoi(port)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bastion port %q", port)
+		}
+		b.Host = host
+		b.Port = p
+	}
+	return b, nil
+}
@@ -3,7 +3,6 @@ package install
 import (
 	"fmt"
 
-	"github.com/apprenda/kismatic/pkg/ssh"
 	"github.com/apprenda/kismatic/pkg/util"
 	"github.com/blang/semver"
 )
@@ -66,7 +65,7 @@ func parseVersion(versionString string) (semver.Version, error) {
 
 // ListVersions connects to the cluster described in the plan file and
 // gathers version information about it.
-func ListVersions(plan *Plan) (ClusterVersion, error) {
+func ListVersions(plan *Plan, generatedAssetsDir string) (ClusterVersion, error) {
 	nodes := plan.GetUniqueNodes()
 	cv := ClusterVersion{
 		Nodes: []ListableNode{},
@@ -75,7 +74,7 @@ func ListVersions(plan *Plan) (ClusterVersion, error) {
 	sshDeets := plan.Cluster.SSH
 	verFile := "/etc/kismatic-version"
 	for i, node := range nodes {
-		client, err := ssh.NewClient(node.IP, sshDeets.Port, sshDeets.User, sshDeets.Key)
+		client, err := sshDeets.forNode(node).newClient(node, generatedAssetsDir)
 		if err != nil {
 			return cv, fmt.Errorf("error creating SSH client: %v", err)
 		}
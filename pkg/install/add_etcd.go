@@ -0,0 +1,103 @@
+package install
+
+import (
+	"fmt"
+
+	"github.com/apprenda/kismatic/pkg/util"
+)
+
+// AddEtcd adds an etcd node to the original cluster described in the plan.
+// The node joins the existing etcd clusters as a new member.
+// If successful, the updated plan is returned.
+func (ae *ansibleExecutor) AddEtcd(originalPlan *Plan, newEtcd Node) (*Plan, error) {
+	if err := checkAddNodePrereqs(ae.pki, originalPlan, newEtcd); err != nil {
+		return nil, err
+	}
+	updatedPlan := addEtcdToPlan(*originalPlan, newEtcd)
+
+	// Generate node certificates
+	util.PrintHeader(ae.stdout, "Generating Certificate For Etcd Node", '=')
+	ca, err := ae.pki.GetClusterCA(&updatedPlan)
+	if err != nil {
+		return nil, err
+	}
+	if err = ae.pki.GenerateNodeCertificate(&updatedPlan, newEtcd, ca); err != nil {
+		return nil, fmt.Errorf("error generating certificate for new etcd node: %v", err)
+	}
+
+	inventory := buildInventoryFromPlan(&updatedPlan, ae.options.GeneratedAssetsDirectory)
+	cc, err := ae.buildClusterCatalog(&updatedPlan)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate ansible vars: %v", err)
+	}
+	cc.NewEtcdNode = newEtcd.Host
+
+	// The existing members must know about the new member before it starts
+	util.PrintHeader(ae.stdout, "Adding Etcd Member", '=')
+	t := task{
+		name:           "add-etcd-member",
+		playbook:       "_etcd-member-add.yaml",
+		plan:           updatedPlan,
+		inventory:      inventory,
+		clusterCatalog: *cc,
+		explainer:      ae.defaultExplainer(),
+	}
+	if err = ae.execute(t); err != nil {
+		return nil, fmt.Errorf("error adding etcd member: %v", err)
+	}
+
+	// Run the playbook to add the etcd node
+	util.PrintHeader(ae.stdout, "Adding Etcd Node to Cluster", '=')
+	t = task{
+		name:           "add-etcd",
+		playbook:       "kubernetes-etcd.yaml",
+		plan:           updatedPlan,
+		inventory:      inventory,
+		clusterCatalog: *cc,
+		explainer:      ae.defaultExplainer(),
+		limit:          []string{newEtcd.Host},
+	}
+	if err = ae.execute(t); err != nil {
+		return nil, fmt.Errorf("error running playbook: %v", err)
+	}
+
+	// We need to run ansible against all hosts to update the hosts files
+	if err = ae.updateHostsFiles("add-etcd-update-hosts", updatedPlan, inventory, *cc); err != nil {
+		return nil, err
+	}
+
+	// The etcd endpoints are set on every master and on the network components
+	util.PrintHeader(ae.stdout, "Reconfiguring Control Plane", '=')
+	t = task{
+		name:           "add-etcd-reconfigure-control-plane",
+		playbook:       "reconfigure-control-plane.yaml",
+		plan:           updatedPlan,
+		inventory:      inventory,
+		clusterCatalog: *cc,
+		explainer:      ae.defaultExplainer(),
+	}
+	if err = ae.execute(t); err != nil {
+		return nil, fmt.Errorf("error reconfiguring the control plane: %v", err)
+	}
+	if cc.CNI.Enabled && cc.CNI.Provider == cniProviderCalico {
+		util.PrintHeader(ae.stdout, "Reconfiguring Cluster Network", '=')
+		t = task{
+			name:           "add-etcd-reconfigure-networking",
+			playbook:       "reconfigure-networking.yaml",
+			plan:           updatedPlan,
+			inventory:      inventory,
+			clusterCatalog: *cc,
+			explainer:      ae.defaultExplainer(),
+		}
+		if err = ae.execute(t); err != nil {
+			return nil, fmt.Errorf("error reconfiguring the cluster network: %v", err)
+		}
+	}
+	return &updatedPlan, nil
+}
+
+func addEtcdToPlan(plan Plan, etcd Node) Plan {
+	plan.Etcd.ExpectedCount++
+	plan.Etcd.Nodes = append(plan.Etcd.Nodes, etcd)
+	return plan
+}
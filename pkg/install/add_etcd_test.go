@@ -0,0 +1,134 @@
+package install
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/apprenda/kismatic/pkg/ansible"
+	"github.com/apprenda/kismatic/pkg/install/explain"
+)
+
+func TestAddEtcdCertMissingCAMissing(t *testing.T) {
+	e := ansibleExecutor{
+		options:             ExecutorOptions{RestartServices: true, RunsDirectory: mustGetTempDir(t)},
+		stdout:              ioutil.Discard,
+		consoleOutputFormat: ansible.RawFormat,
+		pki:                 &fakePKI{},
+		certsDir:            mustGetTempDir(t),
+	}
+	originalPlan := &Plan{
+		Etcd: NodeGroup{
+			Nodes: []Node{},
+		},
+	}
+	newPlan, err := e.AddEtcd(originalPlan, Node{})
+	if newPlan != nil {
+		t.Errorf("add etcd returned an updated plan")
+	}
+	if err != errMissingClusterCA {
+		t.Errorf("AddEtcd did not return the expected error. Instead returned: %v", err)
+	}
+}
+
+func TestAddEtcdPlanIsUpdated(t *testing.T) {
+	fakeRunner := fakeRunner{}
+	e := ansibleExecutor{
+		options:             ExecutorOptions{RunsDirectory: mustGetTempDir(t)},
+		stdout:              ioutil.Discard,
+		consoleOutputFormat: ansible.RawFormat,
+		pki: &fakePKI{
+			caExists: true,
+		},
+		runnerExplainerFactory: func(explain.AnsibleEventExplainer, io.Writer) (ansible.Runner, *explain.AnsibleEventStreamExplainer, error) {
+			return &fakeRunner, &explain.AnsibleEventStreamExplainer{}, nil
+		},
+		certsDir: mustGetTempDir(t),
+	}
+	originalPlan := &Plan{
+		Etcd: NodeGroup{
+			ExpectedCount: 1,
+			Nodes:         []Node{{Host: "existingEtcd"}},
+		},
+		Master: MasterNodeGroup{
+			Nodes: []Node{{InternalIP: "10.10.2.20"}},
+		},
+		Cluster: Cluster{
+			Networking: NetworkConfig{
+				ServiceCIDRBlock: "10.0.0.0/16",
+			},
+		},
+		AddOns: AddOns{
+			CNI: &CNI{Provider: cniProviderCalico},
+		},
+	}
+	newEtcd := Node{
+		Host: "test",
+	}
+	updatedPlan, err := e.AddEtcd(originalPlan, newEtcd)
+	if err != nil {
+		t.Fatalf("unexpected error while adding etcd: %v", err)
+	}
+	if updatedPlan.Etcd.ExpectedCount != 2 {
+		t.Errorf("expected count was not incremented")
+	}
+	found := false
+	for _, n := range updatedPlan.Etcd.Nodes {
+		if n.Equal(newEtcd) {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("the updated plan does not include the new etcd node")
+	}
+	if fakeRunner.incomingCatalog.NewEtcdNode != newEtcd.Host {
+		t.Errorf("expected new etcd node to be %q, but got %q", newEtcd.Host, fakeRunner.incomingCatalog.NewEtcdNode)
+	}
+	expected := []string{"_etcd-member-add.yaml", "reconfigure-control-plane.yaml", "reconfigure-networking.yaml"}
+	for _, e := range expected {
+		found = false
+		for _, p := range fakeRunner.allNodesPlaybooks {
+			if p == e {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("expected playbook %s was not run during add-etcd. The following plays ran: %v", e, fakeRunner.allNodesPlaybooks)
+		}
+	}
+}
+
+func TestAddEtcdPlanNotUpdatedAfterFailure(t *testing.T) {
+	e := ansibleExecutor{
+		options:             ExecutorOptions{RunsDirectory: mustGetTempDir(t)},
+		stdout:              ioutil.Discard,
+		consoleOutputFormat: ansible.RawFormat,
+		pki: &fakePKI{
+			caExists: true,
+		},
+		runnerExplainerFactory: fakeRunnerExplainer(errors.New("exec error")),
+		certsDir:               mustGetTempDir(t),
+	}
+	originalPlan := &Plan{
+		Etcd: NodeGroup{
+			ExpectedCount: 1,
+			Nodes:         []Node{{Host: "existingEtcd"}},
+		},
+		Master: MasterNodeGroup{
+			Nodes: []Node{{InternalIP: "10.10.2.20"}},
+		},
+		Cluster: Cluster{
+			Networking: NetworkConfig{
+				ServiceCIDRBlock: "10.0.0.0/16",
+			},
+		},
+	}
+	updatedPlan, err := e.AddEtcd(originalPlan, Node{Host: "test"})
+	if err == nil {
+		t.Errorf("expected an error, but didn't get one")
+	}
+	if updatedPlan != nil {
+		t.Error("plan was updated, even though adding etcd failed")
+	}
+}
@@ -0,0 +1,72 @@
+package install
+
+import (
+	"fmt"
+
+	"github.com/apprenda/kismatic/pkg/util"
+)
+
+// AddMaster adds a master node to the original cluster described in the plan.
+// If successful, the updated plan is returned.
+func (ae *ansibleExecutor) AddMaster(originalPlan *Plan, newMaster Node) (*Plan, error) {
+	if err := checkAddNodePrereqs(ae.pki, originalPlan, newMaster); err != nil {
+		return nil, err
+	}
+	updatedPlan := addMasterToPlan(*originalPlan, newMaster)
+
+	// Generate node certificates
+	util.PrintHeader(ae.stdout, "Generating Certificate For Master Node", '=')
+	ca, err := ae.pki.GetClusterCA(&updatedPlan)
+	if err != nil {
+		return nil, err
+	}
+	if err = ae.pki.GenerateNodeCertificate(&updatedPlan, newMaster, ca); err != nil {
+		return nil, fmt.Errorf("error generating certificate for new master: %v", err)
+	}
+
+	// Run the playbook to add the master
+	inventory := buildInventoryFromPlan(&updatedPlan, ae.options.GeneratedAssetsDirectory)
+	cc, err := ae.buildClusterCatalog(&updatedPlan)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate ansible vars: %v", err)
+	}
+	util.PrintHeader(ae.stdout, "Adding Master Node to Cluster", '=')
+	t := task{
+		name:           "add-master",
+		playbook:       "kubernetes-master.yaml",
+		plan:           updatedPlan,
+		inventory:      inventory,
+		clusterCatalog: *cc,
+		explainer:      ae.defaultExplainer(),
+		limit:          []string{newMaster.Host},
+	}
+	if err = ae.execute(t); err != nil {
+		return nil, fmt.Errorf("error running playbook: %v", err)
+	}
+
+	// We need to run ansible against all hosts to update the hosts files
+	if err = ae.updateHostsFiles("add-master-update-hosts", updatedPlan, inventory, *cc); err != nil {
+		return nil, err
+	}
+
+	// The API server count is set on every master
+	util.PrintHeader(ae.stdout, "Reconfiguring Control Plane", '=')
+	t = task{
+		name:           "add-master-reconfigure-control-plane",
+		playbook:       "reconfigure-control-plane.yaml",
+		plan:           updatedPlan,
+		inventory:      inventory,
+		clusterCatalog: *cc,
+		explainer:      ae.defaultExplainer(),
+	}
+	if err = ae.execute(t); err != nil {
+		return nil, fmt.Errorf("error reconfiguring the control plane: %v", err)
+	}
+	return &updatedPlan, nil
+}
+
+func addMasterToPlan(plan Plan, master Node) Plan {
+	plan.Master.ExpectedCount++
+	plan.Master.Nodes = append(plan.Master.Nodes, master)
+	return plan
+}
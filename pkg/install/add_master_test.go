@@ -0,0 +1,128 @@
+package install
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/apprenda/kismatic/pkg/ansible"
+	"github.com/apprenda/kismatic/pkg/install/explain"
+)
+
+func TestAddMasterCertMissingCAMissing(t *testing.T) {
+	e := ansibleExecutor{
+		options:             ExecutorOptions{RestartServices: true, RunsDirectory: mustGetTempDir(t)},
+		stdout:              ioutil.Discard,
+		consoleOutputFormat: ansible.RawFormat,
+		pki:                 &fakePKI{},
+		certsDir:            mustGetTempDir(t),
+	}
+	originalPlan := &Plan{
+		Master: MasterNodeGroup{
+			Nodes: []Node{},
+		},
+	}
+	newPlan, err := e.AddMaster(originalPlan, Node{})
+	if newPlan != nil {
+		t.Errorf("add master returned an updated plan")
+	}
+	if err != errMissingClusterCA {
+		t.Errorf("AddMaster did not return the expected error. Instead returned: %v", err)
+	}
+}
+
+func TestAddMasterPlanIsUpdated(t *testing.T) {
+	fakeRunner := fakeRunner{}
+	pki := &fakePKI{
+		caExists: true,
+	}
+	e := ansibleExecutor{
+		options:             ExecutorOptions{RunsDirectory: mustGetTempDir(t)},
+		stdout:              ioutil.Discard,
+		consoleOutputFormat: ansible.RawFormat,
+		pki:                 pki,
+		runnerExplainerFactory: func(explain.AnsibleEventExplainer, io.Writer) (ansible.Runner, *explain.AnsibleEventStreamExplainer, error) {
+			return &fakeRunner, &explain.AnsibleEventStreamExplainer{}, nil
+		},
+		certsDir: mustGetTempDir(t),
+	}
+	originalPlan := &Plan{
+		Master: MasterNodeGroup{
+			ExpectedCount: 1,
+			Nodes:         []Node{{Host: "existingMaster", InternalIP: "10.10.2.20"}},
+		},
+		Cluster: Cluster{
+			Networking: NetworkConfig{
+				ServiceCIDRBlock: "10.0.0.0/16",
+			},
+		},
+	}
+	newMaster := Node{
+		Host: "test",
+	}
+	updatedPlan, err := e.AddMaster(originalPlan, newMaster)
+	if err != nil {
+		t.Fatalf("unexpected error while adding master: %v", err)
+	}
+	if !pki.generateNodeCertCalled {
+		t.Error("node certificate was not generated")
+	}
+	if updatedPlan.Master.ExpectedCount != 2 {
+		t.Errorf("expected count was not incremented")
+	}
+	found := false
+	for _, m := range updatedPlan.Master.Nodes {
+		if m.Equal(newMaster) {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("the updated plan does not include the new master")
+	}
+	// the original plan must not be modified
+	if originalPlan.Master.ExpectedCount != 1 || len(originalPlan.Master.Nodes) != 1 {
+		t.Errorf("the original plan was modified")
+	}
+	expectedPlaybook := "reconfigure-control-plane.yaml"
+	found = false
+	for _, p := range fakeRunner.allNodesPlaybooks {
+		if p == expectedPlaybook {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected playbook %s was not run during add-master. The following plays ran: %v", expectedPlaybook, fakeRunner.allNodesPlaybooks)
+	}
+}
+
+func TestAddMasterPlanNotUpdatedAfterFailure(t *testing.T) {
+	e := ansibleExecutor{
+		options:             ExecutorOptions{RunsDirectory: mustGetTempDir(t)},
+		stdout:              ioutil.Discard,
+		consoleOutputFormat: ansible.RawFormat,
+		pki: &fakePKI{
+			caExists: true,
+		},
+		runnerExplainerFactory: fakeRunnerExplainer(errors.New("exec error")),
+		certsDir:               mustGetTempDir(t),
+	}
+	originalPlan := &Plan{
+		Master: MasterNodeGroup{
+			ExpectedCount: 1,
+			Nodes:         []Node{{Host: "existingMaster", InternalIP: "10.10.2.20"}},
+		},
+		Cluster: Cluster{
+			Networking: NetworkConfig{
+				ServiceCIDRBlock: "10.0.0.0/16",
+			},
+		},
+	}
+	updatedPlan, err := e.AddMaster(originalPlan, Node{Host: "test"})
+	if err == nil {
+		t.Errorf("expected an error, but didn't get one")
+	}
+	if updatedPlan != nil {
+		t.Error("plan was updated, even though adding master failed")
+	}
+}
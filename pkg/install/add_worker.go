@@ -4,23 +4,24 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/apprenda/kismatic/pkg/ansible"
 	"github.com/apprenda/kismatic/pkg/util"
 )
 
 var errMissingClusterCA = errors.New("The Certificate Authority's private key and certificate used to install " +
-	"the cluster are required for adding worker nodes.")
+	"the cluster are required for adding nodes.")
 
 // AddWorker adds a worker node to the original cluster described in the plan.
 // If successful, the updated plan is returned.
 func (ae *ansibleExecutor) AddWorker(originalPlan *Plan, newWorker Node) (*Plan, error) {
-	if err := checkAddWorkerPrereqs(ae.pki, newWorker); err != nil {
+	if err := checkAddNodePrereqs(ae.pki, originalPlan, newWorker); err != nil {
 		return nil, err
 	}
 	updatedPlan := addWorkerToPlan(*originalPlan, newWorker)
 
 	// Generate node certificates
 	util.PrintHeader(ae.stdout, "Generating Certificate For Worker Node", '=')
-	ca, err := ae.pki.GetClusterCA()
+	ca, err := ae.pki.GetClusterCA(&updatedPlan)
 	if err != nil {
 		return nil, err
 	}
@@ -29,7 +30,7 @@ func (ae *ansibleExecutor) AddWorker(originalPlan *Plan, newWorker Node) (*Plan,
 	}
 
 	// Run the playbook to add the worker
-	inventory := buildInventoryFromPlan(&updatedPlan)
+	inventory := buildInventoryFromPlan(&updatedPlan, ae.options.GeneratedAssetsDirectory)
 	cc, err := ae.buildClusterCatalog(&updatedPlan)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate ansible vars: %v", err)
@@ -49,19 +50,8 @@ func (ae *ansibleExecutor) AddWorker(originalPlan *Plan, newWorker Node) (*Plan,
 	}
 
 	// We need to run ansible against all hosts to update the hosts files
-	if updatedPlan.Cluster.Networking.UpdateHostsFiles {
-		util.PrintHeader(ae.stdout, "Updating Hosts Files On All Nodes", '=')
-		t = task{
-			name:           "add-worker-update-hosts",
-			playbook:       "_hosts.yaml",
-			plan:           updatedPlan,
-			inventory:      inventory,
-			clusterCatalog: *cc,
-			explainer:      ae.defaultExplainer(),
-		}
-		if err = ae.execute(t); err != nil {
-			return nil, fmt.Errorf("error updating hosts files on all nodes: %v", err)
-		}
+	if err = ae.updateHostsFiles("add-worker-update-hosts", updatedPlan, inventory, *cc); err != nil {
+		return nil, err
 	}
 
 	// Verify that the node registered with API server
@@ -105,16 +95,16 @@ func addWorkerToPlan(plan Plan, worker Node) Plan {
 }
 
 // ensure the assumptions we are making are solid
-func checkAddWorkerPrereqs(pki PKI, newWorker Node) error {
+func checkAddNodePrereqs(pki PKI, plan *Plan, newNode Node) error {
 	// 1. if the node certificate is not there, we need to ensure that
-	// the CA is available for generating the new worker's cert
+	// the CA is available for generating the new node's cert
 	// don't check for a valid cert here since its already being done in GenerateNodeCertificate()
-	certExists, err := pki.NodeCertificateExists(newWorker)
+	certExists, err := pki.NodeCertificateExists(newNode)
 	if err != nil {
 		return fmt.Errorf("error while checking if node's certificate exists: %v", err)
 	}
 	if !certExists {
-		caExists, err := pki.CertificateAuthorityExists()
+		caExists, err := pki.CertificateAuthorityExists(plan)
 		if err != nil {
 			return fmt.Errorf("error while checking if cluster CA exists: %v", err)
 		}
@@ -124,3 +114,24 @@ func checkAddWorkerPrereqs(pki PKI, newWorker Node) error {
 	}
 	return nil
 }
+
+// updateHostsFiles runs ansible against all hosts to update the hosts files,
+// if the plan requires it
+func (ae *ansibleExecutor) updateHostsFiles(name string, p Plan, inventory ansible.Inventory, cc ansible.ClusterCatalog) error {
+	if !p.Cluster.Networking.UpdateHostsFiles {
+		return nil
+	}
+	util.PrintHeader(ae.stdout, "Updating Hosts Files On All Nodes", '=')
+	t := task{
+		name:           name,
+		playbook:       "_hosts.yaml",
+		plan:           p,
+		inventory:      inventory,
+		clusterCatalog: cc,
+		explainer:      ae.defaultExplainer(),
+	}
+	if err := ae.execute(t); err != nil {
+		return fmt.Errorf("error updating hosts files on all nodes: %v", err)
+	}
+	return nil
+}
@@ -288,29 +288,40 @@ type fakePKI struct {
 	err                    error
 	generateCACalled       bool
 	generateNodeCertCalled bool
+	archiveNodeCertsCalled bool
+	rotateCertsCalled      bool
 }
 
-func (f *fakePKI) CertificateAuthorityExists() (bool, error)     { return f.caExists, f.err }
-func (f *fakePKI) NodeCertificateExists(node Node) (bool, error) { return f.nodeCertExists, f.err }
+func (f *fakePKI) CertificateAuthorityExists(p *Plan) (bool, error) { return f.caExists, f.err }
+func (f *fakePKI) NodeCertificateExists(node Node) (bool, error)    { return f.nodeCertExists, f.err }
 func (f *fakePKI) GenerateNodeCertificate(plan *Plan, node Node, ca *tls.CA) error {
 	f.generateNodeCertCalled = true
 	return f.err
 }
-func (f *fakePKI) GetClusterCA() (*tls.CA, error) { return nil, f.err }
+func (f *fakePKI) GetClusterCA(p *Plan) (*tls.CA, error) { return nil, f.err }
 func (f *fakePKI) GenerateClusterCA(p *Plan) (*tls.CA, error) {
 	f.generateCACalled = true
 	return nil, f.err
 }
 func (f *fakePKI) GenerateClusterCertificates(p *Plan, ca *tls.CA) error { return f.err }
-func (f *fakePKI) GenerateCertificate(name string, validityPeriod string, commonName string, subjectAlternateNames []string, organizations []string, ca *tls.CA, overwrite bool) (bool, error) {
+func (f *fakePKI) GenerateCertificate(plan *Plan, name string, validityPeriod string, commonName string, subjectAlternateNames []string, organizations []string, ca *tls.CA, overwrite bool) (bool, error) {
 	return false, f.err
 }
+func (f *fakePKI) ArchiveNodeCertificates(plan *Plan, node Node) (string, error) {
+	f.archiveNodeCertsCalled = true
+	return "", f.err
+}
+func (f *fakePKI) RotateClusterCertificates(plan *Plan, ca *tls.CA) (string, error) {
+	f.rotateCertsCalled = true
+	return "", f.err
+}
 
 type fakeRunner struct {
 	eventChan         chan ansible.Event
 	err               error
 	incomingCatalog   ansible.ClusterCatalog
 	allNodesPlaybooks []string
+	limitedNodes      [][]string
 }
 
 func (f *fakeRunner) StartPlaybook(playbookFile string, inventory ansible.Inventory, cc ansible.ClusterCatalog) (<-chan ansible.Event, error) {
@@ -320,6 +331,7 @@ func (f *fakeRunner) StartPlaybook(playbookFile string, inventory ansible.Invent
 func (f *fakeRunner) WaitPlaybook() error { return f.err }
 func (f *fakeRunner) StartPlaybookOnNode(playbookFile string, inventory ansible.Inventory, cc ansible.ClusterCatalog, node ...string) (<-chan ansible.Event, error) {
 	f.incomingCatalog = cc
+	f.limitedNodes = append(f.limitedNodes, node)
 	return f.eventChan, f.err
 }
 
@@ -0,0 +1,112 @@
+package install
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/apprenda/kismatic/pkg/util"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	defaultAuditLogPath      = "/var/log/kubernetes/audit.log"
+	auditWebhookModeBatch    = "batch"
+	auditWebhookModeBlocking = "blocking"
+	auditPolicyFilename      = "audit-policy.yaml"
+)
+
+func auditWebhookModes() []string {
+	return []string{auditWebhookModeBatch, auditWebhookModeBlocking}
+}
+
+func auditLevels() []string {
+	return []string{"None", "Metadata", "Request", "RequestResponse"}
+}
+
+func (a *AuditConfig) validate() (bool, []error) {
+	v := newValidator()
+	if len(a.PolicyRules) == 0 && a.PolicyFile == "" {
+		v.addError(errors.New("Audit policy_rules or policy_file is required"))
+	}
+	if len(a.PolicyRules) > 0 && a.PolicyFile != "" {
+		v.addError(errors.New("Audit policy_rules and policy_file cannot be used together"))
+	}
+	for i, r := range a.PolicyRules {
+		level, _ := mapSliceValue(r, "level").(string)
+		if !util.Contains(level, auditLevels()) {
+			v.addError(fmt.Errorf("Audit policy rule %d has an invalid level %q. Options are %v", i+1, level, auditLevels()))
+		}
+	}
+	if a.PolicyFile != "" {
+		if !filepath.IsAbs(a.PolicyFile) {
+			v.addError(errors.New("Audit policy_file must be an absolute path"))
+		}
+		if _, err := os.Stat(a.PolicyFile); os.IsNotExist(err) {
+			v.addError(fmt.Errorf("Audit policy file was not found at %q", a.PolicyFile))
+		}
+	}
+	if a.LogPath != "" && !filepath.IsAbs(a.LogPath) {
+		v.addError(errors.New("Audit log_path must be an absolute path"))
+	}
+	if a.LogMaxAge != nil && *a.LogMaxAge < 0 {
+		v.addError(errors.New("Audit log_max_age must be greater or equal to 0"))
+	}
+	if a.LogMaxBackups != nil && *a.LogMaxBackups < 0 {
+		v.addError(errors.New("Audit log_max_backups must be greater or equal to 0"))
+	}
+	if a.Webhook != nil {
+		v.validate(a.Webhook)
+	}
+	return v.valid()
+}
+
+func (w *AuditWebhook) validate() (bool, []error) {
+	v := newValidator()
+	if w.ConfigFile == "" {
+		v.addError(errors.New("Audit webhook config_file is required"))
+	} else {
+		if !filepath.IsAbs(w.ConfigFile) {
+			v.addError(errors.New("Audit webhook config_file must be an absolute path"))
+		}
+		if _, err := os.Stat(w.ConfigFile); os.IsNotExist(err) {
+			v.addError(fmt.Errorf("Audit webhook config file was not found at %q", w.ConfigFile))
+		}
+	}
+	if w.Mode != "" && !util.Contains(w.Mode, auditWebhookModes()) {
+		v.addError(fmt.Errorf("%q is not a valid audit webhook mode. Options are %v", w.Mode, auditWebhookModes()))
+	}
+	return v.valid()
+}
+
+// auditPolicyFile returns the path to the audit policy file that should be
+// copied to the master nodes. When the policy is defined with rules, the
+// policy file is written to the generated assets directory, unless write is
+// false.
+func auditPolicyFile(a AuditConfig, generatedAssetsDir string, write bool) (string, error) {
+	if a.PolicyFile != "" {
+		return a.PolicyFile, nil
+	}
+	file, err := filepath.Abs(filepath.Join(generatedAssetsDir, auditPolicyFilename))
+	if err != nil {
+		return "", fmt.Errorf("failed to determine absolute path to %s: %v", auditPolicyFilename, err)
+	}
+	if !write {
+		return file, nil
+	}
+	policy := yaml.MapSlice{
+		{Key: "apiVersion", Value: "audit.k8s.io/v1beta1"},
+		{Key: "kind", Value: "Policy"},
+		{Key: "rules", Value: a.PolicyRules},
+	}
+	b, err := yaml.Marshal(policy)
+	if err != nil {
+		return "", fmt.Errorf("error marshalling audit policy: %v", err)
+	}
+	if err := ioutil.WriteFile(file, b, 0644); err != nil {
+		return "", fmt.Errorf("error writing audit policy file: %v", err)
+	}
+	return file, nil
+}
@@ -0,0 +1,244 @@
+package install
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+func TestValidateAuditConfig(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "test-validate-audit")
+	if err != nil {
+		t.Fatalf("error creating tmp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	policyFile := filepath.Join(tmpDir, "policy.yaml")
+	if err := ioutil.WriteFile(policyFile, []byte("kind: Policy"), 0644); err != nil {
+		t.Fatalf("error writing policy file: %v", err)
+	}
+	rules := []yaml.MapSlice{{{Key: "level", Value: "Metadata"}}}
+	zero, negative := 0, -1
+
+	tests := []struct {
+		name  string
+		audit AuditConfig
+		valid bool
+	}{
+		{
+			name:  "policy rules",
+			audit: AuditConfig{PolicyRules: rules, LogPath: defaultAuditLogPath},
+			valid: true,
+		},
+		{
+			name:  "policy file",
+			audit: AuditConfig{PolicyFile: policyFile},
+			valid: true,
+		},
+		{
+			name:  "no policy",
+			audit: AuditConfig{},
+		},
+		{
+			name:  "policy rules and file",
+			audit: AuditConfig{PolicyRules: rules, PolicyFile: policyFile},
+		},
+		{
+			name:  "rule without level",
+			audit: AuditConfig{PolicyRules: []yaml.MapSlice{{{Key: "resources", Value: []interface{}{}}}}},
+		},
+		{
+			name:  "rule with invalid level",
+			audit: AuditConfig{PolicyRules: []yaml.MapSlice{{{Key: "level", Value: "Everything"}}}},
+		},
+		{
+			name:  "missing policy file",
+			audit: AuditConfig{PolicyFile: filepath.Join(tmpDir, "missing.yaml")},
+		},
+		{
+			name:  "relative log path",
+			audit: AuditConfig{PolicyRules: rules, LogPath: "audit.log"},
+		},
+		{
+			name:  "zero max age and max backups",
+			audit: AuditConfig{PolicyRules: rules, LogMaxAge: &zero, LogMaxBackups: &zero},
+			valid: true,
+		},
+		{
+			name:  "negative max age",
+			audit: AuditConfig{PolicyRules: rules, LogMaxAge: &negative},
+		},
+		{
+			name:  "negative max backups",
+			audit: AuditConfig{PolicyRules: rules, LogMaxBackups: &negative},
+		},
+		{
+			name:  "webhook",
+			audit: AuditConfig{PolicyRules: rules, Webhook: &AuditWebhook{ConfigFile: policyFile, Mode: "blocking"}},
+			valid: true,
+		},
+		{
+			name:  "webhook without config file",
+			audit: AuditConfig{PolicyRules: rules, Webhook: &AuditWebhook{}},
+		},
+		{
+			name:  "webhook with invalid mode",
+			audit: AuditConfig{PolicyRules: rules, Webhook: &AuditWebhook{ConfigFile: policyFile, Mode: "async"}},
+		},
+	}
+	for _, test := range tests {
+		ok, errs := test.audit.validate()
+		if ok != test.valid {
+			t.Errorf("%s: expected valid to be %t, but got %t: %v", test.name, test.valid, ok, errs)
+		}
+	}
+}
+
+func TestValidateAuditOptionOverrides(t *testing.T) {
+	p := getPlan()
+	p.Cluster.APIServerOptions.Overrides = map[string]string{
+		"audit-log-path": "/tmp/audit.log",
+		"v":              "3",
+	}
+	p.Cluster.APIServerOptions.Audit = &AuditConfig{PolicyRules: []yaml.MapSlice{{{Key: "level", Value: "Metadata"}}}}
+	ok, errs := p.Cluster.validate()
+	if ok {
+		t.Fatal("expected audit option overrides to be invalid when audit is configured")
+	}
+	found := false
+	for _, err := range errs {
+		if strings.Contains(err.Error(), "audit-log-path") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected an error about the audit-log-path override, but got: %v", errs)
+	}
+
+	// Audit overrides are allowed when audit is not configured
+	p.Cluster.APIServerOptions.Audit = nil
+	_, errs = p.Cluster.validate()
+	for _, err := range errs {
+		if strings.Contains(err.Error(), "audit-log-path") {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+}
+
+func TestAuditPolicyFileFromRules(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "test-audit-policy")
+	if err != nil {
+		t.Fatalf("error creating tmp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	audit := AuditConfig{
+		PolicyRules: []yaml.MapSlice{
+			{{Key: "level", Value: "None"}, {Key: "users", Value: []interface{}{"system:kube-proxy"}}},
+			{{Key: "level", Value: "Metadata"}},
+		},
+	}
+	// The policy file is not written during a dry run
+	if _, err := auditPolicyFile(audit, tmpDir, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, auditPolicyFilename)); !os.IsNotExist(err) {
+		t.Error("expected the policy file not to be written")
+	}
+
+	file, err := auditPolicyFile(audit, tmpDir, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if file != filepath.Join(tmpDir, auditPolicyFilename) {
+		t.Errorf("expected policy file in %s, but got %s", tmpDir, file)
+	}
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatalf("error reading policy file: %v", err)
+	}
+	expected := `apiVersion: audit.k8s.io/v1beta1
+kind: Policy
+rules:
+- level: None
+  users:
+  - system:kube-proxy
+- level: Metadata
+`
+	if string(b) != expected {
+		t.Errorf("expected policy:\n%s\nbut got:\n%s", expected, b)
+	}
+
+	// The policy file of the plan is used as is
+	audit = AuditConfig{PolicyFile: "/etc/audit/policy.yaml"}
+	if file, err := auditPolicyFile(audit, tmpDir, true); err != nil || file != audit.PolicyFile {
+		t.Errorf("expected policy file %s, but got %s (%v)", audit.PolicyFile, file, err)
+	}
+}
+
+func TestAuditDefaults(t *testing.T) {
+	p := &Plan{}
+	p.Cluster.APIServerOptions.Audit = &AuditConfig{Webhook: &AuditWebhook{}}
+	setDefaults(p)
+	audit := p.Cluster.APIServerOptions.Audit
+	if audit.LogPath != defaultAuditLogPath {
+		t.Errorf("expected log path %s, but got %s", defaultAuditLogPath, audit.LogPath)
+	}
+	if audit.LogMaxAge == nil || *audit.LogMaxAge != 30 || audit.LogMaxBackups == nil || *audit.LogMaxBackups != 10 {
+		t.Errorf("expected log max age 30 and max backups 10, but got %v and %v", audit.LogMaxAge, audit.LogMaxBackups)
+	}
+	if audit.Webhook.Mode != auditWebhookModeBatch {
+		t.Errorf("expected webhook mode %s, but got %s", auditWebhookModeBatch, audit.Webhook.Mode)
+	}
+
+	// An explicit 0 is not replaced with the default
+	zero := 0
+	p.Cluster.APIServerOptions.Audit = &AuditConfig{LogMaxAge: &zero, LogMaxBackups: &zero}
+	setDefaults(p)
+	audit = p.Cluster.APIServerOptions.Audit
+	if *audit.LogMaxAge != 0 || *audit.LogMaxBackups != 0 {
+		t.Errorf("expected log max age and max backups to remain 0, but got %d and %d", *audit.LogMaxAge, *audit.LogMaxBackups)
+	}
+}
+
+func TestReadAuditPolicyRules(t *testing.T) {
+	planStr := `cluster:
+  kube_apiserver:
+    audit:
+      policy_rules:
+      - level: RequestResponse
+        resources:
+        - group: ""
+          resources: ["pods"]
+      - level: Metadata
+`
+	p := &Plan{}
+	if err := yaml.Unmarshal([]byte(planStr), p); err != nil {
+		t.Fatalf("error unmarshalling plan: %v", err)
+	}
+	audit := p.Cluster.APIServerOptions.Audit
+	if audit == nil || len(audit.PolicyRules) != 2 {
+		t.Fatalf("expected two policy rules, but got %v", audit)
+	}
+	if ok, errs := audit.validate(); !ok {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+	tmpDir, err := ioutil.TempDir("", "test-read-audit-policy")
+	if err != nil {
+		t.Fatalf("error creating tmp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	file, err := auditPolicyFile(*audit, tmpDir, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatalf("error reading policy file: %v", err)
+	}
+	if !strings.Contains(string(b), "- level: RequestResponse\n  resources:\n  - group: \"\"\n    resources:\n    - pods\n") {
+		t.Errorf("unexpected policy file:\n%s", b)
+	}
+}
@@ -0,0 +1,171 @@
+package install
+
+import (
+	"crypto/x509"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/apprenda/kismatic/pkg/tls"
+	"github.com/cloudflare/cfssl/helpers"
+)
+
+const (
+	kubernetesCertificatesDir = "/etc/kubernetes/pki"
+	etcdK8sCertificatesDir    = "/etc/etcd_k8s"
+	etcdNetworkingCertsDir    = "/etc/etcd_networking"
+)
+
+// CertificateStatus contains information about a certificate of the cluster,
+// found in the generated keys directory or deployed on a node
+type CertificateStatus struct {
+	// Node on which the certificate is deployed. Empty for the certificates
+	// in the generated keys directory.
+	Node                  string
+	File                  string
+	CommonName            string
+	SubjectAlternateNames []string
+	Organizations         []string
+	Issuer                string
+	Expiry                time.Time
+	// Expiring is true if the certificate expires within the window
+	Expiring bool
+	// Mismatch is true if the certificate deployed on the node is not the
+	// certificate found in the generated keys directory
+	Mismatch bool
+	// Error is set when the certificate could not be read
+	Error string
+}
+
+// NeedsAttention returns true if the certificate could not be read, is about
+// to expire, or does not match the certificate in the generated keys directory
+func (s CertificateStatus) NeedsAttention() bool {
+	return s.Error != "" || s.Expiring || s.Mismatch
+}
+
+// deployedCertificate is a certificate deployed on a node
+type deployedCertificate struct {
+	// path of the certificate on the node
+	path string
+	// name of the certificate in the generated keys directory
+	name string
+}
+
+// LocalCertificatesStatus returns the status of the certificates of the cluster
+// found in the generated keys directory. Certificates that expire within the
+// window are flagged as expiring.
+func LocalCertificatesStatus(plan *Plan, certsDir string, window time.Duration) ([]CertificateStatus, error) {
+	manifest, err := certManifestForCluster(*plan)
+	if err != nil {
+		return nil, err
+	}
+	names := []string{"ca"}
+	for _, s := range manifest {
+		if !contains(s.filename, names) {
+			names = append(names, s.filename)
+		}
+	}
+	statuses := []CertificateStatus{}
+	for _, name := range names {
+		s := CertificateStatus{File: filepath.Join(certsDir, name+".pem")}
+		cert, err := tls.ReadCert(name, certsDir)
+		if err != nil {
+			s.Error = fmt.Sprintf("error reading certificate: %v", err)
+		} else {
+			s.describe(cert, window)
+		}
+		statuses = append(statuses, s)
+	}
+	return statuses, nil
+}
+
+// NodeCertificatesStatus connects to the nodes of the cluster via SSH, and returns
+// the status of the certificates deployed on them. Certificates that expire within
+// the window, or that are not the same as the certificate in the generated keys
+// directory are flagged.
+func NodeCertificatesStatus(plan *Plan, generatedAssetsDir string, window time.Duration) ([]CertificateStatus, error) {
+	certsDir := filepath.Join(generatedAssetsDir, "keys")
+	statuses := []CertificateStatus{}
+	for _, node := range plan.GetUniqueNodes() {
+		client, err := plan.Cluster.SSH.forNode(node).newClient(node, generatedAssetsDir)
+		if err != nil {
+			return nil, fmt.Errorf("error creating SSH client for node %q: %v", node.Host, err)
+		}
+		for _, d := range deployedCertificates(*plan, node) {
+			s := CertificateStatus{Node: node.Host, File: d.path}
+			out, err := client.Output(true, fmt.Sprintf("sudo cat %s", d.path))
+			if err != nil {
+				s.Error = fmt.Sprintf("error reading certificate: %s", strings.TrimSpace(out))
+				statuses = append(statuses, s)
+				continue
+			}
+			certs, err := helpers.ParseCertificatesPEM([]byte(strings.TrimSpace(out)))
+			if err != nil {
+				s.Error = fmt.Sprintf("error parsing certificate: %v", err)
+				statuses = append(statuses, s)
+				continue
+			}
+			if len(certs) == 0 {
+				s.Error = "error parsing certificate: no certificate found"
+				statuses = append(statuses, s)
+				continue
+			}
+			cert := certs[0]
+			s.describe(cert, window)
+			local, err := tls.ReadCert(d.name, certsDir)
+			s.Mismatch = err != nil || !local.Equal(cert)
+			statuses = append(statuses, s)
+		}
+	}
+	return statuses, nil
+}
+
+func (s *CertificateStatus) describe(cert *x509.Certificate, window time.Duration) {
+	s.CommonName = cert.Subject.CommonName
+	s.Organizations = cert.Subject.Organization
+	s.SubjectAlternateNames = append([]string{}, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		s.SubjectAlternateNames = append(s.SubjectAlternateNames, ip.String())
+	}
+	s.Issuer = cert.Issuer.CommonName
+	s.Expiry = cert.NotAfter
+	s.Expiring = time.Now().Add(window).After(cert.NotAfter)
+}
+
+// deployedCertificates returns the certificates that are deployed on the node,
+// according to its roles
+func deployedCertificates(plan Plan, node Node) []deployedCertificate {
+	roles := plan.GetRolesForIP(node.IP)
+	certs := []deployedCertificate{}
+	if contains("etcd", roles) {
+		dirs := []string{etcdK8sCertificatesDir}
+		if plan.AddOns.CNI != nil && !plan.AddOns.CNI.Disable && plan.AddOns.CNI.Provider == cniProviderCalico {
+			dirs = append(dirs, etcdNetworkingCertsDir)
+		}
+		for _, dir := range dirs {
+			certs = append(certs,
+				deployedCertificate{path: dir + "/ca.pem", name: "ca"},
+				deployedCertificate{path: dir + "/etcd.pem", name: fmt.Sprintf("%s-etcd", node.Host)},
+				deployedCertificate{path: dir + "/etcd-client.pem", name: "etcd-client"},
+			)
+		}
+	}
+	if containsAny([]string{"master", "worker", "ingress", "storage"}, roles) {
+		certs = append(certs,
+			deployedCertificate{path: kubernetesCertificatesDir + "/ca.pem", name: "ca"},
+			deployedCertificate{path: kubernetesCertificatesDir + "/kubelet.pem", name: fmt.Sprintf("%s-kubelet", node.Host)},
+			deployedCertificate{path: kubernetesCertificatesDir + "/kube-proxy.pem", name: kubeProxyCertFilenamePrefix},
+			deployedCertificate{path: kubernetesCertificatesDir + "/etcd-client.pem", name: "etcd-client"},
+		)
+	}
+	if contains("master", roles) {
+		certs = append(certs,
+			deployedCertificate{path: kubernetesCertificatesDir + "/api-server.pem", name: fmt.Sprintf("%s-apiserver", node.Host)},
+			deployedCertificate{path: kubernetesCertificatesDir + "/controller-manager.pem", name: controllerManagerCertFilenamePrefix},
+			deployedCertificate{path: kubernetesCertificatesDir + "/scheduler.pem", name: schedulerCertFilenamePrefix},
+			deployedCertificate{path: kubernetesCertificatesDir + "/service-account.pem", name: serviceAccountCertFilename},
+		)
+	}
+	return certs
+}
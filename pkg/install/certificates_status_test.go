@@ -0,0 +1,123 @@
+package install
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLocalCertificatesStatus(t *testing.T) {
+	pki := getPKI(t)
+	defer cleanup(pki.GeneratedCertsDirectory, t)
+
+	p := getPlan()
+	ca, err := pki.GenerateClusterCA(p)
+	if err != nil {
+		t.Fatalf("error generating CA for test: %v", err)
+	}
+	if err = pki.GenerateClusterCertificates(p, ca); err != nil {
+		t.Fatalf("error generating cluster certificates: %v", err)
+	}
+
+	statuses, err := LocalCertificatesStatus(p, pki.GeneratedCertsDirectory, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(statuses) == 0 {
+		t.Fatalf("expected certificate statuses, but got none")
+	}
+	for _, s := range statuses {
+		if s.NeedsAttention() {
+			t.Errorf("certificate %q unexpectedly needs attention: %+v", s.File, s)
+		}
+		if s.Node != "" {
+			t.Errorf("expected no node for local certificate %q, but got %q", s.File, s.Node)
+		}
+	}
+	if statuses[0].CommonName != p.Cluster.Name {
+		t.Errorf("expected the CA to be listed first, but got %q", statuses[0].CommonName)
+	}
+
+	// The cluster certificates expire in 1h
+	statuses, err = LocalCertificatesStatus(p, pki.GeneratedCertsDirectory, 2*time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, s := range statuses[1:] {
+		if !s.Expiring {
+			t.Errorf("expected certificate %q to be flagged as expiring", s.File)
+		}
+	}
+}
+
+func TestLocalCertificatesStatusMissingCertificate(t *testing.T) {
+	pki := getPKI(t)
+	defer cleanup(pki.GeneratedCertsDirectory, t)
+
+	statuses, err := LocalCertificatesStatus(getPlan(), pki.GeneratedCertsDirectory, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, s := range statuses {
+		if s.Error == "" {
+			t.Errorf("expected an error for missing certificate %q", s.File)
+		}
+	}
+}
+
+func TestDeployedCertificates(t *testing.T) {
+	p := Plan{
+		AddOns: AddOns{CNI: &CNI{Provider: cniProviderCalico}},
+		Etcd:   NodeGroup{Nodes: []Node{{Host: "etcd01", IP: "10.0.0.1"}}},
+		Master: MasterNodeGroup{Nodes: []Node{{Host: "master01", IP: "10.0.0.2"}}},
+		Worker: NodeGroup{Nodes: []Node{{Host: "worker01", IP: "10.0.0.3"}}},
+	}
+	tests := []struct {
+		node     Node
+		expected map[string]string
+	}{
+		{
+			node: p.Etcd.Nodes[0],
+			expected: map[string]string{
+				"/etc/etcd_k8s/ca.pem":                 "ca",
+				"/etc/etcd_k8s/etcd.pem":               "etcd01-etcd",
+				"/etc/etcd_k8s/etcd-client.pem":        "etcd-client",
+				"/etc/etcd_networking/ca.pem":          "ca",
+				"/etc/etcd_networking/etcd.pem":        "etcd01-etcd",
+				"/etc/etcd_networking/etcd-client.pem": "etcd-client",
+			},
+		},
+		{
+			node: p.Master.Nodes[0],
+			expected: map[string]string{
+				"/etc/kubernetes/pki/ca.pem":                 "ca",
+				"/etc/kubernetes/pki/kubelet.pem":            "master01-kubelet",
+				"/etc/kubernetes/pki/kube-proxy.pem":         "kube-proxy",
+				"/etc/kubernetes/pki/etcd-client.pem":        "etcd-client",
+				"/etc/kubernetes/pki/api-server.pem":         "master01-apiserver",
+				"/etc/kubernetes/pki/controller-manager.pem": "kube-controller-manager",
+				"/etc/kubernetes/pki/scheduler.pem":          "kube-scheduler",
+				"/etc/kubernetes/pki/service-account.pem":    "service-account",
+			},
+		},
+		{
+			node: p.Worker.Nodes[0],
+			expected: map[string]string{
+				"/etc/kubernetes/pki/ca.pem":          "ca",
+				"/etc/kubernetes/pki/kubelet.pem":     "worker01-kubelet",
+				"/etc/kubernetes/pki/kube-proxy.pem":  "kube-proxy",
+				"/etc/kubernetes/pki/etcd-client.pem": "etcd-client",
+			},
+		},
+	}
+	for _, test := range tests {
+		certs := deployedCertificates(p, test.node)
+		if len(certs) != len(test.expected) {
+			t.Errorf("node %q: expected %d certificates, but got %d: %v", test.node.Host, len(test.expected), len(certs), certs)
+		}
+		for _, c := range certs {
+			if name, ok := test.expected[c.path]; !ok || name != c.name {
+				t.Errorf("node %q: unexpected certificate %q with name %q", test.node.Host, c.path, c.name)
+			}
+		}
+	}
+}
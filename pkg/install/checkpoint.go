@@ -0,0 +1,292 @@
+package install
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/apprenda/kismatic/pkg/ansible"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	checkpointFilename = "checkpoint.json"
+
+	playStarted   = "started"
+	playCompleted = "completed"
+	playFailed    = "failed"
+
+	hostOK          = "ok"
+	hostFailed      = "failed"
+	hostUnreachable = "unreachable"
+)
+
+var errNothingToResume = errors.New("the previous run completed successfully, there is nothing to resume")
+
+// checkpoint records the progress of a playbook run
+type checkpoint struct {
+	// Playbook is the playbook that is being run. When resuming a previous
+	// run, this is the playbook of the original run.
+	Playbook string `json:"playbook"`
+	// PlanHash is the hash of the plan that was used for the run
+	PlanHash string `json:"planHash"`
+	// FirstPlay is the number of plays of the playbook that were skipped
+	// because they had completed in a previous run
+	FirstPlay int `json:"firstPlay"`
+	// Plays that started during the run, in order
+	Plays []playCheckpoint `json:"plays"`
+	// Complete is true when the playbook ran to completion
+	Complete bool `json:"complete"`
+}
+
+// playCheckpoint records the status of a single play, and the status of each host in the play
+type playCheckpoint struct {
+	Name   string            `json:"name"`
+	Status string            `json:"status"`
+	Hosts  map[string]string `json:"hosts,omitempty"`
+}
+
+// resumePoint is the position in a playbook from which a run is resumed
+type resumePoint struct {
+	playbook  string
+	firstPlay int
+}
+
+// nextPlay returns the index of the first play in the playbook that did not complete
+func (cp checkpoint) nextPlay() int {
+	for i, p := range cp.Plays {
+		if p.Status != playCompleted {
+			return cp.FirstPlay + i
+		}
+	}
+	return cp.FirstPlay + len(cp.Plays)
+}
+
+// checkpointRecorder keeps the checkpoint file in the run directory up to
+// date with the events coming out of the ansible event stream
+type checkpointRecorder struct {
+	sync.Mutex
+	file string
+	cp   checkpoint
+}
+
+// record consumes the events, and forwards them on the returned channel
+func (r *checkpointRecorder) record(in <-chan ansible.Event) <-chan ansible.Event {
+	out := make(chan ansible.Event)
+	go func() {
+		for e := range in {
+			r.Lock()
+			if r.update(e) {
+				r.write()
+			}
+			r.Unlock()
+			out <- e
+		}
+		close(out)
+	}()
+	return out
+}
+
+// update the checkpoint with the event. Returns true if the checkpoint changed.
+func (r *checkpointRecorder) update(e ansible.Event) bool {
+	switch event := e.(type) {
+	case *ansible.PlayStartEvent:
+		r.endPlay()
+		r.cp.Plays = append(r.cp.Plays, playCheckpoint{Name: event.Name, Status: playStarted, Hosts: map[string]string{}})
+		return true
+	case *ansible.PlaybookEndEvent:
+		r.endPlay()
+		return true
+	case *ansible.RunnerOKEvent:
+		return r.setHostStatus(event.Host, hostOK)
+	case *ansible.RunnerFailedEvent:
+		if event.IgnoreErrors {
+			return false
+		}
+		return r.setHostStatus(event.Host, hostFailed)
+	case *ansible.RunnerItemFailedEvent:
+		if event.IgnoreErrors {
+			return false
+		}
+		return r.setHostStatus(event.Host, hostFailed)
+	case *ansible.RunnerUnreachableEvent:
+		return r.setHostStatus(event.Host, hostUnreachable)
+	}
+	return false
+}
+
+// a host that has failed in the current play is never marked as ok again
+func (r *checkpointRecorder) setHostStatus(host, status string) bool {
+	if len(r.cp.Plays) == 0 {
+		return false
+	}
+	play := &r.cp.Plays[len(r.cp.Plays)-1]
+	current, ok := play.Hosts[host]
+	if ok && (current == status || current != hostOK) {
+		return false
+	}
+	play.Hosts[host] = status
+	return true
+}
+
+// endPlay sets the final status of the play that is currently running
+func (r *checkpointRecorder) endPlay() {
+	if len(r.cp.Plays) == 0 {
+		return
+	}
+	play := &r.cp.Plays[len(r.cp.Plays)-1]
+	if play.Status != playStarted {
+		return
+	}
+	play.Status = playCompleted
+	for _, s := range play.Hosts {
+		if s != hostOK {
+			play.Status = playFailed
+		}
+	}
+}
+
+// finish records the outcome of the playbook run, once ansible has exited
+func (r *checkpointRecorder) finish(runErr error) error {
+	r.Lock()
+	defer r.Unlock()
+	if runErr == nil {
+		r.endPlay()
+		r.cp.Complete = true
+	} else if len(r.cp.Plays) > 0 && r.cp.Plays[len(r.cp.Plays)-1].Status == playStarted {
+		// ansible exited while the play was running
+		r.cp.Plays[len(r.cp.Plays)-1].Status = playFailed
+	}
+	return r.write()
+}
+
+func (r *checkpointRecorder) write() error {
+	b, err := json.MarshalIndent(r.cp, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling checkpoint: %v", err)
+	}
+	if err := ioutil.WriteFile(r.file, b, 0644); err != nil {
+		return fmt.Errorf("error writing checkpoint file %q: %v", r.file, err)
+	}
+	return nil
+}
+
+// planHash returns a hash of the plan that is used to detect changes between runs
+func planHash(p Plan) (string, error) {
+	b, err := yaml.Marshal(p)
+	if err != nil {
+		return "", fmt.Errorf("error marshalling plan to yaml: %v", err)
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(b)), nil
+}
+
+// lastCheckpoint returns the checkpoint of the most recent run of the given task
+func lastCheckpoint(runsDir, taskName string) (*checkpoint, error) {
+	dir := filepath.Join(runsDir, taskName)
+	files, err := ioutil.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("error listing runs in %q: %v", dir, err)
+	}
+	runs := []string{}
+	for _, f := range files {
+		if f.IsDir() {
+			runs = append(runs, f.Name())
+		}
+	}
+	// run directories are named after their start time, so the most recent sorts last
+	sort.Sort(sort.Reverse(sort.StringSlice(runs)))
+	for _, run := range runs {
+		b, err := ioutil.ReadFile(filepath.Join(dir, run, checkpointFilename))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading checkpoint: %v", err)
+		}
+		cp := &checkpoint{}
+		if err := json.Unmarshal(b, cp); err != nil {
+			return nil, fmt.Errorf("error reading checkpoint of run %q: %v", run, err)
+		}
+		return cp, nil
+	}
+	return nil, fmt.Errorf("no previous run of %q was found in %q", taskName, runsDir)
+}
+
+// writeResumePlaybook writes a playbook that contains the entries of the given
+// playbook starting at the entry that includes the play with the given index.
+// The new playbook is written next to the original one, so that includes are
+// resolved the same way. Returns the name of the new playbook, and the number
+// of plays that were skipped.
+func writeResumePlaybook(playbooksDir, playbook string, play int) (string, int, error) {
+	entries, err := readPlaybook(filepath.Join(playbooksDir, playbook))
+	if err != nil {
+		return "", 0, err
+	}
+	skipped := 0
+	for i, entry := range entries {
+		count, err := countPlays(playbooksDir, entry)
+		if err != nil {
+			return "", 0, err
+		}
+		if skipped+count <= play {
+			skipped += count
+			continue
+		}
+		b, err := yaml.Marshal(entries[i:])
+		if err != nil {
+			return "", 0, fmt.Errorf("error marshalling resume playbook: %v", err)
+		}
+		name := strings.TrimSuffix(playbook, filepath.Ext(playbook)) + "-resume.yaml"
+		if err := ioutil.WriteFile(filepath.Join(playbooksDir, name), append([]byte("---\n"), b...), 0644); err != nil {
+			return "", 0, fmt.Errorf("error writing resume playbook: %v", err)
+		}
+		return name, skipped, nil
+	}
+	return "", 0, errNothingToResume
+}
+
+func readPlaybook(file string) ([]yaml.MapSlice, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("error reading playbook: %v", err)
+	}
+	entries := []yaml.MapSlice{}
+	if err := yaml.Unmarshal(b, &entries); err != nil {
+		return nil, fmt.Errorf("error parsing playbook %q: %v", file, err)
+	}
+	return entries, nil
+}
+
+// countPlays returns the number of plays in the playbook entry, following includes
+func countPlays(playbooksDir string, entry yaml.MapSlice) (int, error) {
+	for _, item := range entry {
+		if item.Key != "include" {
+			continue
+		}
+		include, ok := item.Value.(string)
+		if !ok || len(strings.Fields(include)) == 0 {
+			return 0, fmt.Errorf("invalid include %v", item.Value)
+		}
+		included, err := readPlaybook(filepath.Join(playbooksDir, strings.Fields(include)[0]))
+		if err != nil {
+			return 0, err
+		}
+		count := 0
+		for _, e := range included {
+			n, err := countPlays(playbooksDir, e)
+			if err != nil {
+				return 0, err
+			}
+			count += n
+		}
+		return count, nil
+	}
+	return 1, nil
+}
@@ -0,0 +1,216 @@
+package install
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/apprenda/kismatic/pkg/ansible"
+)
+
+func playStart(name string) *ansible.PlayStartEvent {
+	e := &ansible.PlayStartEvent{}
+	e.Name = name
+	return e
+}
+
+func runnerOK(host string) *ansible.RunnerOKEvent {
+	e := &ansible.RunnerOKEvent{}
+	e.Host = host
+	return e
+}
+
+func runnerFailed(host string, ignoreErrors bool) *ansible.RunnerFailedEvent {
+	e := &ansible.RunnerFailedEvent{}
+	e.Host = host
+	e.IgnoreErrors = ignoreErrors
+	return e
+}
+
+func TestCheckpointRecorderPlayStatus(t *testing.T) {
+	r := &checkpointRecorder{file: filepath.Join(mustGetTempDir(t), checkpointFilename)}
+	events := []ansible.Event{
+		playStart("first"),
+		runnerOK("node1"),
+		runnerFailed("node2", true),
+		runnerOK("node2"),
+		playStart("second"),
+		runnerOK("node1"),
+		runnerFailed("node2", false),
+		runnerOK("node2"),
+	}
+	for _, e := range events {
+		r.update(e)
+	}
+	if err := r.finish(nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(r.cp.Plays) != 2 {
+		t.Fatalf("expected 2 plays, got %d", len(r.cp.Plays))
+	}
+	if r.cp.Plays[0].Status != playCompleted {
+		t.Errorf("expected first play to be %q, got %q", playCompleted, r.cp.Plays[0].Status)
+	}
+	if r.cp.Plays[1].Status != playFailed {
+		t.Errorf("expected second play to be %q, got %q", playFailed, r.cp.Plays[1].Status)
+	}
+	if r.cp.Plays[1].Hosts["node2"] != hostFailed {
+		t.Errorf("expected node2 to be %q, got %q", hostFailed, r.cp.Plays[1].Hosts["node2"])
+	}
+	if r.cp.nextPlay() != 1 {
+		t.Errorf("expected next play to be 1, got %d", r.cp.nextPlay())
+	}
+}
+
+func TestCheckpointRecorderAnsibleExitedDuringPlay(t *testing.T) {
+	r := &checkpointRecorder{
+		file: filepath.Join(mustGetTempDir(t), checkpointFilename),
+		cp:   checkpoint{FirstPlay: 3},
+	}
+	r.update(playStart("first"))
+	r.update(runnerOK("node1"))
+	if err := r.finish(os.ErrInvalid); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.cp.Complete {
+		t.Errorf("checkpoint was marked as complete")
+	}
+	if r.cp.nextPlay() != 3 {
+		t.Errorf("expected next play to be 3, got %d", r.cp.nextPlay())
+	}
+	b, err := ioutil.ReadFile(r.file)
+	if err != nil {
+		t.Fatalf("checkpoint file was not written: %v", err)
+	}
+	cp := checkpoint{}
+	if err := json.Unmarshal(b, &cp); err != nil {
+		t.Fatalf("error reading checkpoint file: %v", err)
+	}
+	if len(cp.Plays) != 1 || cp.Plays[0].Status != playFailed {
+		t.Errorf("unexpected checkpoint file contents: %s", string(b))
+	}
+}
+
+func mustWriteFile(t *testing.T, file string, contents string) {
+	if err := ioutil.WriteFile(file, []byte(contents), 0644); err != nil {
+		t.Fatalf("error writing file: %v", err)
+	}
+}
+
+func TestWriteResumePlaybook(t *testing.T) {
+	dir := mustGetTempDir(t)
+	defer os.RemoveAll(dir)
+	mustWriteFile(t, filepath.Join(dir, "main.yaml"), `---
+  - include: _a.yaml
+  - include: _b.yaml play_name="B"
+    when: foo|bool == true
+  - include: _c.yaml
+`)
+	mustWriteFile(t, filepath.Join(dir, "_a.yaml"), `---
+  - hosts: all
+    name: "A1"
+  - hosts: all
+    name: "A2"
+`)
+	mustWriteFile(t, filepath.Join(dir, "_b.yaml"), `---
+  - hosts: all
+    name: "{{ play_name | default('B') }}"
+`)
+	mustWriteFile(t, filepath.Join(dir, "_c.yaml"), `---
+  - hosts: all
+    name: "C"
+`)
+	tests := []struct {
+		play            int
+		expectedSkipped int
+		expected        []string
+	}{
+		{play: 0, expectedSkipped: 0, expected: []string{"_a.yaml", "_b.yaml", "_c.yaml"}},
+		{play: 1, expectedSkipped: 0, expected: []string{"_a.yaml", "_b.yaml", "_c.yaml"}},
+		{play: 2, expectedSkipped: 2, expected: []string{"_b.yaml", "_c.yaml"}},
+		{play: 3, expectedSkipped: 3, expected: []string{"_c.yaml"}},
+	}
+	for _, test := range tests {
+		name, skipped, err := writeResumePlaybook(dir, "main.yaml", test.play)
+		if err != nil {
+			t.Errorf("play %d: unexpected error: %v", test.play, err)
+			continue
+		}
+		if skipped != test.expectedSkipped {
+			t.Errorf("play %d: expected %d skipped plays, got %d", test.play, test.expectedSkipped, skipped)
+		}
+		entries, err := readPlaybook(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("error reading resume playbook: %v", err)
+		}
+		if len(entries) != len(test.expected) {
+			t.Errorf("play %d: expected %d entries, got %d", test.play, len(test.expected), len(entries))
+			continue
+		}
+		for i, e := range entries {
+			include := e[0].Value.(string)
+			if !strings.HasPrefix(include, test.expected[i]) {
+				t.Errorf("play %d: expected entry %d to include %q, got %q", test.play, i, test.expected[i], include)
+			}
+		}
+	}
+	if _, _, err := writeResumePlaybook(dir, "main.yaml", 4); err != errNothingToResume {
+		t.Errorf("expected errNothingToResume, got %v", err)
+	}
+}
+
+func mustWriteCheckpoint(t *testing.T, runsDir, run string, cp checkpoint) {
+	dir := filepath.Join(runsDir, "apply", run)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("error creating run directory: %v", err)
+	}
+	b, err := json.Marshal(cp)
+	if err != nil {
+		t.Fatalf("error marshaling checkpoint: %v", err)
+	}
+	mustWriteFile(t, filepath.Join(dir, checkpointFilename), string(b))
+}
+
+func TestResumePoint(t *testing.T) {
+	runsDir := mustGetTempDir(t)
+	defer os.RemoveAll(runsDir)
+	e := ansibleExecutor{options: ExecutorOptions{RunsDirectory: runsDir}}
+	p := Plan{Cluster: Cluster{Name: "foo"}}
+	hash, err := planHash(p)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := e.resumePoint("apply", p); err == nil {
+		t.Errorf("expected an error when there is no previous run")
+	}
+
+	mustWriteCheckpoint(t, runsDir, "2017-01-01-00-00-00", checkpoint{Playbook: "kubernetes.yaml", PlanHash: hash, Complete: true})
+	mustWriteCheckpoint(t, runsDir, "2017-01-02-00-00-00", checkpoint{
+		Playbook:  "kubernetes.yaml",
+		PlanHash:  hash,
+		FirstPlay: 2,
+		Plays:     []playCheckpoint{{Name: "a", Status: playCompleted}, {Name: "b", Status: playFailed}},
+	})
+	rp, err := e.resumePoint("apply", p)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rp.playbook != "kubernetes.yaml" || rp.firstPlay != 3 {
+		t.Errorf("unexpected resume point: %+v", rp)
+	}
+
+	p.Cluster.Name = "bar"
+	if _, err := e.resumePoint("apply", p); err == nil {
+		t.Errorf("expected an error when the plan has changed")
+	}
+
+	mustWriteCheckpoint(t, runsDir, "2017-01-03-00-00-00", checkpoint{Playbook: "kubernetes.yaml", PlanHash: hash, Complete: true})
+	p.Cluster.Name = "foo"
+	if _, err := e.resumePoint("apply", p); err != errNothingToResume {
+		t.Errorf("expected errNothingToResume, got %v", err)
+	}
+}
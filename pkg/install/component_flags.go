@@ -0,0 +1,551 @@
+package install
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// flagType is the type of the value of a Kubernetes component flag
+type flagType int
+
+const (
+	stringFlag flagType = iota
+	boolFlag
+	durationFlag
+)
+
+// maxSuggestionDistance is the maximum edit distance between an unknown
+// option and a known flag for the flag to be suggested
+const maxSuggestionDistance = 3
+
+// componentFlags are the flags of each Kubernetes component
+type componentFlags struct {
+	apiServer         map[string]flagType
+	controllerManager map[string]flagType
+	scheduler         map[string]flagType
+	proxy             map[string]flagType
+	kubelet           map[string]flagType
+}
+
+// logFlags are the logging flags shared by all the Kubernetes components
+var logFlags = map[string]flagType{
+	"alsologtostderr":     boolFlag,
+	"log-backtrace-at":    stringFlag,
+	"log-dir":             stringFlag,
+	"log-flush-frequency": durationFlag,
+	"logtostderr":         boolFlag,
+	"stderrthreshold":     stringFlag,
+	"v":                   stringFlag,
+	"version":             stringFlag,
+	"vmodule":             stringFlag,
+}
+
+// knownComponentFlags lists the flags of the Kubernetes components, for each
+// Kubernetes minor version installed by KET
+var knownComponentFlags = map[string]componentFlags{
+	"v1.8": {
+		apiServer: withFlags(logFlags, map[string]flagType{
+			"admission-control":                            stringFlag,
+			"admission-control-config-file":                stringFlag,
+			"advertise-address":                            stringFlag,
+			"allow-privileged":                             boolFlag,
+			"anonymous-auth":                               boolFlag,
+			"apiserver-count":                              stringFlag,
+			"audit-log-format":                             stringFlag,
+			"audit-log-maxage":                             stringFlag,
+			"audit-log-maxbackup":                          stringFlag,
+			"audit-log-maxsize":                            stringFlag,
+			"audit-log-path":                               stringFlag,
+			"audit-policy-file":                            stringFlag,
+			"audit-webhook-config-file":                    stringFlag,
+			"audit-webhook-mode":                           stringFlag,
+			"authentication-token-webhook-cache-ttl":       durationFlag,
+			"authentication-token-webhook-config-file":     stringFlag,
+			"authorization-mode":                           stringFlag,
+			"authorization-policy-file":                    stringFlag,
+			"authorization-rbac-super-user":                stringFlag,
+			"authorization-webhook-cache-authorized-ttl":   durationFlag,
+			"authorization-webhook-cache-unauthorized-ttl": durationFlag,
+			"authorization-webhook-config-file":            stringFlag,
+			"basic-auth-file":                              stringFlag,
+			"bind-address":                                 stringFlag,
+			"cert-dir":                                     stringFlag,
+			"client-ca-file":                               stringFlag,
+			"cloud-config":                                 stringFlag,
+			"cloud-provider":                               stringFlag,
+			"contention-profiling":                         boolFlag,
+			"cors-allowed-origins":                         stringFlag,
+			"default-not-ready-toleration-seconds":         stringFlag,
+			"default-unreachable-toleration-seconds":       stringFlag,
+			"default-watch-cache-size":                     stringFlag,
+			"delete-collection-workers":                    stringFlag,
+			"deserialization-cache-size":                   stringFlag,
+			"enable-aggregator-routing":                    boolFlag,
+			"enable-bootstrap-token-auth":                  boolFlag,
+			"enable-garbage-collector":                     boolFlag,
+			"enable-logs-handler":                          boolFlag,
+			"enable-swagger-ui":                            boolFlag,
+			"etcd-cafile":                                  stringFlag,
+			"etcd-certfile":                                stringFlag,
+			"etcd-keyfile":                                 stringFlag,
+			"etcd-prefix":                                  stringFlag,
+			"etcd-quorum-read":                             boolFlag,
+			"etcd-servers":                                 stringFlag,
+			"etcd-servers-overrides":                       stringFlag,
+			"event-ttl":                                    durationFlag,
+			"experimental-bootstrap-token-auth":            boolFlag,
+			"experimental-encryption-provider-config":      stringFlag,
+			"experimental-keystone-ca-file":                stringFlag,
+			"experimental-keystone-url":                    stringFlag,
+			"external-hostname":                            stringFlag,
+			"feature-gates":                                stringFlag,
+			"insecure-bind-address":                        stringFlag,
+			"insecure-port":                                stringFlag,
+			"kubelet-certificate-authority":                stringFlag,
+			"kubelet-client-certificate":                   stringFlag,
+			"kubelet-client-key":                           stringFlag,
+			"kubelet-https":                                boolFlag,
+			"kubelet-preferred-address-types":              stringFlag,
+			"kubelet-read-only-port":                       stringFlag,
+			"kubelet-timeout":                              durationFlag,
+			"kubernetes-service-node-port":                 stringFlag,
+			"master-service-namespace":                     stringFlag,
+			"max-connection-bytes-per-sec":                 stringFlag,
+			"max-mutating-requests-inflight":               stringFlag,
+			"max-requests-inflight":                        stringFlag,
+			"min-request-timeout":                          stringFlag,
+			"oidc-ca-file":                                 stringFlag,
+			"oidc-client-id":                               stringFlag,
+			"oidc-groups-claim":                            stringFlag,
+			"oidc-groups-prefix":                           stringFlag,
+			"oidc-issuer-url":                              stringFlag,
+			"oidc-username-claim":                          stringFlag,
+			"oidc-username-prefix":                         stringFlag,
+			"profiling":                                    boolFlag,
+			"proxy-client-cert-file":                       stringFlag,
+			"proxy-client-key-file":                        stringFlag,
+			"repair-malformed-updates":                     boolFlag,
+			"request-timeout":                              durationFlag,
+			"requestheader-allowed-names":                  stringFlag,
+			"requestheader-client-ca-file":                 stringFlag,
+			"requestheader-extra-headers-prefix":           stringFlag,
+			"requestheader-group-headers":                  stringFlag,
+			"requestheader-username-headers":               stringFlag,
+			"runtime-config":                               stringFlag,
+			"secure-port":                                  stringFlag,
+			"service-account-key-file":                     stringFlag,
+			"service-account-lookup":                       boolFlag,
+			"service-cluster-ip-range":                     stringFlag,
+			"service-node-port-range":                      stringFlag,
+			"ssh-keyfile":                                  stringFlag,
+			"ssh-user":                                     stringFlag,
+			"storage-backend":                              stringFlag,
+			"storage-media-type":                           stringFlag,
+			"storage-versions":                             stringFlag,
+			"target-ram-mb":                                stringFlag,
+			"tls-ca-file":                                  stringFlag,
+			"tls-cert-file":                                stringFlag,
+			"tls-cipher-suites":                            stringFlag,
+			"tls-min-version":                              stringFlag,
+			"tls-private-key-file":                         stringFlag,
+			"tls-sni-cert-key":                             stringFlag,
+			"token-auth-file":                              stringFlag,
+			"watch-cache":                                  boolFlag,
+			"watch-cache-sizes":                            stringFlag,
+		}),
+		controllerManager: withFlags(logFlags, map[string]flagType{
+			"address":                                                  stringFlag,
+			"allocate-node-cidrs":                                      boolFlag,
+			"attach-detach-reconcile-sync-period":                      durationFlag,
+			"cidr-allocator-type":                                      stringFlag,
+			"cloud-config":                                             stringFlag,
+			"cloud-provider":                                           stringFlag,
+			"cluster-cidr":                                             stringFlag,
+			"cluster-name":                                             stringFlag,
+			"cluster-signing-cert-file":                                stringFlag,
+			"cluster-signing-key-file":                                 stringFlag,
+			"concurrent-deployment-syncs":                              stringFlag,
+			"concurrent-endpoint-syncs":                                stringFlag,
+			"concurrent-gc-syncs":                                      stringFlag,
+			"concurrent-namespace-syncs":                               stringFlag,
+			"concurrent-replicaset-syncs":                              stringFlag,
+			"concurrent-resource-quota-syncs":                          stringFlag,
+			"concurrent-service-syncs":                                 stringFlag,
+			"concurrent-serviceaccount-token-syncs":                    stringFlag,
+			"concurrent_rc_syncs":                                      stringFlag,
+			"configure-cloud-routes":                                   boolFlag,
+			"contention-profiling":                                     boolFlag,
+			"controller-start-interval":                                durationFlag,
+			"controllers":                                              stringFlag,
+			"daemonset-lookup-cache-size":                              stringFlag,
+			"deployment-controller-sync-period":                        durationFlag,
+			"disable-attach-detach-reconcile-sync":                     boolFlag,
+			"enable-dynamic-provisioning":                              boolFlag,
+			"enable-garbage-collector":                                 boolFlag,
+			"enable-hostpath-provisioner":                              boolFlag,
+			"enable-taint-manager":                                     boolFlag,
+			"experimental-cluster-signing-duration":                    durationFlag,
+			"feature-gates":                                            stringFlag,
+			"flex-volume-plugin-dir":                                   stringFlag,
+			"horizontal-pod-autoscaler-downscale-delay":                durationFlag,
+			"horizontal-pod-autoscaler-sync-period":                    durationFlag,
+			"horizontal-pod-autoscaler-tolerance":                      stringFlag,
+			"horizontal-pod-autoscaler-upscale-delay":                  durationFlag,
+			"horizontal-pod-autoscaler-use-rest-clients":               boolFlag,
+			"insecure-experimental-approve-all-kubelet-csrs-for-group": stringFlag,
+			"kube-api-burst":                                           stringFlag,
+			"kube-api-content-type":                                    stringFlag,
+			"kube-api-qps":                                             stringFlag,
+			"kubeconfig":                                               stringFlag,
+			"large-cluster-size-threshold":                             stringFlag,
+			"leader-elect":                                             boolFlag,
+			"leader-elect-lease-duration":                              durationFlag,
+			"leader-elect-renew-deadline":                              durationFlag,
+			"leader-elect-resource-lock":                               stringFlag,
+			"leader-elect-retry-period":                                durationFlag,
+			"master":                                                   stringFlag,
+			"min-resync-period":                                        durationFlag,
+			"namespace-sync-period":                                    durationFlag,
+			"node-cidr-mask-size":                                      stringFlag,
+			"node-eviction-rate":                                       stringFlag,
+			"node-monitor-grace-period":                                durationFlag,
+			"node-monitor-period":                                      durationFlag,
+			"node-startup-grace-period":                                durationFlag,
+			"node-sync-period":                                         durationFlag,
+			"pod-eviction-timeout":                                     durationFlag,
+			"port":                                                     stringFlag,
+			"profiling":                                                boolFlag,
+			"pv-recycler-increment-timeout-nfs":                        stringFlag,
+			"pv-recycler-minimum-timeout-hostpath":                     stringFlag,
+			"pv-recycler-minimum-timeout-nfs":                          stringFlag,
+			"pv-recycler-pod-template-filepath-hostpath":               stringFlag,
+			"pv-recycler-pod-template-filepath-nfs":                    stringFlag,
+			"pv-recycler-timeout-increment-hostpath":                   stringFlag,
+			"pvclaimbinder-sync-period":                                durationFlag,
+			"replicaset-lookup-cache-size":                             stringFlag,
+			"replication-controller-lookup-cache-size":                 stringFlag,
+			"resource-quota-sync-period":                               durationFlag,
+			"root-ca-file":                                             stringFlag,
+			"route-reconciliation-period":                              durationFlag,
+			"secondary-node-eviction-rate":                             stringFlag,
+			"service-account-private-key-file":                         stringFlag,
+			"service-cluster-ip-range":                                 stringFlag,
+			"service-sync-period":                                      durationFlag,
+			"terminated-pod-gc-threshold":                              stringFlag,
+			"unhealthy-zone-threshold":                                 stringFlag,
+			"use-service-account-credentials":                          boolFlag,
+		}),
+		scheduler: withFlags(logFlags, map[string]flagType{
+			"address":                            stringFlag,
+			"algorithm-provider":                 stringFlag,
+			"contention-profiling":               boolFlag,
+			"failure-domains":                    stringFlag,
+			"feature-gates":                      stringFlag,
+			"hard-pod-affinity-symmetric-weight": stringFlag,
+			"kube-api-burst":                     stringFlag,
+			"kube-api-content-type":              stringFlag,
+			"kube-api-qps":                       stringFlag,
+			"kubeconfig":                         stringFlag,
+			"leader-elect":                       boolFlag,
+			"leader-elect-lease-duration":        durationFlag,
+			"leader-elect-renew-deadline":        durationFlag,
+			"leader-elect-resource-lock":         stringFlag,
+			"leader-elect-retry-period":          durationFlag,
+			"lock-object-name":                   stringFlag,
+			"lock-object-namespace":              stringFlag,
+			"master":                             stringFlag,
+			"policy-config-file":                 stringFlag,
+			"policy-configmap":                   stringFlag,
+			"policy-configmap-namespace":         stringFlag,
+			"port":                               stringFlag,
+			"profiling":                          boolFlag,
+			"scheduler-name":                     stringFlag,
+			"use-legacy-policy-config":           boolFlag,
+		}),
+		proxy: withFlags(logFlags, map[string]flagType{
+			"bind-address":                      stringFlag,
+			"cleanup-iptables":                  boolFlag,
+			"cluster-cidr":                      stringFlag,
+			"config":                            stringFlag,
+			"config-sync-period":                durationFlag,
+			"conntrack-max-per-core":            stringFlag,
+			"conntrack-min":                     stringFlag,
+			"conntrack-tcp-timeout-close-wait":  durationFlag,
+			"conntrack-tcp-timeout-established": durationFlag,
+			"feature-gates":                     stringFlag,
+			"healthz-bind-address":              stringFlag,
+			"healthz-port":                      stringFlag,
+			"hostname-override":                 stringFlag,
+			"iptables-masquerade-bit":           stringFlag,
+			"iptables-min-sync-period":          durationFlag,
+			"iptables-sync-period":              durationFlag,
+			"ipvs-min-sync-period":              durationFlag,
+			"ipvs-scheduler":                    stringFlag,
+			"ipvs-sync-period":                  durationFlag,
+			"kube-api-burst":                    stringFlag,
+			"kube-api-content-type":             stringFlag,
+			"kube-api-qps":                      stringFlag,
+			"kubeconfig":                        stringFlag,
+			"masquerade-all":                    boolFlag,
+			"master":                            stringFlag,
+			"metrics-bind-address":              stringFlag,
+			"oom-score-adj":                     stringFlag,
+			"profiling":                         boolFlag,
+			"proxy-mode":                        stringFlag,
+			"proxy-port-range":                  stringFlag,
+			"resource-container":                stringFlag,
+			"udp-timeout":                       durationFlag,
+			"write-config-to":                   stringFlag,
+		}),
+		kubelet: withFlags(logFlags, map[string]flagType{
+			"address":                                           stringFlag,
+			"allow-privileged":                                  boolFlag,
+			"anonymous-auth":                                    boolFlag,
+			"authentication-token-webhook":                      boolFlag,
+			"authentication-token-webhook-cache-ttl":            durationFlag,
+			"authorization-mode":                                stringFlag,
+			"authorization-webhook-cache-authorized-ttl":        durationFlag,
+			"authorization-webhook-cache-unauthorized-ttl":      durationFlag,
+			"azure-container-registry-config":                   stringFlag,
+			"bootstrap-kubeconfig":                              stringFlag,
+			"cadvisor-port":                                     stringFlag,
+			"cert-dir":                                          stringFlag,
+			"cgroup-driver":                                     stringFlag,
+			"cgroup-root":                                       stringFlag,
+			"cgroups-per-qos":                                   boolFlag,
+			"chaos-chance":                                      stringFlag,
+			"client-ca-file":                                    stringFlag,
+			"cloud-config":                                      stringFlag,
+			"cloud-provider":                                    stringFlag,
+			"cluster-dns":                                       stringFlag,
+			"cluster-domain":                                    stringFlag,
+			"cni-bin-dir":                                       stringFlag,
+			"cni-conf-dir":                                      stringFlag,
+			"container-runtime":                                 stringFlag,
+			"container-runtime-endpoint":                        stringFlag,
+			"containerized":                                     boolFlag,
+			"contention-profiling":                              boolFlag,
+			"cpu-cfs-quota":                                     boolFlag,
+			"cpu-manager-policy":                                stringFlag,
+			"cpu-manager-reconcile-period":                      durationFlag,
+			"docker":                                            stringFlag,
+			"docker-disable-shared-pid":                         boolFlag,
+			"docker-endpoint":                                   stringFlag,
+			"docker-exec-handler":                               stringFlag,
+			"enable-controller-attach-detach":                   boolFlag,
+			"enable-custom-metrics":                             boolFlag,
+			"enable-debugging-handlers":                         boolFlag,
+			"enable-server":                                     boolFlag,
+			"enforce-node-allocatable":                          stringFlag,
+			"event-burst":                                       stringFlag,
+			"event-qps":                                         stringFlag,
+			"eviction-hard":                                     stringFlag,
+			"eviction-max-pod-grace-period":                     stringFlag,
+			"eviction-minimum-reclaim":                          stringFlag,
+			"eviction-pressure-transition-period":               durationFlag,
+			"eviction-soft":                                     stringFlag,
+			"eviction-soft-grace-period":                        stringFlag,
+			"exit-on-lock-contention":                           boolFlag,
+			"experimental-allocatable-ignore-eviction":          boolFlag,
+			"experimental-allowed-unsafe-sysctls":               stringFlag,
+			"experimental-bootstrap-kubeconfig":                 stringFlag,
+			"experimental-check-node-capabilities-before-mount": boolFlag,
+			"experimental-fail-swap-on":                         boolFlag,
+			"experimental-kernel-memcg-notification":            boolFlag,
+			"experimental-mounter-path":                         stringFlag,
+			"experimental-qos-reserved":                         stringFlag,
+			"fail-swap-on":                                      boolFlag,
+			"feature-gates":                                     stringFlag,
+			"file-check-frequency":                              durationFlag,
+			"google-json-key":                                   stringFlag,
+			"hairpin-mode":                                      stringFlag,
+			"healthz-bind-address":                              stringFlag,
+			"healthz-port":                                      stringFlag,
+			"host-ipc-sources":                                  stringFlag,
+			"host-network-sources":                              stringFlag,
+			"host-pid-sources":                                  stringFlag,
+			"hostname-override":                                 stringFlag,
+			"http-check-frequency":                              durationFlag,
+			"image-gc-high-threshold":                           stringFlag,
+			"image-gc-low-threshold":                            stringFlag,
+			"image-pull-progress-deadline":                      durationFlag,
+			"image-service-endpoint":                            stringFlag,
+			"iptables-drop-bit":                                 stringFlag,
+			"iptables-masquerade-bit":                           stringFlag,
+			"keep-terminated-pod-volumes":                       boolFlag,
+			"kube-api-burst":                                    stringFlag,
+			"kube-api-content-type":                             stringFlag,
+			"kube-api-qps":                                      stringFlag,
+			"kube-reserved":                                     stringFlag,
+			"kube-reserved-cgroup":                              stringFlag,
+			"kubeconfig":                                        stringFlag,
+			"kubelet-cgroups":                                   stringFlag,
+			"lock-file":                                         stringFlag,
+			"make-iptables-util-chains":                         boolFlag,
+			"manifest-url":                                      stringFlag,
+			"manifest-url-header":                               stringFlag,
+			"max-open-files":                                    stringFlag,
+			"max-pods":                                          stringFlag,
+			"minimum-container-ttl-duration":                    durationFlag,
+			"minimum-image-ttl-duration":                        durationFlag,
+			"network-plugin":                                    stringFlag,
+			"network-plugin-mtu":                                stringFlag,
+			"node-ip":                                           stringFlag,
+			"node-labels":                                       stringFlag,
+			"node-status-update-frequency":                      durationFlag,
+			"non-masquerade-cidr":                               stringFlag,
+			"oom-score-adj":                                     stringFlag,
+			"pod-cidr":                                          stringFlag,
+			"pod-infra-container-image":                         stringFlag,
+			"pod-manifest-path":                                 stringFlag,
+			"pods-per-core":                                     stringFlag,
+			"port":                                              stringFlag,
+			"protect-kernel-defaults":                           boolFlag,
+			"provider-id":                                       stringFlag,
+			"read-only-port":                                    stringFlag,
+			"register-node":                                     boolFlag,
+			"register-schedulable":                              boolFlag,
+			"register-with-taints":                              stringFlag,
+			"registry-burst":                                    stringFlag,
+			"registry-qps":                                      stringFlag,
+			"require-kubeconfig":                                boolFlag,
+			"resolv-conf":                                       stringFlag,
+			"rkt-api-endpoint":                                  stringFlag,
+			"rkt-path":                                          stringFlag,
+			"rkt-stage1-image":                                  stringFlag,
+			"root-dir":                                          stringFlag,
+			"rotate-certificates":                               boolFlag,
+			"runonce":                                           boolFlag,
+			"runtime-cgroups":                                   stringFlag,
+			"runtime-request-timeout":                           durationFlag,
+			"seccomp-profile-root":                              stringFlag,
+			"serialize-image-pulls":                             boolFlag,
+			"streaming-connection-idle-timeout":                 durationFlag,
+			"sync-frequency":                                    durationFlag,
+			"system-cgroups":                                    stringFlag,
+			"system-reserved":                                   stringFlag,
+			"system-reserved-cgroup":                            stringFlag,
+			"tls-cert-file":                                     stringFlag,
+			"tls-cipher-suites":                                 stringFlag,
+			"tls-min-version":                                   stringFlag,
+			"tls-private-key-file":                              stringFlag,
+			"volume-plugin-dir":                                 stringFlag,
+			"volume-stats-agg-period":                           durationFlag,
+		}),
+	},
+}
+
+// withFlags returns the union of the flag sets
+func withFlags(sets ...map[string]flagType) map[string]flagType {
+	flags := map[string]flagType{}
+	for _, s := range sets {
+		for name, t := range s {
+			flags[name] = t
+		}
+	}
+	return flags
+}
+
+// optionOverridesWithPrefix returns the sorted names of the overrides that
+// start with the prefix
+func optionOverridesWithPrefix(overrides map[string]string, prefix string) []string {
+	found := []string{}
+	for o := range overrides {
+		if strings.HasPrefix(o, prefix) {
+			found = append(found, o)
+		}
+	}
+	sort.Strings(found)
+	return found
+}
+
+// validateOptionOverrides returns an error for each override that is not a
+// known flag of the component, or whose value does not match the type of the
+// flag. The overrides are not validated when the flags of the component are
+// not known.
+func validateOptionOverrides(component string, overrides map[string]string, known map[string]flagType) []error {
+	errs := []error{}
+	if len(known) == 0 {
+		return errs
+	}
+	names := make([]string, 0, len(overrides))
+	for name := range overrides {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := overrides[name]
+		t, ok := known[name]
+		if !ok {
+			if suggestion := suggestFlag(name, known); suggestion != "" {
+				errs = append(errs, fmt.Errorf("%s Option %q is not a valid option, did you mean %q?", component, name, suggestion))
+			} else {
+				errs = append(errs, fmt.Errorf("%s Option %q is not a valid option", component, name))
+			}
+			continue
+		}
+		switch t {
+		case boolFlag:
+			if _, err := strconv.ParseBool(value); err != nil {
+				errs = append(errs, fmt.Errorf("%s Option %q must be a boolean, but got %q", component, name, value))
+			}
+		case durationFlag:
+			if _, err := time.ParseDuration(value); err != nil {
+				errs = append(errs, fmt.Errorf("%s Option %q must be a duration such as \"2h0m0s\", but got %q", component, name, value))
+			}
+		}
+	}
+	return errs
+}
+
+// suggestFlag returns the known flag that is the closest to the name, or an
+// empty string if none is close enough
+func suggestFlag(name string, known map[string]flagType) string {
+	suggestion := ""
+	best := maxSuggestionDistance + 1
+	for flag := range known {
+		d := editDistance(name, flag)
+		if d < best || (d == best && flag < suggestion) {
+			suggestion = flag
+			best = d
+		}
+	}
+	if best > maxSuggestionDistance {
+		return ""
+	}
+	return suggestion
+}
+
+// editDistance returns the Levenshtein distance between the two strings
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = minInt(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+func minInt(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
@@ -0,0 +1,76 @@
+package install
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestValidateOptionOverrides(t *testing.T) {
+	known := knownComponentFlags[kubernetesMinorVersion].apiServer
+	tests := []struct {
+		overrides map[string]string
+		errs      []error
+	}{
+		{
+			overrides: map[string]string{"event-ttl": "2h0m0s", "enable-swagger-ui": "false", "v": "3"},
+			errs:      []error{},
+		},
+		{
+			overrides: map[string]string{"authorization-mod": "RBAC"},
+			errs:      []error{fmt.Errorf(`Kube ApiServer Option "authorization-mod" is not a valid option, did you mean "authorization-mode"?`)},
+		},
+		{
+			overrides: map[string]string{"foobar": "baz"},
+			errs:      []error{fmt.Errorf(`Kube ApiServer Option "foobar" is not a valid option`)},
+		},
+		{
+			overrides: map[string]string{"enable-swagger-ui": "yes"},
+			errs:      []error{fmt.Errorf(`Kube ApiServer Option "enable-swagger-ui" must be a boolean, but got "yes"`)},
+		},
+		{
+			overrides: map[string]string{"event-ttl": "2", "profiling": "1"},
+			errs:      []error{fmt.Errorf(`Kube ApiServer Option "event-ttl" must be a duration such as "2h0m0s", but got "2"`)},
+		},
+	}
+	for _, test := range tests {
+		errs := validateOptionOverrides("Kube ApiServer", test.overrides, known)
+		assertEqual(t, errs, test.errs)
+	}
+
+	// Overrides are not validated when the flags are not known
+	if errs := validateOptionOverrides("Kube ApiServer", map[string]string{"foobar": "baz"}, nil); len(errs) != 0 {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+}
+
+func TestValidateNodeKubeletOptionOverrides(t *testing.T) {
+	n := Node{
+		Host:           "worker01",
+		IP:             "192.168.205.12",
+		KubeletOptions: KubeletOptions{Overrides: map[string]string{"max-pod": "50"}},
+	}
+	ok, errs := n.validate()
+	if ok {
+		t.Fatal("expected unknown kubelet option of the node to be invalid")
+	}
+	assertEqual(t, errs, []error{fmt.Errorf(`Kubelet Option "max-pod" is not a valid option, did you mean "max-pods"?`)})
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		distance int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"max-pods", "max-pods", 0},
+		{"max-pod", "max-pods", 1},
+		{"authorization-mdoe", "authorization-mode", 2},
+		{"kitten", "sitting", 3},
+	}
+	for _, test := range tests {
+		if d := editDistance(test.a, test.b); d != test.distance {
+			t.Errorf("expected distance between %q and %q to be %d, but got %d", test.a, test.b, test.distance, d)
+		}
+	}
+}
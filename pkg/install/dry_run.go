@@ -0,0 +1,64 @@
+package install
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/apprenda/kismatic/pkg/util"
+)
+
+const (
+	dryRunInventoryFilename      = "inventory.ini"
+	dryRunClusterCatalogFilename = "clustercatalog.yaml"
+	dryRunSummaryFilename        = "dry-run.txt"
+)
+
+// dryRun writes the inventory and the cluster catalog of the task to the run
+// directory, along with a summary of what the task would do, instead of
+// running the playbook.
+func (ae *ansibleExecutor) dryRun(t task, runDirectory string) error {
+	inventoryFile := filepath.Join(runDirectory, dryRunInventoryFilename)
+	if err := ioutil.WriteFile(inventoryFile, t.inventory.ToINI(), 0644); err != nil {
+		return fmt.Errorf("error writing inventory file %q: %v", inventoryFile, err)
+	}
+	cc, err := t.clusterCatalog.ToYAML()
+	if err != nil {
+		return err
+	}
+	ccFile := filepath.Join(runDirectory, dryRunClusterCatalogFilename)
+	if err := ioutil.WriteFile(ccFile, cc, 0644); err != nil {
+		return fmt.Errorf("error writing cluster catalog file %q: %v", ccFile, err)
+	}
+	summaryFile := filepath.Join(runDirectory, dryRunSummaryFilename)
+	if err := ioutil.WriteFile(summaryFile, dryRunSummary(t, inventoryFile, ccFile), 0644); err != nil {
+		return fmt.Errorf("error writing dry run summary %q: %v", summaryFile, err)
+	}
+	util.PrettyPrintSkipped(ae.stdout, "Dry run of playbook %q, see %q for details", t.playbook, summaryFile)
+	return nil
+}
+
+// dryRunSummary describes what the task would do when run
+func dryRunSummary(t task, inventoryFile, ccFile string) []byte {
+	targets := "all nodes in the inventory"
+	if len(t.limit) > 0 {
+		targets = strings.Join(t.limit, ", ")
+	}
+	restarts := "none"
+	if services := t.clusterCatalog.RestartedServices(); len(services) > 0 {
+		restarts = strings.Join(services, ", ")
+	}
+	b := &bytes.Buffer{}
+	fmt.Fprintf(b, "Task: %s\n", t.name)
+	fmt.Fprintf(b, "Playbook: %s\n", t.playbook)
+	if t.resume != nil {
+		fmt.Fprintf(b, "Resuming: %s, starting at play %d\n", t.resume.playbook, t.resume.firstPlay+1)
+	}
+	fmt.Fprintf(b, "Targeted nodes: %s\n", targets)
+	fmt.Fprintf(b, "Forced service restarts: %s\n", restarts)
+	fmt.Fprintf(b, "Inventory: %s\n", inventoryFile)
+	fmt.Fprintf(b, "Cluster catalog: %s\n", ccFile)
+	return b.Bytes()
+}
@@ -0,0 +1,73 @@
+package install
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/apprenda/kismatic/pkg/ansible"
+	"github.com/apprenda/kismatic/pkg/install/explain"
+)
+
+func TestExecuteDryRun(t *testing.T) {
+	runsDir := mustGetTempDir(t)
+	e := ansibleExecutor{
+		options:             ExecutorOptions{DryRun: true, RunsDirectory: runsDir},
+		stdout:              ioutil.Discard,
+		consoleOutputFormat: ansible.RawFormat,
+		runnerExplainerFactory: func(explain.AnsibleEventExplainer, io.Writer) (ansible.Runner, *explain.AnsibleEventStreamExplainer, error) {
+			return nil, nil, errors.New("ansible must not run during a dry run")
+		},
+	}
+	cc := ansible.ClusterCatalog{ClusterName: "test"}
+	cc.EnableRestart()
+	cc.ForceDockerRestart = false
+	inventory := ansible.Inventory{
+		Roles: []ansible.Role{{Name: "worker", Nodes: []ansible.Node{{Host: "worker01", PublicIP: "10.0.0.1"}}}},
+	}
+	tk := task{
+		name:           "add-worker",
+		playbook:       "kubernetes-worker.yaml",
+		inventory:      inventory,
+		clusterCatalog: cc,
+		limit:          []string{"worker01"},
+	}
+	if err := e.execute(tk); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	runs, err := ListRuns(runsDir)
+	if err != nil {
+		t.Fatalf("error listing runs: %v", err)
+	}
+	if len(runs) != 1 {
+		t.Fatalf("expected 1 run, got %d", len(runs))
+	}
+	if runs[0].Metadata.Result != RunResultDryRun {
+		t.Errorf("expected result %q, got %q", RunResultDryRun, runs[0].Metadata.Result)
+	}
+	dir := runs[0].Directory
+	for _, f := range []string{runPlanFilename, dryRunInventoryFilename, dryRunClusterCatalogFilename} {
+		if _, err := ioutil.ReadFile(filepath.Join(dir, f)); err != nil {
+			t.Errorf("expected %q to be written: %v", f, err)
+		}
+	}
+	b, err := ioutil.ReadFile(filepath.Join(dir, dryRunSummaryFilename))
+	if err != nil {
+		t.Fatalf("error reading summary: %v", err)
+	}
+	summary := string(b)
+	expected := []string{
+		"Playbook: kubernetes-worker.yaml",
+		"Targeted nodes: worker01",
+		"Forced service restarts: etcd, kube-apiserver, kube-controller-manager, kube-scheduler, kube-proxy, kubelet, calico-node\n",
+	}
+	for _, s := range expected {
+		if !strings.Contains(summary, s) {
+			t.Errorf("expected summary to contain %q, got:\n%s", s, summary)
+		}
+	}
+}
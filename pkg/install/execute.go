@@ -13,6 +13,7 @@ import (
 
 	"github.com/apprenda/kismatic/pkg/ansible"
 	"github.com/apprenda/kismatic/pkg/install/explain"
+	"github.com/apprenda/kismatic/pkg/ssh"
 	"github.com/apprenda/kismatic/pkg/tls"
 	"github.com/apprenda/kismatic/pkg/util"
 )
@@ -22,6 +23,8 @@ import (
 type PreFlightExecutor interface {
 	RunPreFlightCheck(*Plan) error
 	RunNewWorkerPreFlightCheck(Plan, Node) error
+	RunNewMasterPreFlightCheck(Plan, Node) error
+	RunNewEtcdPreFlightCheck(Plan, Node) error
 	RunUpgradePreFlightCheck(*Plan, ListableNode) error
 }
 
@@ -32,12 +35,17 @@ type Executor interface {
 	GenerateCertificates(p *Plan, useExistingCA bool) error
 	RunSmokeTest(*Plan) error
 	AddWorker(*Plan, Node) (*Plan, error)
+	AddMaster(*Plan, Node) (*Plan, error)
+	AddEtcd(*Plan, Node) (*Plan, error)
+	RemoveNode(*Plan, Node) (*Plan, error)
 	RunPlay(string, *Plan) error
 	AddVolume(*Plan, StorageVolume) error
 	DeleteVolume(*Plan, string) error
 	UpgradeNodes(plan Plan, nodesToUpgrade []ListableNode, onlineUpgrade bool, maxParallelWorkers int) error
 	ValidateControlPlane(plan Plan) error
 	UpgradeClusterServices(plan Plan) error
+	RotateCertificates(plan Plan) error
+	RotateEncryptionKey(plan Plan) error
 }
 
 // DiagnosticsExecutor will run diagnostics on the nodes after an install
@@ -53,7 +61,7 @@ type ExecutorOptions struct {
 	// RestartServices determines whether the cluster services should be
 	// restarted during the installation.
 	RestartServices bool
-	// OutputFormat sets the format of the executor
+	// OutputFormat sets the format of the executor (options "simple", "raw" or "json")
 	OutputFormat string
 	// Verbose output from the executor
 	Verbose bool
@@ -61,8 +69,13 @@ type ExecutorOptions struct {
 	RunsDirectory string
 	// DiagnosticsDirecty is where the doDiagnostics information about the cluster will be dumped
 	DiagnosticsDirecty string
-	// DryRun determines if the executor should actually run the task
+	// DryRun determines if the executor should actually run the task. When set,
+	// the inventory, cluster catalog and a summary of each task are written to
+	// the run directory instead.
 	DryRun bool
+	// Resume continues the installation from the first play that did not
+	// complete during the previous installation run
+	Resume bool
 }
 
 // NewExecutor returns an executor for performing installations according to the installation plan.
@@ -80,20 +93,22 @@ func NewExecutor(stdout io.Writer, errOut io.Writer, options ExecutorOptions) (E
 	switch options.OutputFormat {
 	case "raw":
 		outFormat = ansible.RawFormat
-	case "simple":
+	case "simple", "json":
 		outFormat = ansible.JSONLinesFormat
 	default:
 		return nil, fmt.Errorf("Output format %q is not supported", options.OutputFormat)
 	}
+	msgOut := util.MessageWriter(stdout, errOut, options.OutputFormat)
 	certsDir := filepath.Join(options.GeneratedAssetsDirectory, "keys")
 	pki := &LocalPKI{
 		CACsr: filepath.Join(ansibleDir, "playbooks", "tls", "ca-csr.json"),
 		GeneratedCertsDirectory: certsDir,
-		Log: stdout,
+		Log: msgOut,
 	}
 	return &ansibleExecutor{
 		options:             options,
-		stdout:              stdout,
+		stdout:              msgOut,
+		eventsOut:           stdout,
 		consoleOutputFormat: outFormat,
 		ansibleDir:          ansibleDir,
 		certsDir:            certsDir,
@@ -112,7 +127,7 @@ func NewPreFlightExecutor(stdout io.Writer, errOut io.Writer, options ExecutorOp
 	switch options.OutputFormat {
 	case "raw":
 		outFormat = ansible.RawFormat
-	case "simple":
+	case "simple", "json":
 		outFormat = ansible.JSONLinesFormat
 	default:
 		return nil, fmt.Errorf("Output format %q is not supported", options.OutputFormat)
@@ -120,7 +135,8 @@ func NewPreFlightExecutor(stdout io.Writer, errOut io.Writer, options ExecutorOp
 
 	return &ansibleExecutor{
 		options:             options,
-		stdout:              stdout,
+		stdout:              util.MessageWriter(stdout, errOut, options.OutputFormat),
+		eventsOut:           stdout,
 		consoleOutputFormat: outFormat,
 		ansibleDir:          ansibleDir,
 	}, nil
@@ -145,7 +161,7 @@ func NewDiagnosticsExecutor(stdout io.Writer, errOut io.Writer, options Executor
 	switch options.OutputFormat {
 	case "raw":
 		outFormat = ansible.RawFormat
-	case "simple":
+	case "simple", "json":
 		outFormat = ansible.JSONLinesFormat
 	default:
 		return nil, fmt.Errorf("Output format %q is not supported", options.OutputFormat)
@@ -153,7 +169,8 @@ func NewDiagnosticsExecutor(stdout io.Writer, errOut io.Writer, options Executor
 
 	return &ansibleExecutor{
 		options:             options,
-		stdout:              stdout,
+		stdout:              util.MessageWriter(stdout, errOut, options.OutputFormat),
+		eventsOut:           stdout,
 		consoleOutputFormat: outFormat,
 		ansibleDir:          ansibleDir,
 	}, nil
@@ -162,6 +179,7 @@ func NewDiagnosticsExecutor(stdout io.Writer, errOut io.Writer, options Executor
 type ansibleExecutor struct {
 	options             ExecutorOptions
 	stdout              io.Writer
+	eventsOut           io.Writer
 	consoleOutputFormat ansible.OutputFormat
 	ansibleDir          string
 	certsDir            string
@@ -186,33 +204,73 @@ type task struct {
 	plan Plan
 	// run the task on specific nodes
 	limit []string
+	// the position in the original playbook when resuming a previous run
+	resume *resumePoint
 }
 
 // execute will run the given task, and setup all what's needed for us to run ansible.
 func (ae *ansibleExecutor) execute(t task) error {
-	if ae.options.DryRun {
-		return nil
-	}
-	runDirectory, err := ae.createRunDirectory(t.name)
+	runDirectory, start, err := ae.createRunDirectory(t.name)
 	if err != nil {
 		return fmt.Errorf("error creating working directory for %q: %v", t.name, err)
 	}
+	// Record the details of the run, so that it can be inspected later
+	runRec := &runRecorder{
+		file: filepath.Join(runDirectory, runMetadataFilename),
+		meta: RunMetadata{
+			Operation: t.name,
+			Playbook:  t.playbook,
+			Limit:     t.limit,
+			Start:     start,
+			Result:    RunResultRunning,
+		},
+		dryRun: ae.options.DryRun,
+	}
+	if err = runRec.write(); err != nil {
+		return err
+	}
 	// Save the plan file that was used for this execution
 	fp := FilePlanner{
-		File: filepath.Join(runDirectory, "kismatic-cluster.yaml"),
+		File: filepath.Join(runDirectory, runPlanFilename),
 	}
 	if err = fp.Write(&t.plan); err != nil {
 		return fmt.Errorf("error recording plan file to %s: %v", fp.File, err)
 	}
+	// Record what the task would do, without running it
+	if ae.options.DryRun {
+		err = ae.dryRun(t, runDirectory)
+		if runErr := runRec.finish(err); runErr != nil {
+			util.PrettyPrintWarn(ae.stdout, "Failed to record the result of the run: %v\n", runErr)
+		}
+		return err
+	}
+	sshAuthSock, err := serveEncryptedSSHKeys(t.plan)
+	if err != nil {
+		return err
+	}
 	ansibleLogFilename := filepath.Join(runDirectory, "ansible.log")
 	ansibleLogFile, err := os.Create(ansibleLogFilename)
 	if err != nil {
 		return fmt.Errorf("error creating ansible log file %q: %v", ansibleLogFilename, err)
 	}
-	runner, explainer, err := ae.ansibleRunnerWithExplainer(t.explainer, ansibleLogFile, runDirectory)
+	runner, explainer, err := ae.ansibleRunnerWithExplainer(t.explainer, ansibleLogFile, runDirectory, sshAuthSock)
+	if err != nil {
+		return err
+	}
+
+	// Record the progress of the run, so that it can be resumed
+	hash, err := planHash(t.plan)
 	if err != nil {
 		return err
 	}
+	recorder := &checkpointRecorder{
+		file: filepath.Join(runDirectory, checkpointFilename),
+		cp:   checkpoint{Playbook: t.playbook, PlanHash: hash},
+	}
+	if t.resume != nil {
+		recorder.cp.Playbook = t.resume.playbook
+		recorder.cp.FirstPlay = t.resume.firstPlay
+	}
 
 	// Start running ansible with the given playbook
 	var eventStream <-chan ansible.Event
@@ -226,10 +284,17 @@ func (ae *ansibleExecutor) execute(t task) error {
 	}
 	// Ansible blocks until explainer starts reading from stream. Start
 	// explainer in a separate go routine
-	go explainer.Explain(eventStream)
+	go explainer.Explain(recorder.record(runRec.record(eventStream)))
 
 	// Wait until ansible exits
-	if err = runner.WaitPlaybook(); err != nil {
+	err = runner.WaitPlaybook()
+	if cpErr := recorder.finish(err); cpErr != nil {
+		util.PrettyPrintWarn(ae.stdout, "Failed to record the progress of the run: %v\n", cpErr)
+	}
+	if runErr := runRec.finish(err); runErr != nil {
+		util.PrettyPrintWarn(ae.stdout, "Failed to record the result of the run: %v\n", runErr)
+	}
+	if err != nil {
 		return fmt.Errorf("error running playbook: %v", err)
 	}
 	return nil
@@ -247,14 +312,14 @@ func (ae *ansibleExecutor) GenerateCertificates(p *Plan, useExistingCA bool) err
 	var caCert *tls.CA
 	var err error
 	if useExistingCA {
-		exists, err := ae.pki.CertificateAuthorityExists()
+		exists, err := ae.pki.CertificateAuthorityExists(p)
 		if err != nil {
 			return fmt.Errorf("error checking if CA exists: %v", err)
 		}
 		if !exists {
 			return errors.New("The Certificate Authority is required, but it was not found.")
 		}
-		caCert, err = ae.pki.GetClusterCA()
+		caCert, err = ae.pki.GetClusterCA(p)
 		if err != nil {
 			return fmt.Errorf("error reading CA certificate: %v", err)
 		}
@@ -287,14 +352,56 @@ func (ae *ansibleExecutor) Install(p *Plan) error {
 		name:           "apply",
 		playbook:       "kubernetes.yaml",
 		plan:           *p,
-		inventory:      buildInventoryFromPlan(p),
+		inventory:      buildInventoryFromPlan(p, ae.options.GeneratedAssetsDirectory),
 		clusterCatalog: *cc,
 		explainer:      ae.defaultExplainer(),
 	}
+	if ae.options.Resume {
+		resume, err := ae.resumePoint(t.name, *p)
+		if err == errNothingToResume {
+			util.PrettyPrintOk(ae.stdout, "The cluster was installed during the previous run, skipping installation")
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error resuming installation: %v", err)
+		}
+		playbook, skipped, err := writeResumePlaybook(filepath.Join(ae.ansibleDir, "playbooks"), resume.playbook, resume.firstPlay)
+		if err == errNothingToResume {
+			util.PrettyPrintOk(ae.stdout, "The cluster was installed during the previous run, skipping installation")
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error resuming installation: %v", err)
+		}
+		t.playbook = playbook
+		t.resume = &resumePoint{playbook: resume.playbook, firstPlay: skipped}
+		util.PrintHeader(ae.stdout, "Resuming Cluster Installation", '=')
+		return ae.execute(t)
+	}
 	util.PrintHeader(ae.stdout, "Installing Cluster", '=')
 	return ae.execute(t)
 }
 
+// resumePoint returns the position in the playbook from which the last run of the task
+// should be resumed. The plan must not have changed since the last run.
+func (ae *ansibleExecutor) resumePoint(taskName string, p Plan) (*resumePoint, error) {
+	cp, err := lastCheckpoint(ae.options.RunsDirectory, taskName)
+	if err != nil {
+		return nil, err
+	}
+	if cp.Complete {
+		return nil, errNothingToResume
+	}
+	hash, err := planHash(p)
+	if err != nil {
+		return nil, err
+	}
+	if hash != cp.PlanHash {
+		return nil, errors.New("the plan file has changed since the previous run, the installation cannot be resumed")
+	}
+	return &resumePoint{playbook: cp.Playbook, firstPlay: cp.nextPlay()}, nil
+}
+
 func (ae *ansibleExecutor) RunSmokeTest(p *Plan) error {
 	cc, err := ae.buildClusterCatalog(p)
 	if err != nil {
@@ -305,7 +412,7 @@ func (ae *ansibleExecutor) RunSmokeTest(p *Plan) error {
 		playbook:       "smoketest.yaml",
 		explainer:      ae.defaultExplainer(),
 		plan:           *p,
-		inventory:      buildInventoryFromPlan(p),
+		inventory:      buildInventoryFromPlan(p, ae.options.GeneratedAssetsDirectory),
 		clusterCatalog: *cc,
 	}
 	util.PrintHeader(ae.stdout, "Running Smoke Test", '=')
@@ -325,7 +432,7 @@ func (ae *ansibleExecutor) RunPreFlightCheck(p *Plan) error {
 	t := task{
 		name:           "preflight",
 		playbook:       "preflight.yaml",
-		inventory:      buildInventoryFromPlan(p),
+		inventory:      buildInventoryFromPlan(p, ae.options.GeneratedAssetsDirectory),
 		clusterCatalog: *cc,
 		explainer:      ae.preflightExplainer(),
 		plan:           *p,
@@ -335,6 +442,22 @@ func (ae *ansibleExecutor) RunPreFlightCheck(p *Plan) error {
 
 // RunNewWorkerPreFlightCheck runs the preflight checks against a new worker node
 func (ae *ansibleExecutor) RunNewWorkerPreFlightCheck(p Plan, node Node) error {
+	return ae.runNewNodePreFlightCheck("add-worker-preflight", addWorkerToPlan(p, node), node)
+}
+
+// RunNewMasterPreFlightCheck runs the preflight checks against a new master node
+func (ae *ansibleExecutor) RunNewMasterPreFlightCheck(p Plan, node Node) error {
+	return ae.runNewNodePreFlightCheck("add-master-preflight", addMasterToPlan(p, node), node)
+}
+
+// RunNewEtcdPreFlightCheck runs the preflight checks against a new etcd node
+func (ae *ansibleExecutor) RunNewEtcdPreFlightCheck(p Plan, node Node) error {
+	return ae.runNewNodePreFlightCheck("add-etcd-preflight", addEtcdToPlan(p, node), node)
+}
+
+// runNewNodePreFlightCheck runs the preflight checks against the new node,
+// which must already be part of the plan
+func (ae *ansibleExecutor) runNewNodePreFlightCheck(name string, p Plan, node Node) error {
 	cc, err := ae.buildClusterCatalog(&p)
 	if err != nil {
 		return err
@@ -343,12 +466,10 @@ func (ae *ansibleExecutor) RunNewWorkerPreFlightCheck(p Plan, node Node) error {
 	if err != nil {
 		return err
 	}
-	p.Worker.ExpectedCount++
-	p.Worker.Nodes = append(p.Worker.Nodes, node)
 	t := task{
-		name:           "add-worker-preflight",
+		name:           name,
 		playbook:       "preflight.yaml",
-		inventory:      buildInventoryFromPlan(&p),
+		inventory:      buildInventoryFromPlan(&p, ae.options.GeneratedAssetsDirectory),
 		clusterCatalog: *cc,
 		explainer:      ae.preflightExplainer(),
 		plan:           p,
@@ -358,7 +479,7 @@ func (ae *ansibleExecutor) RunNewWorkerPreFlightCheck(p Plan, node Node) error {
 }
 
 func (ae *ansibleExecutor) RunUpgradePreFlightCheck(p *Plan, node ListableNode) error {
-	inventory := buildInventoryFromPlan(p)
+	inventory := buildInventoryFromPlan(p, ae.options.GeneratedAssetsDirectory)
 	cc, err := ae.buildClusterCatalog(p)
 	if err != nil {
 		return err
@@ -393,7 +514,7 @@ func (ae *ansibleExecutor) RunPlay(playName string, p *Plan) error {
 	t := task{
 		name:           "step",
 		playbook:       playName,
-		inventory:      buildInventoryFromPlan(p),
+		inventory:      buildInventoryFromPlan(p, ae.options.GeneratedAssetsDirectory),
 		clusterCatalog: *cc,
 		explainer:      ae.defaultExplainer(),
 		plan:           *p,
@@ -444,7 +565,7 @@ func (ae *ansibleExecutor) AddVolume(plan *Plan, volume StorageVolume) error {
 		name:           "add-volume",
 		playbook:       "volume-add.yaml",
 		plan:           *plan,
-		inventory:      buildInventoryFromPlan(plan),
+		inventory:      buildInventoryFromPlan(plan, ae.options.GeneratedAssetsDirectory),
 		clusterCatalog: *cc,
 		explainer:      ae.defaultExplainer(),
 	}
@@ -465,7 +586,7 @@ func (ae *ansibleExecutor) DeleteVolume(plan *Plan, name string) error {
 		name:           "delete-volume",
 		playbook:       "volume-delete.yaml",
 		plan:           *plan,
-		inventory:      buildInventoryFromPlan(plan),
+		inventory:      buildInventoryFromPlan(plan, ae.options.GeneratedAssetsDirectory),
 		clusterCatalog: *cc,
 		explainer:      ae.defaultExplainer(),
 	}
@@ -544,7 +665,7 @@ func (ae *ansibleExecutor) UpgradeNodes(plan Plan, nodesToUpgrade []ListableNode
 }
 
 func (ae *ansibleExecutor) upgradeNodes(plan Plan, onlineUpgrade bool, nodes ...ListableNode) error {
-	inventory := buildInventoryFromPlan(&plan)
+	inventory := buildInventoryFromPlan(&plan, ae.options.GeneratedAssetsDirectory)
 	cc, err := ae.buildClusterCatalog(&plan)
 	if err != nil {
 		return err
@@ -575,7 +696,7 @@ func (ae *ansibleExecutor) upgradeNodes(plan Plan, onlineUpgrade bool, nodes ...
 }
 
 func (ae *ansibleExecutor) ValidateControlPlane(plan Plan) error {
-	inventory := buildInventoryFromPlan(&plan)
+	inventory := buildInventoryFromPlan(&plan, ae.options.GeneratedAssetsDirectory)
 	cc, err := ae.buildClusterCatalog(&plan)
 	if err != nil {
 		return err
@@ -592,7 +713,7 @@ func (ae *ansibleExecutor) ValidateControlPlane(plan Plan) error {
 }
 
 func (ae *ansibleExecutor) UpgradeClusterServices(plan Plan) error {
-	inventory := buildInventoryFromPlan(&plan)
+	inventory := buildInventoryFromPlan(&plan, ae.options.GeneratedAssetsDirectory)
 	cc, err := ae.buildClusterCatalog(&plan)
 	if err != nil {
 		return err
@@ -609,7 +730,7 @@ func (ae *ansibleExecutor) UpgradeClusterServices(plan Plan) error {
 }
 
 func (ae *ansibleExecutor) DiagnoseNodes(plan Plan) error {
-	inventory := buildInventoryFromPlan(&plan)
+	inventory := buildInventoryFromPlan(&plan, ae.options.GeneratedAssetsDirectory)
 	cc, err := ae.buildClusterCatalog(&plan)
 	if err != nil {
 		return err
@@ -655,11 +776,12 @@ func (ae *ansibleExecutor) buildClusterCatalog(p *Plan) (*ansible.ClusterCatalog
 		HTTPProxy:                    p.Cluster.Networking.HTTPProxy,
 		HTTPSProxy:                   p.Cluster.Networking.HTTPSProxy,
 		TargetVersion:                KismaticVersion.String(),
-		APIServerOptions:             p.Cluster.APIServerOptions.Overrides,
-		KubeControllerManagerOptions: p.Cluster.KubeControllerManagerOptions.Overrides,
-		KubeSchedulerOptions:         p.Cluster.KubeSchedulerOptions.Overrides,
-		KubeProxyOptions:             p.Cluster.KubeProxyOptions.Overrides,
-		KubeletOptions:               p.Cluster.KubeletOptions.Overrides,
+		APIServerOptions:             withFeatureGates(p.Cluster.APIServerOptions.Overrides, p.Cluster.FeatureGates),
+		KubeControllerManagerOptions: withFeatureGates(p.Cluster.KubeControllerManagerOptions.Overrides, p.Cluster.FeatureGates),
+		KubeSchedulerOptions:         withFeatureGates(p.Cluster.KubeSchedulerOptions.Overrides, p.Cluster.FeatureGates),
+		KubeProxyOptions:             withFeatureGates(p.Cluster.KubeProxyOptions.Overrides, p.Cluster.FeatureGates),
+		KubeletOptions:               withFeatureGates(p.Cluster.KubeletOptions.Overrides, p.Cluster.FeatureGates),
+		FeatureGates:                 featureGatesFlag(p.Cluster.FeatureGates),
 	}
 
 	cc.NoProxy = p.AllAddresses()
@@ -718,6 +840,49 @@ func (ae *ansibleExecutor) buildClusterCatalog(p *Plan) (*ansible.ClusterCatalog
 	cc.CloudProvider = p.Cluster.CloudProvider.Provider
 	cc.CloudConfig = p.Cluster.CloudProvider.Config
 
+	// API server audit logging
+	if audit := p.Cluster.APIServerOptions.Audit; audit != nil {
+		// the policy file is not written during a dry run
+		policyFile, err := auditPolicyFile(*audit, ae.options.GeneratedAssetsDirectory, !ae.options.DryRun)
+		if err != nil {
+			return nil, err
+		}
+		cc.APIServerAudit.Enabled = true
+		cc.APIServerAudit.PolicyFile = policyFile
+		cc.APIServerAudit.LogPath = audit.LogPath
+		if audit.LogMaxAge != nil {
+			cc.APIServerAudit.LogMaxAge = *audit.LogMaxAge
+		}
+		if audit.LogMaxBackups != nil {
+			cc.APIServerAudit.LogMaxBackups = *audit.LogMaxBackups
+		}
+		if audit.Webhook != nil {
+			cc.APIServerAudit.WebhookConfigFile = audit.Webhook.ConfigFile
+			cc.APIServerAudit.WebhookMode = audit.Webhook.Mode
+		}
+	}
+
+	// Secrets encryption at rest. The config file is generated when applying
+	// the plan, and is not required during a dry run.
+	if p.Cluster.SecretsEncryption != nil && p.Cluster.SecretsEncryption.Enabled {
+		configFile, err := encryptionConfigFile(ae.options.GeneratedAssetsDirectory, !ae.options.DryRun)
+		if err != nil {
+			return nil, err
+		}
+		cc.SecretsEncryptionConfig = configFile
+	}
+
+	// OIDC authentication
+	if p.Cluster.Authentication != nil && p.Cluster.Authentication.OIDC != nil {
+		oidc := p.Cluster.Authentication.OIDC
+		cc.APIServerOIDC.Enabled = true
+		cc.APIServerOIDC.IssuerURL = oidc.IssuerURL
+		cc.APIServerOIDC.ClientID = oidc.ClientID
+		cc.APIServerOIDC.UsernameClaim = oidc.UsernameClaim
+		cc.APIServerOIDC.GroupsClaim = oidc.GroupsClaim
+		cc.APIServerOIDC.CAFile = oidc.CAFile
+	}
+
 	// add_ons
 	cc.RunPodValidation = p.NetworkConfigured()
 	// CNI
@@ -778,22 +943,36 @@ func (ae *ansibleExecutor) buildClusterCatalog(p *Plan) (*ansible.ClusterCatalog
 	// setup kubelet node overrides
 	cc.KubeletNodeOptions = make(map[string]map[string]string)
 	for _, n := range p.GetUniqueNodes() {
-		cc.KubeletNodeOptions[n.Host] = n.KubeletOptions.Overrides
+		cc.KubeletNodeOptions[n.Host] = withFeatureGates(n.KubeletOptions.Overrides, p.Cluster.FeatureGates)
 	}
 
 	return &cc, nil
 }
 
-func (ae *ansibleExecutor) createRunDirectory(runName string) (string, error) {
+// createRunDirectory creates a new directory for the run. A counter is added
+// to the name of the directory if another run started at the same time, so
+// that runs never share a directory.
+func (ae *ansibleExecutor) createRunDirectory(runName string) (string, time.Time, error) {
 	start := time.Now()
-	runDirectory := filepath.Join(ae.options.RunsDirectory, runName, start.Format("2006-01-02-15-04-05"))
-	if err := os.MkdirAll(runDirectory, 0777); err != nil {
-		return "", fmt.Errorf("error creating directory: %v", err)
+	parent := filepath.Join(ae.options.RunsDirectory, runName)
+	if err := os.MkdirAll(parent, 0777); err != nil {
+		return "", start, fmt.Errorf("error creating directory: %v", err)
+	}
+	name := start.Format(runTimestampFormat)
+	runDirectory := filepath.Join(parent, name)
+	for i := 1; ; i++ {
+		err := os.Mkdir(runDirectory, 0777)
+		if err == nil {
+			return runDirectory, start, nil
+		}
+		if !os.IsExist(err) {
+			return "", start, fmt.Errorf("error creating directory: %v", err)
+		}
+		runDirectory = filepath.Join(parent, fmt.Sprintf("%s-%d", name, i))
 	}
-	return runDirectory, nil
 }
 
-func (ae *ansibleExecutor) ansibleRunnerWithExplainer(explainer explain.AnsibleEventExplainer, ansibleLog io.Writer, runDirectory string) (ansible.Runner, *explain.AnsibleEventStreamExplainer, error) {
+func (ae *ansibleExecutor) ansibleRunnerWithExplainer(explainer explain.AnsibleEventExplainer, ansibleLog io.Writer, runDirectory string, sshAuthSock string) (ansible.Runner, *explain.AnsibleEventStreamExplainer, error) {
 	if ae.runnerExplainerFactory != nil {
 		return ae.runnerExplainerFactory(explainer, ansibleLog)
 	}
@@ -808,7 +987,7 @@ func (ae *ansibleExecutor) ansibleRunnerWithExplainer(explainer explain.AnsibleE
 	}
 
 	// Send stdout and stderr to ansibleOut
-	runner, err := ansible.NewRunner(ansibleOut, ansibleOut, ae.ansibleDir, runDirectory)
+	runner, err := ansible.NewRunner(ansibleOut, ansibleOut, ae.ansibleDir, runDirectory, sshAuthSock)
 	if err != nil {
 		return nil, nil, fmt.Errorf("error creating ansible runner: %v", err)
 	}
@@ -821,6 +1000,9 @@ func (ae *ansibleExecutor) ansibleRunnerWithExplainer(explainer explain.AnsibleE
 }
 
 func (ae *ansibleExecutor) defaultExplainer() explain.AnsibleEventExplainer {
+	if ae.options.OutputFormat == "json" {
+		return explain.JSONExplainer(ae.eventsOut)
+	}
 	var out io.Writer
 	switch ae.consoleOutputFormat {
 	case ansible.JSONLinesFormat:
@@ -832,6 +1014,9 @@ func (ae *ansibleExecutor) defaultExplainer() explain.AnsibleEventExplainer {
 }
 
 func (ae *ansibleExecutor) preflightExplainer() explain.AnsibleEventExplainer {
+	if ae.options.OutputFormat == "json" {
+		return explain.JSONExplainer(ae.eventsOut)
+	}
 	var out io.Writer
 	switch ae.consoleOutputFormat {
 	case ansible.JSONLinesFormat:
@@ -842,29 +1027,29 @@ func (ae *ansibleExecutor) preflightExplainer() explain.AnsibleEventExplainer {
 	return explain.PreflightExplainer(ae.options.Verbose, out)
 }
 
-func buildInventoryFromPlan(p *Plan) ansible.Inventory {
+func buildInventoryFromPlan(p *Plan, generatedAssetsDir string) ansible.Inventory {
 	etcdNodes := []ansible.Node{}
 	for _, n := range p.Etcd.Nodes {
-		etcdNodes = append(etcdNodes, installNodeToAnsibleNode(&n, &p.Cluster.SSH))
+		etcdNodes = append(etcdNodes, installNodeToAnsibleNode(&n, &p.Cluster.SSH, generatedAssetsDir))
 	}
 	masterNodes := []ansible.Node{}
 	for _, n := range p.Master.Nodes {
-		masterNodes = append(masterNodes, installNodeToAnsibleNode(&n, &p.Cluster.SSH))
+		masterNodes = append(masterNodes, installNodeToAnsibleNode(&n, &p.Cluster.SSH, generatedAssetsDir))
 	}
 	workerNodes := []ansible.Node{}
 	for _, n := range p.Worker.Nodes {
-		workerNodes = append(workerNodes, installNodeToAnsibleNode(&n, &p.Cluster.SSH))
+		workerNodes = append(workerNodes, installNodeToAnsibleNode(&n, &p.Cluster.SSH, generatedAssetsDir))
 	}
 	ingressNodes := []ansible.Node{}
 	if p.Ingress.Nodes != nil {
 		for _, n := range p.Ingress.Nodes {
-			ingressNodes = append(ingressNodes, installNodeToAnsibleNode(&n, &p.Cluster.SSH))
+			ingressNodes = append(ingressNodes, installNodeToAnsibleNode(&n, &p.Cluster.SSH, generatedAssetsDir))
 		}
 	}
 	storageNodes := []ansible.Node{}
 	if p.Storage.Nodes != nil {
 		for _, n := range p.Storage.Nodes {
-			storageNodes = append(storageNodes, installNodeToAnsibleNode(&n, &p.Cluster.SSH))
+			storageNodes = append(storageNodes, installNodeToAnsibleNode(&n, &p.Cluster.SSH, generatedAssetsDir))
 		}
 	}
 
@@ -896,9 +1081,28 @@ func buildInventoryFromPlan(p *Plan) ansible.Inventory {
 	return inventory
 }
 
+// serveEncryptedSSHKeys serves the encrypted SSH keys of the plan with an SSH
+// agent, as Ansible cannot prompt for their passphrase. It returns the socket
+// of the agent, or an empty string if none of the keys are encrypted.
+func serveEncryptedSSHKeys(p Plan) (string, error) {
+	keys := []string{}
+	for _, n := range p.getAllNodes() {
+		keys = append(keys, p.Cluster.SSH.forNode(n).Key)
+	}
+	if b := p.Cluster.SSH.bastion(); b != nil {
+		keys = append(keys, b.Key)
+	}
+	sock, err := ssh.AgentSocket(keys...)
+	if err != nil {
+		return "", fmt.Errorf("error loading SSH keys: %v", err)
+	}
+	return sock, nil
+}
+
 // Converts plan node to ansible node
-func installNodeToAnsibleNode(n *Node, s *SSHConfig) ansible.Node {
-	return ansible.Node{
+func installNodeToAnsibleNode(n *Node, clusterSSH *SSHConfig, generatedAssetsDir string) ansible.Node {
+	s := clusterSSH.forNode(*n)
+	node := ansible.Node{
 		Host:          n.Host,
 		PublicIP:      n.IP,
 		InternalIP:    n.InternalIP,
@@ -906,6 +1110,11 @@ func installNodeToAnsibleNode(n *Node, s *SSHConfig) ansible.Node {
 		SSHUser:       s.User,
 		SSHPort:       s.Port,
 	}
+	if b := s.bastion(); b != nil {
+		node.SSHProxyCommand = b.ProxyCommand(s.knownHostsFile(generatedAssetsDir))
+	}
+	node.SSHKnownHostsFile = s.knownHostsFile(generatedAssetsDir)
+	return node
 }
 
 // Prepend each line of the incoming stream with a timestamp
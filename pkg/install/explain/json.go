@@ -0,0 +1,206 @@
+package explain
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/apprenda/kismatic/pkg/ansible"
+)
+
+// Types of the records written by the JSON explainer
+const (
+	PlaybookStartRecord = "playbook_start"
+	PlaybookEndRecord   = "playbook_end"
+	PlayStartRecord     = "play_start"
+	PlayEndRecord       = "play_end"
+	TaskStartRecord     = "task_start"
+	TaskEndRecord       = "task_end"
+	HostResultRecord    = "host_result"
+)
+
+// Status of a host result record
+const (
+	HostOK          = "ok"
+	HostFailed      = "failed"
+	HostIgnored     = "failed_ignored"
+	HostSkipped     = "skipped"
+	HostUnreachable = "unreachable"
+	HostRetry       = "retry"
+)
+
+// JSONRecord is a single line written by the JSON explainer
+type JSONRecord struct {
+	Time     time.Time `json:"time"`
+	Type     string    `json:"type"`
+	Playbook string    `json:"playbook,omitempty"`
+	Play     string    `json:"play,omitempty"`
+	Task     string    `json:"task,omitempty"`
+	// Handler is true when the task is a handler
+	Handler bool   `json:"handler,omitempty"`
+	Host    string `json:"host,omitempty"`
+	Status  string `json:"status,omitempty"`
+	Item    string `json:"item,omitempty"`
+	// Command, Message, Stdout and Stderr are reported by the ansible module that ran on the host
+	Command    []string `json:"command,omitempty"`
+	Message    string   `json:"message,omitempty"`
+	Stdout     string   `json:"stdout,omitempty"`
+	Stderr     string   `json:"stderr,omitempty"`
+	Attempts   int      `json:"attempts,omitempty"`
+	MaxRetries int      `json:"maxRetries,omitempty"`
+	// Duration in seconds. For host results, this is the time elapsed since the task started.
+	Duration float64 `json:"duration,omitempty"`
+}
+
+// JSONExplainer returns an explainer that writes a JSON record per line
+// for each playbook, play, task and host result
+func JSONExplainer(out io.Writer) AnsibleEventExplainer {
+	return &jsonExplainer{
+		encoder: json.NewEncoder(out),
+		now:     time.Now,
+	}
+}
+
+type jsonExplainer struct {
+	encoder *json.Encoder
+	now     func() time.Time
+
+	playbook      string
+	playbookStart time.Time
+	play          string
+	playStart     time.Time
+	task          string
+	handler       bool
+	taskStart     time.Time
+}
+
+// ExplainEvent writes the JSON records that correspond to the ansible event
+func (explainer *jsonExplainer) ExplainEvent(e ansible.Event) {
+	now := explainer.now()
+	switch event := e.(type) {
+	case *ansible.PlaybookStartEvent:
+		explainer.playbook = event.Name
+		explainer.playbookStart = now
+		explainer.write(JSONRecord{Time: now, Type: PlaybookStartRecord, Playbook: event.Name})
+	case *ansible.PlaybookEndEvent:
+		explainer.endPlay(now)
+		explainer.write(JSONRecord{
+			Time:     now,
+			Type:     PlaybookEndRecord,
+			Playbook: explainer.playbook,
+			Duration: seconds(now.Sub(explainer.playbookStart)),
+		})
+	case *ansible.PlayStartEvent:
+		explainer.endPlay(now)
+		explainer.play = event.Name
+		explainer.playStart = now
+		explainer.write(JSONRecord{Time: now, Type: PlayStartRecord, Playbook: explainer.playbook, Play: event.Name})
+	case *ansible.TaskStartEvent:
+		explainer.startTask(now, event.Name, false)
+	case *ansible.HandlerTaskStartEvent:
+		explainer.startTask(now, event.Name, true)
+	case *ansible.RunnerOKEvent:
+		explainer.writeResult(now, HostOK, event.Host, event.Result)
+	case *ansible.RunnerItemOKEvent:
+		explainer.writeResult(now, HostOK, event.Host, event.Result)
+	case *ansible.RunnerFailedEvent:
+		explainer.writeResult(now, failedStatus(event.IgnoreErrors), event.Host, event.Result)
+	case *ansible.RunnerItemFailedEvent:
+		explainer.writeResult(now, failedStatus(event.IgnoreErrors), event.Host, event.Result)
+	case *ansible.RunnerItemRetryEvent:
+		explainer.writeResult(now, HostRetry, event.Host, event.Result)
+	case *ansible.RunnerSkippedEvent:
+		explainer.writeResult(now, HostSkipped, event.Host, event.Result)
+	case *ansible.RunnerUnreachableEvent:
+		explainer.writeResult(now, HostUnreachable, event.Host, event.Result)
+	}
+}
+
+func (explainer *jsonExplainer) startTask(now time.Time, name string, handler bool) {
+	explainer.endTask(now)
+	explainer.task = name
+	explainer.handler = handler
+	explainer.taskStart = now
+	explainer.write(JSONRecord{
+		Time:     now,
+		Type:     TaskStartRecord,
+		Playbook: explainer.playbook,
+		Play:     explainer.play,
+		Task:     name,
+		Handler:  handler,
+	})
+}
+
+// endTask writes the end record of the task that is currently running, if any
+func (explainer *jsonExplainer) endTask(now time.Time) {
+	if explainer.task == "" {
+		return
+	}
+	explainer.write(JSONRecord{
+		Time:     now,
+		Type:     TaskEndRecord,
+		Playbook: explainer.playbook,
+		Play:     explainer.play,
+		Task:     explainer.task,
+		Handler:  explainer.handler,
+		Duration: seconds(now.Sub(explainer.taskStart)),
+	})
+	explainer.task = ""
+	explainer.handler = false
+}
+
+// endPlay writes the end record of the play that is currently running, if any
+func (explainer *jsonExplainer) endPlay(now time.Time) {
+	explainer.endTask(now)
+	if explainer.play == "" {
+		return
+	}
+	explainer.write(JSONRecord{
+		Time:     now,
+		Type:     PlayEndRecord,
+		Playbook: explainer.playbook,
+		Play:     explainer.play,
+		Duration: seconds(now.Sub(explainer.playStart)),
+	})
+	explainer.play = ""
+}
+
+func (explainer *jsonExplainer) writeResult(now time.Time, status, host string, result ansible.RunnerResult) {
+	r := JSONRecord{
+		Time:       now,
+		Type:       HostResultRecord,
+		Playbook:   explainer.playbook,
+		Play:       explainer.play,
+		Task:       explainer.task,
+		Handler:    explainer.handler,
+		Host:       host,
+		Status:     status,
+		Item:       result.Item,
+		Command:    result.Command,
+		Message:    result.Message,
+		Stdout:     result.Stdout,
+		Stderr:     result.Stderr,
+		Attempts:   result.Attempts,
+		MaxRetries: result.MaxRetries,
+	}
+	if explainer.task != "" {
+		r.Duration = seconds(now.Sub(explainer.taskStart))
+	}
+	explainer.write(r)
+}
+
+func (explainer *jsonExplainer) write(r JSONRecord) {
+	// There is nowhere to report a failure to write to the console
+	explainer.encoder.Encode(r)
+}
+
+func failedStatus(ignoreErrors bool) string {
+	if ignoreErrors {
+		return HostIgnored
+	}
+	return HostFailed
+}
+
+func seconds(d time.Duration) float64 {
+	return d.Seconds()
+}
@@ -0,0 +1,73 @@
+package explain
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/apprenda/kismatic/pkg/ansible"
+)
+
+func TestJSONExplainer(t *testing.T) {
+	out := &bytes.Buffer{}
+	start := time.Date(2017, 9, 27, 10, 0, 0, 0, time.UTC)
+	clock := start
+	explainer := &jsonExplainer{
+		encoder: json.NewEncoder(out),
+		now: func() time.Time {
+			clock = clock.Add(time.Second)
+			return clock
+		},
+	}
+
+	playbookStart := &ansible.PlaybookStartEvent{}
+	playbookStart.Name = "kubernetes.yaml"
+	playStart := &ansible.PlayStartEvent{}
+	playStart.Name = "etcd"
+	taskStart := &ansible.TaskStartEvent{}
+	taskStart.Name = "start etcd"
+	retry := &ansible.RunnerItemRetryEvent{}
+	retry.Host = "etcd01"
+	retry.Result.Attempts = 1
+	retry.Result.MaxRetries = 3
+	failed := &ansible.RunnerFailedEvent{}
+	failed.Host = "etcd01"
+	failed.Result.Message = "service failed to start"
+	failed.Result.Stderr = "error"
+	events := []ansible.Event{playbookStart, playStart, taskStart, retry, failed, &ansible.PlaybookEndEvent{}}
+	for _, e := range events {
+		explainer.ExplainEvent(e)
+	}
+
+	records := []JSONRecord{}
+	dec := json.NewDecoder(out)
+	for dec.More() {
+		r := JSONRecord{}
+		if err := dec.Decode(&r); err != nil {
+			t.Fatalf("error decoding record: %v", err)
+		}
+		records = append(records, r)
+	}
+	expectedTypes := []string{PlaybookStartRecord, PlayStartRecord, TaskStartRecord, HostResultRecord, HostResultRecord, TaskEndRecord, PlayEndRecord, PlaybookEndRecord}
+	if len(records) != len(expectedTypes) {
+		t.Fatalf("expected %d records, got %d: %+v", len(expectedTypes), len(records), records)
+	}
+	for i, r := range records {
+		if r.Type != expectedTypes[i] {
+			t.Errorf("record %d: expected type %q, got %q", i, expectedTypes[i], r.Type)
+		}
+		if r.Playbook != "kubernetes.yaml" {
+			t.Errorf("record %d: expected playbook to be set, got %q", i, r.Playbook)
+		}
+	}
+	if r := records[3]; r.Status != HostRetry || r.Attempts != 1 || r.MaxRetries != 3 || r.Task != "start etcd" {
+		t.Errorf("unexpected retry record: %+v", r)
+	}
+	if r := records[4]; r.Status != HostFailed || r.Message != "service failed to start" || r.Stderr != "error" || r.Duration != 2 {
+		t.Errorf("unexpected failure record: %+v", r)
+	}
+	if r := records[7]; r.Duration != 5 {
+		t.Errorf("expected playbook duration to be 5 seconds, got %v", r.Duration)
+	}
+}
@@ -0,0 +1,180 @@
+package install
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// kubernetesMinorVersion is the minor version of the Kubernetes release
+	// installed by KET
+	kubernetesMinorVersion = "v1.8"
+	featureGatesOption     = "feature-gates"
+)
+
+// knownFeatureGates lists the feature gates of the Kubernetes components,
+// for each Kubernetes minor version installed by KET
+var knownFeatureGates = map[string][]string{
+	"v1.8": {
+		// k8s.io/apiserver/pkg/util/feature
+		"AllAlpha",
+		// k8s.io/kubernetes/pkg/features
+		"Accelerators",
+		"AffinityInAnnotations",
+		"AppArmor",
+		"CPUManager",
+		"DynamicKubeletConfig",
+		"DynamicVolumeProvisioning",
+		"EnableEquivalenceClassCache",
+		"ExpandPersistentVolumes",
+		"ExperimentalCriticalPodAnnotation",
+		"ExperimentalHostUserNamespaceDefaulting",
+		"HugePages",
+		"LocalStorageCapacityIsolation",
+		"MountPropagation",
+		"PersistentLocalVolumes",
+		"PodPriority",
+		"RotateKubeletClientCertificate",
+		"RotateKubeletServerCertificate",
+		"ServiceNodeExclusion",
+		"SupportIPVSProxyMode",
+		"TaintBasedEvictions",
+		"TaintNodesByCondition",
+		// k8s.io/apiserver/pkg/features
+		"APIResponseCompression",
+		"AdvancedAuditing",
+		"Initializers",
+		"StreamingProxyRedirects",
+		// k8s.io/apiextensions-apiserver/pkg/features
+		"CustomResourceValidation",
+	},
+}
+
+// validateFeatureGates returns an error for each feature gate that is not
+// known for the Kubernetes version
+func validateFeatureGates(gates map[string]bool, version string) []error {
+	known := map[string]bool{}
+	for _, g := range knownFeatureGates[version] {
+		known[g] = true
+	}
+	errs := []error{}
+	for _, g := range sortedFeatureGates(gates) {
+		if !known[g] {
+			errs = append(errs, fmt.Errorf("Feature gate %q is not a known feature gate of Kubernetes %s", g, version))
+		}
+	}
+	return errs
+}
+
+// featureGatesOverride is the feature gates option set in the overrides of a component
+type featureGatesOverride struct {
+	component string
+	value     string
+}
+
+// featureGatesOverrides returns the feature gates options set in the
+// overrides of the components
+func featureGatesOverrides(c Cluster) []featureGatesOverride {
+	overrides := []struct {
+		component string
+		options   map[string]string
+	}{
+		{"Kube ApiServer", c.APIServerOptions.Overrides},
+		{"Kube Controller Manager", c.KubeControllerManagerOptions.Overrides},
+		{"Kube Scheduler", c.KubeSchedulerOptions.Overrides},
+		{"Kube Proxy", c.KubeProxyOptions.Overrides},
+		{"Kubelet", c.KubeletOptions.Overrides},
+	}
+	found := []featureGatesOverride{}
+	for _, o := range overrides {
+		if value, ok := o.options[featureGatesOption]; ok {
+			found = append(found, featureGatesOverride{component: o.component, value: value})
+		}
+	}
+	return found
+}
+
+// validateFeatureGatesOverride returns an error for each feature gate that is
+// set both in the cluster feature gates and in the feature gates option of the component
+func validateFeatureGatesOverride(gates map[string]bool, o featureGatesOverride) []error {
+	overridden, err := parseFeatureGates(o.value)
+	if err != nil {
+		return []error{fmt.Errorf("%s Option %q is not valid: %v", o.component, featureGatesOption, err)}
+	}
+	errs := []error{}
+	for _, g := range sortedFeatureGates(overridden) {
+		if _, ok := gates[g]; ok {
+			errs = append(errs, fmt.Errorf("Feature gate %q is set in feature_gates and in the %s Option %q", g, o.component, featureGatesOption))
+		}
+	}
+	return errs
+}
+
+// parseFeatureGates parses the value of the feature gates option, e.g.
+// "AppArmor=false,PodPriority=true"
+func parseFeatureGates(value string) (map[string]bool, error) {
+	gates := map[string]bool{}
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("missing bool value for feature gate %q", pair)
+		}
+		enabled, err := strconv.ParseBool(strings.TrimSpace(kv[1]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q for feature gate %q", kv[1], kv[0])
+		}
+		gates[strings.TrimSpace(kv[0])] = enabled
+	}
+	return gates, nil
+}
+
+// withFeatureGates returns the overrides of a component, where the feature
+// gates option also includes the cluster feature gates. The overrides are
+// returned unchanged when they don't set the feature gates option, as the
+// cluster feature gates are the default value of the option.
+func withFeatureGates(overrides map[string]string, gates map[string]bool) map[string]string {
+	value, ok := overrides[featureGatesOption]
+	if !ok || len(gates) == 0 {
+		return overrides
+	}
+	merged := map[string]bool{}
+	for g, enabled := range gates {
+		merged[g] = enabled
+	}
+	// invalid values are caught when validating the plan
+	overridden, _ := parseFeatureGates(value)
+	for g, enabled := range overridden {
+		merged[g] = enabled
+	}
+	result := make(map[string]string, len(overrides))
+	for k, v := range overrides {
+		result[k] = v
+	}
+	result[featureGatesOption] = featureGatesFlag(merged)
+	return result
+}
+
+// featureGatesFlag returns the value of the feature gates option of the
+// Kubernetes components, e.g. "AppArmor=false,PodPriority=true"
+func featureGatesFlag(gates map[string]bool) string {
+	pairs := []string{}
+	for _, g := range sortedFeatureGates(gates) {
+		pairs = append(pairs, fmt.Sprintf("%s=%t", g, gates[g]))
+	}
+	return strings.Join(pairs, ",")
+}
+
+func sortedFeatureGates(gates map[string]bool) []string {
+	names := make([]string, 0, len(gates))
+	for g := range gates {
+		names = append(names, g)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,130 @@
+package install
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFeatureGatesFlag(t *testing.T) {
+	tests := []struct {
+		gates    map[string]bool
+		expected string
+	}{
+		{
+			gates:    nil,
+			expected: "",
+		},
+		{
+			gates:    map[string]bool{"PodPriority": true},
+			expected: "PodPriority=true",
+		},
+		{
+			gates:    map[string]bool{"PodPriority": true, "AppArmor": false, "HugePages": true},
+			expected: "AppArmor=false,HugePages=true,PodPriority=true",
+		},
+	}
+	for _, test := range tests {
+		if flag := featureGatesFlag(test.gates); flag != test.expected {
+			t.Errorf("expected %q, but got %q", test.expected, flag)
+		}
+	}
+}
+
+func TestValidateFeatureGates(t *testing.T) {
+	errs := validateFeatureGates(map[string]bool{"PodPriority": true, "AppArmor": false}, kubernetesMinorVersion)
+	if len(errs) != 0 {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+	errs = validateFeatureGates(map[string]bool{"PodPriority": true, "NotAFeature": true}, kubernetesMinorVersion)
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "NotAFeature") {
+		t.Errorf("expected an error for the unknown feature gate, but got: %v", errs)
+	}
+	// Feature gates are unknown for the versions that are not in the table
+	errs = validateFeatureGates(map[string]bool{"PodPriority": true}, "v0.1")
+	if len(errs) != 1 {
+		t.Errorf("expected an error for the unknown version, but got: %v", errs)
+	}
+}
+
+func TestValidateFeatureGatesOverrides(t *testing.T) {
+	p := validPlan
+	p.Cluster.FeatureGates = map[string]bool{"PodPriority": true}
+	if ok, errs := p.validate(); !ok {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	// Gates that are not set in feature_gates can be set in the overrides
+	p.Cluster.KubeletOptions.Overrides = map[string]string{"feature-gates": "CPUManager=true"}
+	if ok, errs := p.validate(); !ok {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	p.Cluster.KubeSchedulerOptions.Overrides = map[string]string{"feature-gates": "PodPriority=false"}
+	p.Cluster.KubeletOptions.Overrides = map[string]string{"feature-gates": "CPUManager=true,PodPriority=true"}
+	ok, errs := p.validate()
+	if ok {
+		t.Fatal("expected feature gates overrides to be invalid when the gate is set in feature_gates")
+	}
+	if len(errs) != 2 || !strings.Contains(errs[0].Error(), "Kube Scheduler") || !strings.Contains(errs[1].Error(), "Kubelet") {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+
+	p.Cluster.KubeSchedulerOptions.Overrides = nil
+	p.Cluster.KubeletOptions.Overrides = map[string]string{"feature-gates": "CPUManager"}
+	ok, errs = p.validate()
+	if ok {
+		t.Fatal("expected feature gates override without a value to be invalid")
+	}
+
+	p.Cluster.KubeletOptions.Overrides = nil
+	p.Worker.Nodes = []Node{
+		{
+			Host:           "worker01",
+			IP:             "192.168.205.12",
+			KubeletOptions: KubeletOptions{Overrides: map[string]string{"feature-gates": "PodPriority=true"}},
+		},
+	}
+	ok, errs = p.validate()
+	if ok {
+		t.Fatal("expected node feature gates overrides to be invalid when the gate is set in feature_gates")
+	}
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "worker01") {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+
+	// Feature gates overrides are allowed when feature_gates is not set
+	p.Cluster.FeatureGates = nil
+	if ok, errs := p.validate(); !ok {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+}
+
+func TestParseFeatureGates(t *testing.T) {
+	gates, err := parseFeatureGates("AppArmor=false, PodPriority=true,")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertEqual(t, gates, map[string]bool{"AppArmor": false, "PodPriority": true})
+
+	for _, value := range []string{"PodPriority", "PodPriority=yes"} {
+		if _, err := parseFeatureGates(value); err == nil {
+			t.Errorf("expected an error parsing %q", value)
+		}
+	}
+}
+
+func TestWithFeatureGates(t *testing.T) {
+	gates := map[string]bool{"PodPriority": true}
+	overrides := map[string]string{"v": "3"}
+	if merged := withFeatureGates(overrides, gates); !reflect.DeepEqual(merged, overrides) {
+		t.Errorf("expected overrides without feature gates to be unchanged, but got %v", merged)
+	}
+
+	overrides = map[string]string{"v": "3", "feature-gates": "CPUManager=true"}
+	merged := withFeatureGates(overrides, gates)
+	assertEqual(t, merged, map[string]string{"v": "3", "feature-gates": "CPUManager=true,PodPriority=true"})
+	if overrides["feature-gates"] != "CPUManager=true" {
+		t.Errorf("the overrides of the plan were modified: %v", overrides)
+	}
+}
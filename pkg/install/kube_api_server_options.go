@@ -36,6 +36,11 @@ func (options *APIServerOptions) validate() (bool, []error) {
 	if len(overrides) > 0 {
 		v.addError(fmt.Errorf("Kube ApiServer Option(s) [%v] cannot be overridden", strings.Join(overrides, ", ")))
 	}
+	v.addError(validateOptionOverrides("Kube ApiServer", options.Overrides, knownComponentFlags[kubernetesMinorVersion].apiServer)...)
+
+	if options.Audit != nil {
+		v.validate(options.Audit)
+	}
 
 	return v.valid()
 }
@@ -20,7 +20,7 @@ func TestValidateKubeApiServerOptions(t *testing.T) {
 		{
 			opts: APIServerOptions{
 				Overrides: map[string]string{
-					"foobar": "baz",
+					"event-ttl": "2h0m0s",
 				},
 			},
 			valid: true,
@@ -29,6 +29,7 @@ func (options *KubeControllerManagerOptions) validate() (bool, []error) {
 	if len(overrides) > 0 {
 		v.addError(fmt.Errorf("Kube Controller Manager Option(s) [%v] cannot be overridden", strings.Join(overrides, ", ")))
 	}
+	v.addError(validateOptionOverrides("Kube Controller Manager", options.Overrides, knownComponentFlags[kubernetesMinorVersion].controllerManager)...)
 
 	return v.valid()
 }
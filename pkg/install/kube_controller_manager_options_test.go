@@ -19,7 +19,7 @@ func TestValidateKubeControllerManagerOptions(t *testing.T) {
 		{
 			opts: KubeControllerManagerOptions{
 				Overrides: map[string]string{
-					"foobar": "baz",
+					"node-monitor-period": "5s",
 				},
 			},
 			valid: true,
@@ -24,6 +24,7 @@ func (options *KubeProxyOptions) validate() (bool, []error) {
 	if len(overrides) > 0 {
 		v.addError(fmt.Errorf("Kube Proxy Option(s) [%v] cannot be overridden", strings.Join(overrides, ", ")))
 	}
+	v.addError(validateOptionOverrides("Kube Proxy", options.Overrides, knownComponentFlags[kubernetesMinorVersion].proxy)...)
 
 	return v.valid()
 }
@@ -19,7 +19,7 @@ func TestValidateKubeProxyOptions(t *testing.T) {
 		{
 			opts: KubeProxyOptions{
 				Overrides: map[string]string{
-					"foobar": "baz",
+					"masquerade-all": "true",
 				},
 			},
 			valid: true,
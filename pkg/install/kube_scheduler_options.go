@@ -22,6 +22,7 @@ func (options *KubeSchedulerOptions) validate() (bool, []error) {
 	if len(overrides) > 0 {
 		v.addError(fmt.Errorf("Kube Scheduler Option(s) [%v] cannot be overridden", strings.Join(overrides, ", ")))
 	}
+	v.addError(validateOptionOverrides("Kube Scheduler", options.Overrides, knownComponentFlags[kubernetesMinorVersion].scheduler)...)
 
 	return v.valid()
 }
@@ -19,7 +19,7 @@ func TestValidateKubeSchedulerOptions(t *testing.T) {
 		{
 			opts: KubeSchedulerOptions{
 				Overrides: map[string]string{
-					"foobar": "baz",
+					"algorithm-provider": "DefaultProvider",
 				},
 			},
 			valid: true,
@@ -7,12 +7,17 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"regexp"
 
 	"github.com/apprenda/kismatic/pkg/util"
 )
 
 const kubeconfigFilename = "kubeconfig"
 
+// userNameRegexp matches the user names that are safe to use in file names
+// and in the commands run on the cluster
+var userNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._@-]*$`)
+
 // ConfigOptions sds
 type ConfigOptions struct {
 	CA      string
@@ -45,27 +50,89 @@ users:
     client-key-data: {{.Key}}
 `
 
-// GenerateKubeconfig generate a kubeconfig file for a specific user
+// GenerateKubeconfig generate a kubeconfig file for a specific user. A kubeconfig
+// file template for the OIDC users is also generated when OIDC is configured.
 func GenerateKubeconfig(p *Plan, generatedAssetsDir string) error {
-	user := "admin"
+	certsDir := filepath.Join(generatedAssetsDir, "keys")
+	kubeconfigFile := filepath.Join(generatedAssetsDir, kubeconfigFilename)
+	if err := writeKubeconfig(p, certsDir, adminUser, adminCertFilename, kubeconfigFile); err != nil {
+		return err
+	}
+	if p.Cluster.Authentication != nil && p.Cluster.Authentication.OIDC != nil {
+		return generateOIDCKubeconfig(p, certsDir, OIDCKubeconfigFile(generatedAssetsDir))
+	}
+	return nil
+}
+
+// UserKubeconfigFile returns the path of the kubeconfig file of the user in
+// the generated assets directory
+func UserKubeconfigFile(generatedAssetsDir, user string) string {
+	return filepath.Join(generatedAssetsDir, kubeconfigFilename+"-"+user)
+}
+
+// GenerateUserKubeconfig issues a client certificate for the user that is
+// signed by the cluster CA, using the groups as the organizations of the
+// certificate. A kubeconfig file for the user is then written to the generated
+// assets directory. The certificate is valid for the expiry duration, or for
+// the expiry of the cluster certificates when empty. Existing certificates and
+// kubeconfig files are only replaced when overwrite is true.
+func GenerateUserKubeconfig(p *Plan, pki PKI, generatedAssetsDir string, user string, groups []string, expiry string, overwrite bool) error {
+	if !userNameRegexp.MatchString(user) {
+		return fmt.Errorf("%q is not a valid user name: it must start with an alphanumeric character, and contain only alphanumeric characters, '.', '_', '@' or '-'", user)
+	}
+	manifest, err := certManifestForCluster(*p)
+	if err != nil {
+		return fmt.Errorf("error getting the cluster certificates: %v", err)
+	}
+	if user == "ca" || certSpecFilenameInManifest(user, manifest) {
+		return fmt.Errorf("%q is the name of a cluster certificate and cannot be used as a user name", user)
+	}
+	if user == oidcUser {
+		return fmt.Errorf("%q is reserved for the kubeconfig file of the OIDC users and cannot be used as a user name", user)
+	}
+	if expiry == "" {
+		expiry = p.Cluster.Certificates.Expiry
+	}
+	kubeconfigFile := UserKubeconfigFile(generatedAssetsDir, user)
+	if _, err := os.Stat(kubeconfigFile); err == nil && !overwrite {
+		return fmt.Errorf("kubeconfig file %q already exists", kubeconfigFile)
+	}
+
+	ca, err := pki.GetClusterCA(p)
+	if err != nil {
+		return err
+	}
+	exists, err := pki.GenerateCertificate(p, user, expiry, user, nil, groups, ca, overwrite)
+	if err != nil {
+		return err
+	}
+	if exists && !overwrite {
+		return fmt.Errorf("a certificate for %q already exists", user)
+	}
+
+	certsDir := filepath.Join(generatedAssetsDir, "keys")
+	return writeKubeconfig(p, certsDir, user, user, kubeconfigFile)
+}
+
+// writeKubeconfig writes a kubeconfig file for the user, that authenticates
+// with the certificate found in the certificates directory
+func writeKubeconfig(p *Plan, certsDir string, user string, certName string, kubeconfigFile string) error {
 	server := "https://" + p.Master.LoadBalancedFQDN + ":6443"
 	cluster := p.Cluster.Name
 	context := p.Cluster.Name + "-" + user
 
-	certsDir := filepath.Join(generatedAssetsDir, "keys")
-
 	// Base64 encoded ca
-	caEncoded, err := util.Base64String(filepath.Join(certsDir, "ca.pem"))
+	caEncoded, err := util.Base64String(caBundleFile(certsDir))
 	if err != nil {
 		return fmt.Errorf("error reading ca file for kubeconfig: %v", err)
 	}
 	// Base64 encoded cert
-	certEncoded, err := util.Base64String(filepath.Join(certsDir, user+".pem"))
+	certEncoded, err := util.Base64String(filepath.Join(certsDir, certName+".pem"))
 	if err != nil {
 		return fmt.Errorf("error reading certificate file for kubeconfig: %v", err)
 	}
 	// Base64 encoded key
-	keyEncoded, err := util.Base64String(filepath.Join(certsDir, user+"-key.pem"))
+	keyEncoded, err := util.Base64String(filepath.Join(certsDir, certName+"-key.pem"))
 	if err != nil {
 		return fmt.Errorf("error reading certificate key file for kubeconfig: %v", err)
 	}
@@ -82,7 +149,6 @@ func GenerateKubeconfig(p *Plan, generatedAssetsDir string) error {
 		return fmt.Errorf("error processing config template: %v", err)
 	}
 	// Write config file
-	kubeconfigFile := filepath.Join(generatedAssetsDir, kubeconfigFilename)
 	err = ioutil.WriteFile(kubeconfigFile, kubeconfig.Bytes(), 0644)
 	if err != nil {
 		return fmt.Errorf("error writing kubeconfig file: %v", err)
@@ -0,0 +1,330 @@
+package install
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// KubeconfigEntryNames returns the names of the cluster, user and context
+// entries used when merging the kubeconfig of the user into another kubeconfig
+// file. The names are derived from the cluster name, so that the entries of
+// different clusters do not clash.
+func KubeconfigEntryNames(p *Plan, user string) (cluster, authInfo, context string) {
+	cluster = p.Cluster.Name
+	authInfo = p.Cluster.Name + "-" + user
+	context = p.Cluster.Name + "-" + user
+	return
+}
+
+// kubeconfigMergeRecord records the state of the target kubeconfig file that
+// is replaced by a merge, so that it can be restored when the merged entries
+// are removed
+type kubeconfigMergeRecord struct {
+	// CurrentContext is the current context before the merged context was set
+	// as the current context
+	CurrentContext string `yaml:"current-context,omitempty"`
+	// The entries of the target file that were replaced by the merged entries
+	Cluster yaml.MapSlice `yaml:"cluster,omitempty"`
+	User    yaml.MapSlice `yaml:"user,omitempty"`
+	Context yaml.MapSlice `yaml:"context,omitempty"`
+}
+
+// kubeconfigMergeRecordFile returns the path of the file in which the merge of
+// the context into the target file is recorded
+func kubeconfigMergeRecordFile(targetFile, context string) string {
+	return fmt.Sprintf("%s.%s.bak", targetFile, context)
+}
+
+// readKubeconfigMergeRecord returns the record of the merge, or nil if the
+// file does not exist
+func readKubeconfigMergeRecord(file string) (*kubeconfigMergeRecord, error) {
+	b, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading kubeconfig backup file: %v", err)
+	}
+	r := &kubeconfigMergeRecord{}
+	if err := yaml.Unmarshal(b, r); err != nil {
+		return nil, fmt.Errorf("error parsing kubeconfig backup file %q: %v", file, err)
+	}
+	return r, nil
+}
+
+func writeKubeconfigMergeRecord(r kubeconfigMergeRecord, file string) error {
+	b, err := yaml.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("error marshalling kubeconfig backup: %v", err)
+	}
+	// the replaced entries may contain credentials
+	if err := ioutil.WriteFile(file, b, 0600); err != nil {
+		return fmt.Errorf("error writing kubeconfig backup file: %v", err)
+	}
+	return nil
+}
+
+// MergeKubeconfig adds the cluster, user and context entries of the kubeconfig
+// file to the target kubeconfig file, which is created if it does not exist.
+// Entries of the target file with the same names are replaced, and all other
+// entries are left untouched. The merged context becomes the current context
+// when setCurrent is true. The replaced entries and current context are
+// recorded in a backup file next to the target file, and are restored by
+// RemoveMergedKubeconfig.
+func MergeKubeconfig(p *Plan, kubeconfigFile, targetFile, user string, setCurrent bool) error {
+	source, err := readKubeconfig(kubeconfigFile)
+	if err != nil {
+		return err
+	}
+	if source == nil {
+		return fmt.Errorf("kubeconfig file %q does not exist", kubeconfigFile)
+	}
+	target, err := readKubeconfig(targetFile)
+	if err != nil {
+		return err
+	}
+	if len(target) == 0 {
+		target = yaml.MapSlice{
+			{Key: "apiVersion", Value: "v1"},
+			{Key: "kind", Value: "Config"},
+			{Key: "preferences", Value: yaml.MapSlice{}},
+		}
+	}
+
+	clusterName, authInfoName, contextName := KubeconfigEntryNames(p, user)
+	cluster, err := singleKubeconfigEntry(source, "clusters")
+	if err != nil {
+		return fmt.Errorf("error reading %q: %v", kubeconfigFile, err)
+	}
+	authInfo, err := singleKubeconfigEntry(source, "users")
+	if err != nil {
+		return fmt.Errorf("error reading %q: %v", kubeconfigFile, err)
+	}
+	context, err := singleKubeconfigEntry(source, "contexts")
+	if err != nil {
+		return fmt.Errorf("error reading %q: %v", kubeconfigFile, err)
+	}
+	contextSpec, ok := mapSliceValue(context, "context").(yaml.MapSlice)
+	if !ok {
+		return fmt.Errorf("error reading %q: context %q is invalid", kubeconfigFile, kubeconfigEntryName(context))
+	}
+	contextSpec = setMapSliceValue(contextSpec, "cluster", clusterName)
+	contextSpec = setMapSliceValue(contextSpec, "user", authInfoName)
+
+	// The entries replaced by the first merge are recorded. The entries found
+	// by the next merges are the ones that were merged before.
+	recordFile := kubeconfigMergeRecordFile(targetFile, contextName)
+	record, err := readKubeconfigMergeRecord(recordFile)
+	if err != nil {
+		return err
+	}
+	if record == nil {
+		record = &kubeconfigMergeRecord{
+			Cluster: findKubeconfigEntry(target, "clusters", clusterName),
+			User:    findKubeconfigEntry(target, "users", authInfoName),
+			Context: findKubeconfigEntry(target, "contexts", contextName),
+		}
+	}
+	current, _ := mapSliceValue(target, "current-context").(string)
+	if setCurrent && current != contextName {
+		record.CurrentContext = current
+	}
+
+	target = putKubeconfigEntry(target, "clusters", setMapSliceValue(cluster, "name", clusterName))
+	target = putKubeconfigEntry(target, "users", setMapSliceValue(authInfo, "name", authInfoName))
+	target = putKubeconfigEntry(target, "contexts", setMapSliceValue(setMapSliceValue(context, "context", contextSpec), "name", contextName))
+	if setCurrent {
+		target = setMapSliceValue(target, "current-context", contextName)
+	}
+	if err := writeKubeconfigFile(target, targetFile); err != nil {
+		return err
+	}
+	return writeKubeconfigMergeRecord(*record, recordFile)
+}
+
+// RemoveMergedKubeconfig removes the entries added by MergeKubeconfig from the
+// target kubeconfig file, and restores the entries and the current context that
+// were replaced by the merge. Without a record of the merge, the cluster entry
+// is only removed when no other context refers to it, and the current context
+// is unset if it was the removed context.
+func RemoveMergedKubeconfig(p *Plan, targetFile, user string) error {
+	target, err := readKubeconfig(targetFile)
+	if err != nil {
+		return err
+	}
+	if target == nil {
+		return fmt.Errorf("kubeconfig file %q does not exist", targetFile)
+	}
+	clusterName, authInfoName, contextName := KubeconfigEntryNames(p, user)
+	recordFile := kubeconfigMergeRecordFile(targetFile, contextName)
+	record, err := readKubeconfigMergeRecord(recordFile)
+	if err != nil {
+		return err
+	}
+	if record == nil {
+		record = &kubeconfigMergeRecord{}
+	}
+	target = restoreKubeconfigEntry(target, "contexts", contextName, record.Context)
+	target = restoreKubeconfigEntry(target, "users", authInfoName, record.User)
+	if record.Cluster != nil {
+		target = putKubeconfigEntry(target, "clusters", record.Cluster)
+	} else {
+		clusterInUse := false
+		for _, c := range kubeconfigEntries(target, "contexts") {
+			if spec, ok := mapSliceValue(c, "context").(yaml.MapSlice); ok && mapSliceValue(spec, "cluster") == clusterName {
+				clusterInUse = true
+			}
+		}
+		if !clusterInUse {
+			target = deleteKubeconfigEntry(target, "clusters", clusterName)
+		}
+	}
+	if mapSliceValue(target, "current-context") == contextName {
+		if record.CurrentContext != "" {
+			target = setMapSliceValue(target, "current-context", record.CurrentContext)
+		} else if record.Context == nil {
+			target = setMapSliceValue(target, "current-context", "")
+		}
+	}
+	if err := writeKubeconfigFile(target, targetFile); err != nil {
+		return err
+	}
+	if err := os.Remove(recordFile); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error removing kubeconfig backup file: %v", err)
+	}
+	return nil
+}
+
+// restoreKubeconfigEntry replaces the entry of the list with the previous
+// entry, or removes it when there was no previous entry
+func restoreKubeconfigEntry(config yaml.MapSlice, list string, name string, previous yaml.MapSlice) yaml.MapSlice {
+	if previous != nil {
+		return putKubeconfigEntry(config, list, previous)
+	}
+	return deleteKubeconfigEntry(config, list, name)
+}
+
+// readKubeconfig returns the contents of the kubeconfig file, or nil if the
+// file does not exist
+func readKubeconfig(file string) (yaml.MapSlice, error) {
+	b, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading kubeconfig file: %v", err)
+	}
+	config := yaml.MapSlice{}
+	if err := yaml.Unmarshal(b, &config); err != nil {
+		return nil, fmt.Errorf("error parsing kubeconfig file %q: %v", file, err)
+	}
+	return config, nil
+}
+
+func writeKubeconfigFile(config yaml.MapSlice, file string) error {
+	b, err := yaml.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("error marshalling kubeconfig: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		return fmt.Errorf("error creating directory for kubeconfig file: %v", err)
+	}
+	if err := ioutil.WriteFile(file, b, 0600); err != nil {
+		return fmt.Errorf("error writing kubeconfig file: %v", err)
+	}
+	return nil
+}
+
+// singleKubeconfigEntry returns the only entry of the list, as found in the
+// kubeconfig files generated for a single user
+func singleKubeconfigEntry(config yaml.MapSlice, list string) (yaml.MapSlice, error) {
+	entries := kubeconfigEntries(config, list)
+	if len(entries) != 1 {
+		return nil, fmt.Errorf("expected a single entry in %s, but found %d", list, len(entries))
+	}
+	return entries[0], nil
+}
+
+// kubeconfigEntries returns the named entries of the list, e.g. clusters
+func kubeconfigEntries(config yaml.MapSlice, list string) []yaml.MapSlice {
+	items, _ := mapSliceValue(config, list).([]interface{})
+	entries := []yaml.MapSlice{}
+	for _, item := range items {
+		if e, ok := item.(yaml.MapSlice); ok {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// findKubeconfigEntry returns the entry of the list with the given name, or nil
+// if there is none
+func findKubeconfigEntry(config yaml.MapSlice, list string, name string) yaml.MapSlice {
+	for _, e := range kubeconfigEntries(config, list) {
+		if kubeconfigEntryName(e) == name {
+			return e
+		}
+	}
+	return nil
+}
+
+// putKubeconfigEntry replaces the entry of the list with the same name, or
+// appends the entry when there is none
+func putKubeconfigEntry(config yaml.MapSlice, list string, entry yaml.MapSlice) yaml.MapSlice {
+	items, _ := mapSliceValue(config, list).([]interface{})
+	for i, item := range items {
+		if e, ok := item.(yaml.MapSlice); ok && kubeconfigEntryName(e) == kubeconfigEntryName(entry) {
+			items[i] = entry
+			return setMapSliceValue(config, list, items)
+		}
+	}
+	return setMapSliceValue(config, list, append(items, entry))
+}
+
+// deleteKubeconfigEntry removes the entry with the given name from the list
+func deleteKubeconfigEntry(config yaml.MapSlice, list string, name string) yaml.MapSlice {
+	items, _ := mapSliceValue(config, list).([]interface{})
+	kept := []interface{}{}
+	for _, item := range items {
+		if e, ok := item.(yaml.MapSlice); ok && kubeconfigEntryName(e) == name {
+			continue
+		}
+		kept = append(kept, item)
+	}
+	return setMapSliceValue(config, list, kept)
+}
+
+func kubeconfigEntryName(entry yaml.MapSlice) string {
+	name, _ := mapSliceValue(entry, "name").(string)
+	return name
+}
+
+func mapSliceValue(m yaml.MapSlice, key string) interface{} {
+	for _, item := range m {
+		if item.Key == key {
+			return item.Value
+		}
+	}
+	return nil
+}
+
+// setMapSliceValue sets the value of the key, keeping the order of the keys
+func setMapSliceValue(m yaml.MapSlice, key string, value interface{}) yaml.MapSlice {
+	out := make(yaml.MapSlice, 0, len(m)+1)
+	found := false
+	for _, item := range m {
+		if item.Key == key {
+			item.Value = value
+			found = true
+		}
+		out = append(out, item)
+	}
+	if !found {
+		out = append(out, yaml.MapItem{Key: key, Value: value})
+	}
+	return out
+}
@@ -0,0 +1,181 @@
+package install
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+const existingKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- cluster:
+    server: https://other:6443
+  name: other
+contexts:
+- context:
+    cluster: other
+    user: other-user
+    namespace: dev
+  name: other
+current-context: other
+users:
+- name: other-user
+  user:
+    token: secret
+`
+
+func mustReadKubeconfig(t *testing.T, file string) yaml.MapSlice {
+	config, err := readKubeconfig(file)
+	if err != nil {
+		t.Fatalf("error reading kubeconfig: %v", err)
+	}
+	return config
+}
+
+func entryNames(config yaml.MapSlice, list string) []string {
+	names := []string{}
+	for _, e := range kubeconfigEntries(config, list) {
+		names = append(names, kubeconfigEntryName(e))
+	}
+	return names
+}
+
+func TestMergeKubeconfig(t *testing.T) {
+	path := createTempDirForRegenerateKubeconfigTests(t)
+	defer cleanup(path, t)
+	p := &Plan{}
+	p.Cluster.Name = "test"
+	p.Master.LoadBalancedFQDN = "test"
+	if err := GenerateKubeconfig(p, path); err != nil {
+		t.Fatalf("error generating kubeconfig: %v", err)
+	}
+	target := filepath.Join(path, "config")
+	if err := ioutil.WriteFile(target, []byte(existingKubeconfig), 0600); err != nil {
+		t.Fatalf("error writing kubeconfig: %v", err)
+	}
+
+	// Merging twice must not duplicate the entries
+	for i := 0; i < 2; i++ {
+		if err := MergeKubeconfig(p, filepath.Join(path, kubeconfigFilename), target, "admin", true); err != nil {
+			t.Fatalf("unexpected error merging kubeconfig: %v", err)
+		}
+	}
+	config := mustReadKubeconfig(t, target)
+	tests := []struct {
+		list     string
+		expected []string
+	}{
+		{"clusters", []string{"other", "test"}},
+		{"users", []string{"other-user", "test-admin"}},
+		{"contexts", []string{"other", "test-admin"}},
+	}
+	for _, test := range tests {
+		if names := entryNames(config, test.list); !equalStrings(names, test.expected) {
+			t.Errorf("expected %s %v, but got %v", test.list, test.expected, names)
+		}
+	}
+	if current := mapSliceValue(config, "current-context"); current != "test-admin" {
+		t.Errorf("expected current context test-admin, but got %v", current)
+	}
+	context := kubeconfigEntries(config, "contexts")[1]
+	spec := mapSliceValue(context, "context").(yaml.MapSlice)
+	if mapSliceValue(spec, "cluster") != "test" || mapSliceValue(spec, "user") != "test-admin" {
+		t.Errorf("merged context does not refer to the merged cluster and user: %v", spec)
+	}
+
+	if err := RemoveMergedKubeconfig(p, target, "admin"); err != nil {
+		t.Fatalf("unexpected error removing kubeconfig: %v", err)
+	}
+	config = mustReadKubeconfig(t, target)
+	for _, list := range []string{"clusters", "users", "contexts"} {
+		if names := entryNames(config, list); len(names) != 1 || names[0] == "test" || names[0] == "test-admin" {
+			t.Errorf("expected only the other entry in %s, but got %v", list, names)
+		}
+	}
+	if current := mapSliceValue(config, "current-context"); current != "other" {
+		t.Errorf("expected current context to be restored to other, but got %v", current)
+	}
+	other := mapSliceValue(kubeconfigEntries(config, "contexts")[0], "context").(yaml.MapSlice)
+	if mapSliceValue(other, "namespace") != "dev" {
+		t.Errorf("expected the other context to be untouched, but got %v", other)
+	}
+}
+
+func TestRemoveMergedKubeconfigRestoresReplacedEntries(t *testing.T) {
+	path := createTempDirForRegenerateKubeconfigTests(t)
+	defer cleanup(path, t)
+	p := &Plan{}
+	p.Cluster.Name = "other"
+	p.Master.LoadBalancedFQDN = "test"
+	if err := GenerateKubeconfig(p, path); err != nil {
+		t.Fatalf("error generating kubeconfig: %v", err)
+	}
+	target := filepath.Join(path, "config")
+	if err := ioutil.WriteFile(target, []byte(existingKubeconfig), 0600); err != nil {
+		t.Fatalf("error writing kubeconfig: %v", err)
+	}
+	original := mustReadKubeconfig(t, target)
+
+	// The cluster entry named after the cluster replaces the existing "other" cluster
+	for i := 0; i < 2; i++ {
+		if err := MergeKubeconfig(p, filepath.Join(path, kubeconfigFilename), target, "admin", true); err != nil {
+			t.Fatalf("unexpected error merging kubeconfig: %v", err)
+		}
+	}
+	cluster := kubeconfigEntries(mustReadKubeconfig(t, target), "clusters")[0]
+	if spec := mapSliceValue(cluster, "cluster").(yaml.MapSlice); mapSliceValue(spec, "server") == "https://other:6443" {
+		t.Fatalf("expected the other cluster to be replaced, but got %v", spec)
+	}
+
+	if err := RemoveMergedKubeconfig(p, target, "admin"); err != nil {
+		t.Fatalf("unexpected error removing kubeconfig: %v", err)
+	}
+	if restored := mustReadKubeconfig(t, target); !reflect.DeepEqual(restored, original) {
+		t.Errorf("expected the original kubeconfig to be restored, but got %v", restored)
+	}
+	if _, err := os.Stat(kubeconfigMergeRecordFile(target, "other-admin")); !os.IsNotExist(err) {
+		t.Errorf("expected the backup file to be removed")
+	}
+}
+
+func TestMergeKubeconfigNewFile(t *testing.T) {
+	path := createTempDirForRegenerateKubeconfigTests(t)
+	defer cleanup(path, t)
+	p := &Plan{}
+	p.Cluster.Name = "test"
+	p.Master.LoadBalancedFQDN = "test"
+	if err := GenerateKubeconfig(p, path); err != nil {
+		t.Fatalf("error generating kubeconfig: %v", err)
+	}
+	target := filepath.Join(path, ".kube", "config")
+	if err := MergeKubeconfig(p, filepath.Join(path, kubeconfigFilename), target, "admin", false); err != nil {
+		t.Fatalf("unexpected error merging kubeconfig: %v", err)
+	}
+	config := mustReadKubeconfig(t, target)
+	if mapSliceValue(config, "kind") != "Config" {
+		t.Errorf("expected kind Config, but got %v", mapSliceValue(config, "kind"))
+	}
+	if names := entryNames(config, "contexts"); !equalStrings(names, []string{"test-admin"}) {
+		t.Errorf("expected contexts [test-admin], but got %v", names)
+	}
+	if current := mapSliceValue(config, "current-context"); current != nil {
+		t.Errorf("expected no current context, but got %v", current)
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
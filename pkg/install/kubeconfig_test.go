@@ -4,7 +4,11 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/apprenda/kismatic/pkg/util"
 )
 
 func createTempDirForRegenerateKubeconfigTests(t *testing.T) string {
@@ -104,3 +108,60 @@ func TestRegenerateKubeconfigSameIsNotBackedUp(t *testing.T) {
 		t.Error("did not find expected kubeconfig file")
 	}
 }
+
+func TestGenerateUserKubeconfig(t *testing.T) {
+	path, err := ioutil.TempDir("", "user-kubeconfig")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer cleanup(path, t)
+	pki := &LocalPKI{
+		CACsr:                   "test/ca-csr.json",
+		GeneratedCertsDirectory: filepath.Join(path, "keys"),
+		Log:                     ioutil.Discard,
+	}
+	p := getPlan()
+	if _, err := pki.GenerateClusterCA(p); err != nil {
+		t.Fatalf("error generating CA for test: %v", err)
+	}
+
+	if err := GenerateUserKubeconfig(p, pki, path, "alice", []string{"dev", "ops"}, "720h", false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cert := mustReadCertFile(filepath.Join(path, "keys", "alice.pem"), t)
+	if cert.Subject.CommonName != "alice" {
+		t.Errorf("expected common name alice, but got %s", cert.Subject.CommonName)
+	}
+	if !util.Subset(cert.Subject.Organization, []string{"dev", "ops"}) {
+		t.Errorf("expected organizations to contain the groups, but got %v", cert.Subject.Organization)
+	}
+	if d := cert.NotAfter.Sub(time.Now()); d < 719*time.Hour || d > 721*time.Hour {
+		t.Errorf("expected the certificate to expire in 720h, but expires in %v", d)
+	}
+	kubeconfig, err := ioutil.ReadFile(UserKubeconfigFile(path, "alice"))
+	if err != nil {
+		t.Fatalf("error reading kubeconfig file: %v", err)
+	}
+	for _, s := range []string{"server: https://someFQDN:6443", "name: alice", "current-context: someName-alice"} {
+		if !strings.Contains(string(kubeconfig), s) {
+			t.Errorf("expected kubeconfig to contain %q, but got:\n%s", s, kubeconfig)
+		}
+	}
+
+	// The kubeconfig of an existing user is only replaced when overwriting
+	if err := GenerateUserKubeconfig(p, pki, path, "alice", nil, "720h", false); err == nil {
+		t.Error("expected an error when the kubeconfig already exists")
+	}
+	if err := GenerateUserKubeconfig(p, pki, path, "alice", nil, "720h", true); err != nil {
+		t.Errorf("unexpected error overwriting the kubeconfig: %v", err)
+	}
+}
+
+func TestGenerateUserKubeconfigInvalidUser(t *testing.T) {
+	pki := &LocalPKI{}
+	for _, user := range []string{"", "-alice", "alice bob", "alice/bob", "ca", "admin", "master01", "kube-proxy", "oidc"} {
+		if err := GenerateUserKubeconfig(getPlan(), pki, "generated", user, nil, "720h", false); err == nil {
+			t.Errorf("expected an error for user %q", user)
+		}
+	}
+}
@@ -37,6 +37,7 @@ func (options *KubeletOptions) validate() (bool, []error) {
 	if len(overrides) > 0 {
 		v.addError(fmt.Errorf("Kubelet Option(s) [%v] cannot be overridden", strings.Join(overrides, ", ")))
 	}
+	v.addError(validateOptionOverrides("Kubelet", options.Overrides, knownComponentFlags[kubernetesMinorVersion].kubelet)...)
 
 	return v.valid()
 }
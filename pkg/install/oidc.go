@@ -0,0 +1,109 @@
+package install
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/apprenda/kismatic/pkg/util"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const oidcUser = "oidc"
+
+func (a *Authentication) validate() (bool, []error) {
+	v := newValidator()
+	if a.OIDC != nil {
+		v.validate(a.OIDC)
+	}
+	return v.valid()
+}
+
+func (o *OIDC) validate() (bool, []error) {
+	v := newValidator()
+	u, err := url.Parse(o.IssuerURL)
+	if o.IssuerURL == "" {
+		v.addError(errors.New("OIDC issuer_url is required"))
+	} else if err != nil || u.Scheme != "https" || u.Host == "" {
+		v.addError(fmt.Errorf("OIDC issuer_url %q is not a valid https URL", o.IssuerURL))
+	}
+	if o.ClientID == "" {
+		v.addError(errors.New("OIDC client_id is required"))
+	}
+	if o.CAFile != "" {
+		if !filepath.IsAbs(o.CAFile) {
+			v.addError(errors.New("OIDC ca_file must be an absolute path"))
+		}
+		if _, err := os.Stat(o.CAFile); os.IsNotExist(err) {
+			v.addError(fmt.Errorf("OIDC CA file was not found at %q", o.CAFile))
+		}
+	}
+	return v.valid()
+}
+
+// OIDCKubeconfigFile returns the path to the kubeconfig file template for the
+// users that authenticate with OIDC
+func OIDCKubeconfigFile(generatedAssetsDir string) string {
+	return UserKubeconfigFile(generatedAssetsDir, oidcUser)
+}
+
+// generateOIDCKubeconfig writes a kubeconfig file for the users that
+// authenticate with OIDC. The tokens of the user are not included, and must
+// be set by the user, e.g. with "kubectl config set-credentials".
+func generateOIDCKubeconfig(p *Plan, certsDir string, kubeconfigFile string) error {
+	oidc := p.Cluster.Authentication.OIDC
+	caEncoded, err := util.Base64String(caBundleFile(certsDir))
+	if err != nil {
+		return fmt.Errorf("error reading ca file for kubeconfig: %v", err)
+	}
+	providerConfig := yaml.MapSlice{
+		{Key: "idp-issuer-url", Value: oidc.IssuerURL},
+		{Key: "client-id", Value: oidc.ClientID},
+	}
+	if oidc.CAFile != "" {
+		idpCAEncoded, err := util.Base64String(oidc.CAFile)
+		if err != nil {
+			return fmt.Errorf("error reading OIDC ca file for kubeconfig: %v", err)
+		}
+		providerConfig = append(providerConfig, yaml.MapItem{Key: "idp-certificate-authority-data", Value: idpCAEncoded})
+	}
+	context := p.Cluster.Name + "-" + oidcUser
+	config := yaml.MapSlice{
+		{Key: "apiVersion", Value: "v1"},
+		{Key: "clusters", Value: []interface{}{
+			yaml.MapSlice{
+				{Key: "cluster", Value: yaml.MapSlice{
+					{Key: "certificate-authority-data", Value: caEncoded},
+					{Key: "server", Value: "https://" + p.Master.LoadBalancedFQDN + ":6443"},
+				}},
+				{Key: "name", Value: p.Cluster.Name},
+			},
+		}},
+		{Key: "contexts", Value: []interface{}{
+			yaml.MapSlice{
+				{Key: "context", Value: yaml.MapSlice{
+					{Key: "cluster", Value: p.Cluster.Name},
+					{Key: "user", Value: oidcUser},
+				}},
+				{Key: "name", Value: context},
+			},
+		}},
+		{Key: "current-context", Value: context},
+		{Key: "kind", Value: "Config"},
+		{Key: "preferences", Value: yaml.MapSlice{}},
+		{Key: "users", Value: []interface{}{
+			yaml.MapSlice{
+				{Key: "name", Value: oidcUser},
+				{Key: "user", Value: yaml.MapSlice{
+					{Key: "auth-provider", Value: yaml.MapSlice{
+						{Key: "config", Value: providerConfig},
+						{Key: "name", Value: "oidc"},
+					}},
+				}},
+			},
+		}},
+	}
+	return writeKubeconfigFile(config, kubeconfigFile)
+}
@@ -0,0 +1,153 @@
+package install
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateOIDC(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "test-validate-oidc")
+	if err != nil {
+		t.Fatalf("error creating tmp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	caFile := filepath.Join(tmpDir, "oidc-ca.pem")
+	if err := ioutil.WriteFile(caFile, []byte("ca"), 0644); err != nil {
+		t.Fatalf("error writing CA file: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		oidc  OIDC
+		valid bool
+	}{
+		{
+			name:  "issuer and client ID",
+			oidc:  OIDC{IssuerURL: "https://accounts.example.com", ClientID: "kubernetes"},
+			valid: true,
+		},
+		{
+			name:  "all fields",
+			oidc:  OIDC{IssuerURL: "https://accounts.example.com/dex", ClientID: "kubernetes", UsernameClaim: "email", GroupsClaim: "groups", CAFile: caFile},
+			valid: true,
+		},
+		{
+			name: "no issuer",
+			oidc: OIDC{ClientID: "kubernetes"},
+		},
+		{
+			name: "http issuer",
+			oidc: OIDC{IssuerURL: "http://accounts.example.com", ClientID: "kubernetes"},
+		},
+		{
+			name: "issuer without host",
+			oidc: OIDC{IssuerURL: "https://", ClientID: "kubernetes"},
+		},
+		{
+			name: "no client ID",
+			oidc: OIDC{IssuerURL: "https://accounts.example.com"},
+		},
+		{
+			name: "missing CA file",
+			oidc: OIDC{IssuerURL: "https://accounts.example.com", ClientID: "kubernetes", CAFile: filepath.Join(tmpDir, "missing.pem")},
+		},
+		{
+			name: "relative CA file",
+			oidc: OIDC{IssuerURL: "https://accounts.example.com", ClientID: "kubernetes", CAFile: "oidc-ca.pem"},
+		},
+	}
+	for _, test := range tests {
+		ok, errs := test.oidc.validate()
+		if ok != test.valid {
+			t.Errorf("%s: expected valid to be %t, but got %t: %v", test.name, test.valid, ok, errs)
+		}
+	}
+}
+
+func TestValidateOIDCOptionOverrides(t *testing.T) {
+	p := getPlan()
+	p.Cluster.APIServerOptions.Overrides = map[string]string{
+		"oidc-issuer-url": "https://other.example.com",
+		"v":               "3",
+	}
+	p.Cluster.Authentication = &Authentication{
+		OIDC: &OIDC{IssuerURL: "https://accounts.example.com", ClientID: "kubernetes"},
+	}
+	ok, errs := p.Cluster.validate()
+	if ok {
+		t.Fatal("expected OIDC option overrides to be invalid when OIDC is configured")
+	}
+	found := false
+	for _, err := range errs {
+		if strings.Contains(err.Error(), "oidc-issuer-url") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected an error about the oidc-issuer-url override, but got: %v", errs)
+	}
+
+	// OIDC overrides are allowed when OIDC is not configured
+	p.Cluster.Authentication = nil
+	_, errs = p.Cluster.validate()
+	for _, err := range errs {
+		if strings.Contains(err.Error(), "oidc-issuer-url") {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+}
+
+func TestOIDCDefaults(t *testing.T) {
+	p := &Plan{}
+	p.Cluster.Authentication = &Authentication{OIDC: &OIDC{}}
+	setDefaults(p)
+	if p.Cluster.Authentication.OIDC.UsernameClaim != "sub" {
+		t.Errorf("expected username claim sub, but got %s", p.Cluster.Authentication.OIDC.UsernameClaim)
+	}
+}
+
+func TestGenerateKubeconfigWithOIDC(t *testing.T) {
+	path := createTempDirForRegenerateKubeconfigTests(t)
+	defer os.RemoveAll(path)
+	caFile := filepath.Join(path, "oidc-ca.pem")
+	if err := ioutil.WriteFile(caFile, []byte("oidc-ca"), 0644); err != nil {
+		t.Fatalf("error writing CA file: %v", err)
+	}
+
+	p := &Plan{}
+	p.Cluster.Name = "test"
+	p.Master.LoadBalancedFQDN = "test.example.com"
+	if err := GenerateKubeconfig(p, path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(OIDCKubeconfigFile(path)); !os.IsNotExist(err) {
+		t.Error("expected the OIDC kubeconfig to be generated only when OIDC is configured")
+	}
+
+	p.Cluster.Authentication = &Authentication{
+		OIDC: &OIDC{IssuerURL: "https://accounts.example.com", ClientID: "kubernetes", CAFile: caFile},
+	}
+	if err := GenerateKubeconfig(p, path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	kubeconfig, err := ioutil.ReadFile(OIDCKubeconfigFile(path))
+	if err != nil {
+		t.Fatalf("error reading OIDC kubeconfig file: %v", err)
+	}
+	expected := []string{
+		"server: https://test.example.com:6443",
+		"current-context: test-oidc",
+		"idp-issuer-url: https://accounts.example.com",
+		"client-id: kubernetes",
+		"idp-certificate-authority-data: b2lkYy1jYQ==",
+		"name: oidc",
+	}
+	for _, s := range expected {
+		if !strings.Contains(string(kubeconfig), s) {
+			t.Errorf("expected OIDC kubeconfig to contain %q, but got:\n%s", s, kubeconfig)
+		}
+	}
+}
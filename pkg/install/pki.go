@@ -13,6 +13,7 @@ import (
 	"github.com/apprenda/kismatic/pkg/tls"
 	"github.com/apprenda/kismatic/pkg/util"
 	"github.com/cloudflare/cfssl/csr"
+	"github.com/cloudflare/cfssl/helpers"
 )
 
 const (
@@ -31,17 +32,68 @@ const (
 	kubeletUserPrefix                   = "system:node"
 	kubeletGroup                        = "system:nodes"
 	contivProxyServerCertFilename       = "contiv-proxy-server"
+	caChainName                         = "ca-chain"
 )
 
+const (
+	keyAlgorithmRSA   = "rsa"
+	keyAlgorithmECDSA = "ecdsa"
+)
+
+// keyRequest returns the request for the private keys of the CA and the
+// cluster certificates, with the defaults applied
+func (c CertsConfig) keyRequest() *csr.BasicKeyRequest {
+	algo := c.KeyAlgorithm
+	if algo == "" {
+		algo = keyAlgorithmRSA
+	}
+	size := c.KeySize
+	if size == 0 {
+		size = 2048
+		if algo == keyAlgorithmECDSA {
+			size = 256
+		}
+	}
+	return &csr.BasicKeyRequest{A: algo, S: size}
+}
+
+// subject returns the subject fields of the CA and the cluster certificates,
+// or nil if they are not set
+func (c CertsConfig) subject() *tls.Subject {
+	if c.Subject == nil {
+		return nil
+	}
+	return &tls.Subject{
+		Country:            c.Subject.Country,
+		Locality:           c.Subject.Locality,
+		Organization:       c.Subject.Organization,
+		OrganizationalUnit: c.Subject.OrganizationalUnit,
+	}
+}
+
+// certSubject returns the subject fields of the certificate, or nil if they
+// are not set. Kubernetes reads the organizations of a client certificate as
+// the groups of the client, so the organization is only set on the other
+// certificates.
+func (c CertsConfig) certSubject(s certificateSpec) *tls.Subject {
+	subject := c.subject()
+	if subject != nil && s.client {
+		subject.Organization = ""
+	}
+	return subject
+}
+
 // The PKI provides a way for generating certificates for the cluster described by the Plan
 type PKI interface {
-	CertificateAuthorityExists() (bool, error)
+	CertificateAuthorityExists(p *Plan) (bool, error)
 	NodeCertificateExists(node Node) (bool, error)
 	GenerateNodeCertificate(plan *Plan, node Node, ca *tls.CA) error
-	GetClusterCA() (*tls.CA, error)
+	GetClusterCA(p *Plan) (*tls.CA, error)
 	GenerateClusterCA(p *Plan) (*tls.CA, error)
 	GenerateClusterCertificates(p *Plan, ca *tls.CA) error
-	GenerateCertificate(name string, validityPeriod string, commonName string, subjectAlternateNames []string, organizations []string, ca *tls.CA, overwrite bool) (bool, error)
+	GenerateCertificate(plan *Plan, name string, validityPeriod string, commonName string, subjectAlternateNames []string, organizations []string, ca *tls.CA, overwrite bool) (bool, error)
+	ArchiveNodeCertificates(plan *Plan, node Node) (string, error)
+	RotateClusterCertificates(plan *Plan, ca *tls.CA) (string, error)
 }
 
 // LocalPKI is a file-based PKI
@@ -57,12 +109,15 @@ type certificateSpec struct {
 	commonName            string
 	subjectAlternateNames []string
 	organizations         []string
+	// client is true when the certificate is used to authenticate with the cluster
+	client bool
 }
 
 func (s certificateSpec) equal(other certificateSpec) bool {
 	prelimEqual := s.description == other.description &&
 		s.filename == other.filename &&
 		s.commonName == other.commonName &&
+		s.client == other.client &&
 		len(s.subjectAlternateNames) == len(other.subjectAlternateNames) &&
 		len(s.organizations) == len(other.organizations)
 	if !prelimEqual {
@@ -150,12 +205,14 @@ func certManifestForNode(plan Plan, node Node) ([]certificateSpec, error) {
 			description: "kubernetes controller manager",
 			filename:    controllerManagerCertFilenamePrefix,
 			commonName:  controllerManagerUser,
+			client:      true,
 		})
 		// Scheduler client certificate
 		m = append(m, certificateSpec{
 			description: "kubernetes scheduler",
 			filename:    schedulerCertFilenamePrefix,
 			commonName:  schedulerUser,
+			client:      true,
 		})
 		// Certificate for signing service account tokens
 		m = append(m, certificateSpec{
@@ -172,12 +229,14 @@ func certManifestForNode(plan Plan, node Node) ([]certificateSpec, error) {
 			filename:      fmt.Sprintf("%s-kubelet", node.Host),
 			commonName:    fmt.Sprintf("%s:%s", kubeletUserPrefix, strings.ToLower(node.Host)),
 			organizations: []string{kubeletGroup},
+			client:        true,
 		})
 
 		m = append(m, certificateSpec{
 			description: "kube-proxy",
 			filename:    kubeProxyCertFilenamePrefix,
 			commonName:  kubeProxyUser,
+			client:      true,
 		})
 		// etcd client certificate
 		// all nodes need to be able to talk to etcd b/c of calico
@@ -185,6 +244,7 @@ func certManifestForNode(plan Plan, node Node) ([]certificateSpec, error) {
 			description: "etcd client",
 			filename:    "etcd-client",
 			commonName:  "etcd-client",
+			client:      true,
 		})
 	}
 
@@ -227,13 +287,18 @@ func certManifestForCluster(plan Plan) ([]certificateSpec, error) {
 		filename:      adminCertFilename,
 		commonName:    adminUser,
 		organizations: []string{adminGroup},
+		client:        true,
 	})
 
 	return m, nil
 }
 
-// CertificateAuthorityExists returns true if the CA for the cluster exists
-func (lp *LocalPKI) CertificateAuthorityExists() (bool, error) {
+// CertificateAuthorityExists returns true if the CA for the cluster exists.
+// The private key of the CA is not stored locally when using a remote signer.
+func (lp *LocalPKI) CertificateAuthorityExists(p *Plan) (bool, error) {
+	if p.Cluster.Certificates.signer() != nil {
+		return tls.CertExists("ca", lp.GeneratedCertsDirectory)
+	}
 	return tls.CertKeyPairExists("ca", lp.GeneratedCertsDirectory)
 }
 
@@ -243,7 +308,17 @@ func (lp *LocalPKI) NodeCertificateExists(node Node) (bool, error) {
 }
 
 // GetClusterCA returns the cluster CA
-func (lp *LocalPKI) GetClusterCA() (*tls.CA, error) {
+func (lp *LocalPKI) GetClusterCA(p *Plan) (*tls.CA, error) {
+	if rs := p.Cluster.Certificates.signer(); rs != nil {
+		cert, err := ioutil.ReadFile(filepath.Join(lp.GeneratedCertsDirectory, "ca.pem"))
+		if err != nil {
+			return nil, fmt.Errorf("error reading CA certificate: %v", err)
+		}
+		return &tls.CA{
+			Cert:   cert,
+			Signer: rs,
+		}, nil
+	}
 	key, cert, err := tls.ReadCACert("ca", lp.GeneratedCertsDirectory)
 	if err != nil {
 		return nil, fmt.Errorf("error reading CA certificate/key: %v", err)
@@ -260,13 +335,20 @@ func (lp *LocalPKI) GenerateClusterCA(p *Plan) (*tls.CA, error) {
 	if err != nil {
 		return nil, fmt.Errorf("error verifying CA certificate/key: %v", err)
 	}
+	if rs := p.Cluster.Certificates.signer(); rs != nil {
+		return lp.useRemoteSigner(p, rs)
+	}
+	if p.Cluster.Certificates.usesIntermediateCA() {
+		return lp.useIntermediateCA(p, exists)
+	}
 	if exists {
-		return lp.GetClusterCA()
+		return lp.GetClusterCA(p)
 	}
 
 	// CA keypair doesn't exist, generate one
 	util.PrettyPrintOk(lp.Log, "Generating cluster Certificate Authority")
-	key, cert, err := tls.NewCACert(lp.CACsr, p.Cluster.Name, p.Cluster.Certificates.CAExpiry)
+	c := p.Cluster.Certificates
+	key, cert, err := tls.NewCustomCACert(lp.CACsr, p.Cluster.Name, c.CAExpiry, c.keyRequest(), c.subject())
 	if err != nil {
 		return nil, fmt.Errorf("failed to create CA Cert: %v", err)
 	}
@@ -279,6 +361,94 @@ func (lp *LocalPKI) GenerateClusterCA(p *Plan) (*tls.CA, error) {
 	}, nil
 }
 
+// useIntermediateCA sets up the intermediate CA configured in the plan as the
+// cluster CA. The CA that exists in the generated certificates directory must be
+// the same intermediate CA, as the existing certificates were issued by it.
+// The intermediate CA alone is the trust anchor of the cluster components, while
+// its chain is written to a separate bundle that is used to verify the cluster.
+func (lp *LocalPKI) useIntermediateCA(p *Plan, exists bool) (*tls.CA, error) {
+	c := p.Cluster.Certificates
+	key, cert, bundle, err := tls.ReadIntermediateCA(c.IntermediateCACert, c.IntermediateCAKey, c.IntermediateCAChain)
+	if err != nil {
+		return nil, fmt.Errorf("invalid intermediate CA: %v", err)
+	}
+	if exists {
+		existing, err := tls.ReadCert("ca", lp.GeneratedCertsDirectory)
+		if err != nil {
+			return nil, fmt.Errorf("error reading CA certificate: %v", err)
+		}
+		intermediate, err := helpers.ParseCertificatePEM(cert)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing intermediate CA certificate: %v", err)
+		}
+		if !existing.Equal(intermediate) {
+			return nil, fmt.Errorf("the CA found in %q is not the intermediate CA %q", lp.GeneratedCertsDirectory, c.IntermediateCACert)
+		}
+	} else {
+		util.PrettyPrintOk(lp.Log, "Using intermediate Certificate Authority %q", c.IntermediateCACert)
+	}
+	// The CA files are written even if they exist, as the chain might have changed
+	if err = tls.WriteCert(key, cert, "ca", lp.GeneratedCertsDirectory); err != nil {
+		return nil, fmt.Errorf("error writing CA files: %v", err)
+	}
+	if err = tls.WriteCACert(bundle, caChainName, lp.GeneratedCertsDirectory); err != nil {
+		return nil, fmt.Errorf("error writing CA chain: %v", err)
+	}
+	return &tls.CA{
+		Cert: cert,
+		Key:  key,
+	}, nil
+}
+
+// caBundleFile returns the path to the certificate bundle used to verify the
+// certificates presented by the cluster. It is the CA chain when the cluster
+// certificates are issued by an intermediate CA, and the CA certificate otherwise.
+func caBundleFile(certsDir string) string {
+	chain := filepath.Join(certsDir, caChainName+".pem")
+	if _, err := os.Stat(chain); err == nil {
+		return chain
+	}
+	return filepath.Join(certsDir, "ca.pem")
+}
+
+// useRemoteSigner sets up the CA of the remote signer as the cluster CA. Only
+// the certificate of the CA is stored in the generated certificates directory.
+func (lp *LocalPKI) useRemoteSigner(p *Plan, rs *tls.RemoteSigner) (*tls.CA, error) {
+	cert, err := rs.Certificate()
+	if err != nil {
+		return nil, fmt.Errorf("error getting the CA certificate from the remote signer %q: %v", rs.URL, err)
+	}
+	exists, err := tls.CertExists("ca", lp.GeneratedCertsDirectory)
+	if err != nil {
+		return nil, fmt.Errorf("error verifying CA certificate: %v", err)
+	}
+	if exists {
+		existing, err := tls.ReadCert("ca", lp.GeneratedCertsDirectory)
+		if err != nil {
+			return nil, fmt.Errorf("error reading CA certificate: %v", err)
+		}
+		remote, err := helpers.ParseCertificatesPEM(cert)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing the CA certificate of the remote signer: %v", err)
+		}
+		if len(remote) == 0 {
+			return nil, fmt.Errorf("the remote signer %q did not return a CA certificate", rs.URL)
+		}
+		if !existing.Equal(remote[0]) {
+			return nil, fmt.Errorf("the CA found in %q is not the CA of the remote signer %q", lp.GeneratedCertsDirectory, rs.URL)
+		}
+		return lp.GetClusterCA(p)
+	}
+	util.PrettyPrintOk(lp.Log, "Using the Certificate Authority of the remote signer %q", rs.URL)
+	if err = tls.WriteCACert(cert, "ca", lp.GeneratedCertsDirectory); err != nil {
+		return nil, fmt.Errorf("error writing CA certificate: %v", err)
+	}
+	return &tls.CA{
+		Cert:   cert,
+		Signer: rs,
+	}, nil
+}
+
 // GenerateClusterCertificates creates all certificates required for the cluster
 // described in the plan file.
 func (lp *LocalPKI) GenerateClusterCertificates(p *Plan, ca *tls.CA) error {
@@ -327,7 +497,7 @@ func (lp *LocalPKI) GenerateClusterCertificates(p *Plan, ca *tls.CA) error {
 		}
 
 		// Cert doesn't exist. Generate it
-		if err := generateCert(ca, lp.GeneratedCertsDirectory, s, p.Cluster.Certificates.Expiry); err != nil {
+		if err := generateCert(ca, lp.GeneratedCertsDirectory, s, p.Cluster.Certificates.Expiry, p.Cluster.Certificates); err != nil {
 			return err
 		}
 		util.PrettyPrintOk(lp.Log, "Generated certificate for %s", s.description)
@@ -386,9 +556,92 @@ func (lp *LocalPKI) ValidateClusterCertificates(p *Plan) (warns []error, errs []
 			warns = append(warns, warn...)
 		}
 	}
+	// Certificates issued with a different key or subject configuration are
+	// still valid, so they do not prevent the installation from proceeding
+	outdated, err := lp.outdatedClusterCertificates(p)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	if len(outdated) > 0 {
+		util.PrettyPrintWarn(lp.Log, "Found certificates that do not match the key and subject configuration of the plan")
+		util.PrintValidationErrors(lp.Log, outdated)
+		fmt.Fprintln(lp.Log, "The certificates are not reissued automatically. Run \"kismatic certificates rotate\" to reissue them")
+	}
+	// The CA is never reissued, as all the certificates of the cluster are
+	// signed by it
+	outdatedCA, err := lp.outdatedCA(p)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	if len(outdatedCA) > 0 {
+		util.PrettyPrintWarn(lp.Log, "The cluster CA does not match the key and subject configuration of the plan")
+		util.PrintValidationErrors(lp.Log, outdatedCA)
+		fmt.Fprintln(lp.Log, "The CA is not reissued by \"kismatic certificates rotate\", and remains in use")
+	}
 	return warns, errs
 }
 
+// outdatedCA returns a list of warnings when the CA that was generated for the
+// cluster does not match the certificates configuration of the plan. CAs that
+// are provided with the plan are not validated.
+func (lp *LocalPKI) outdatedCA(p *Plan) ([]error, error) {
+	c := p.Cluster.Certificates
+	if c.signer() != nil || c.usesIntermediateCA() {
+		return nil, nil
+	}
+	exists, err := tls.CertKeyPairExists("ca", lp.GeneratedCertsDirectory)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, nil
+	}
+	return tls.CertKeyAndSubjectValid(c.keyRequest(), c.subject(), "ca", lp.GeneratedCertsDirectory)
+}
+
+// outdatedClusterCertificates returns a list of warnings for the existing cluster
+// certificates whose key or subject do not match the certificates configuration
+// of the plan
+func (lp *LocalPKI) outdatedClusterCertificates(p *Plan) ([]error, error) {
+	manifest, err := certManifestForCluster(*p)
+	if err != nil {
+		return nil, err
+	}
+	c := p.Cluster.Certificates
+	outdated := []error{}
+	for _, s := range manifest {
+		// The service account certificate is not reissued when rotating the
+		// certificates, as doing so would invalidate the service account tokens
+		if s.filename == serviceAccountCertFilename {
+			continue
+		}
+		exists, err := tls.CertKeyPairExists(s.filename, lp.GeneratedCertsDirectory)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			continue
+		}
+		warn, err := tls.CertKeyAndSubjectValid(c.keyRequest(), c.certSubject(s), s.filename, lp.GeneratedCertsDirectory)
+		if err != nil {
+			return nil, err
+		}
+		outdated = append(outdated, warn...)
+		// Client certificates issued with the organization of the subject
+		// belong to an unexpected group
+		if s.client && c.Subject != nil && c.Subject.Organization != "" && !contains(c.Subject.Organization, s.organizations) {
+			cert, err := tls.ReadCert(s.filename, lp.GeneratedCertsDirectory)
+			if err != nil {
+				return nil, fmt.Errorf("error reading cert %s: %v", s.filename, err)
+			}
+			if contains(c.Subject.Organization, cert.Subject.Organization) {
+				outdated = append(outdated, fmt.Errorf("Certificate \"%s.pem\": organization validation failed\n    expected no %q organization on a client certificate, instead got %v", s.filename, c.Subject.Organization, cert.Subject.Organization))
+			}
+		}
+	}
+	return outdated, nil
+}
+
 // GenerateNodeCertificate creates a private key and certificate for the given node
 func (lp *LocalPKI) GenerateNodeCertificate(plan *Plan, node Node, ca *tls.CA) error {
 	m, err := certManifestForNode(*plan, node)
@@ -415,7 +668,7 @@ func (lp *LocalPKI) GenerateNodeCertificate(plan *Plan, node Node, ca *tls.CA) e
 			continue
 		}
 		// Cert doesn't exist. Generate it
-		if err := generateCert(ca, lp.GeneratedCertsDirectory, s, plan.Cluster.Certificates.Expiry); err != nil {
+		if err := generateCert(ca, lp.GeneratedCertsDirectory, s, plan.Cluster.Certificates.Expiry, plan.Cluster.Certificates); err != nil {
 			return err
 		}
 		util.PrettyPrintOk(lp.Log, "Generated certificate for %s", s.description)
@@ -423,10 +676,89 @@ func (lp *LocalPKI) GenerateNodeCertificate(plan *Plan, node Node, ca *tls.CA) e
 	return nil
 }
 
+// ArchiveNodeCertificates moves the certificates that belong to the node
+// into an archive directory, and returns the path of the directory.
+// Certificates that are shared with other nodes are left untouched.
+func (lp *LocalPKI) ArchiveNodeCertificates(plan *Plan, node Node) (string, error) {
+	m, err := certManifestForNode(*plan, node)
+	if err != nil {
+		return "", err
+	}
+	// certificates of older versions were named after the node
+	names := []string{node.Host}
+	for _, s := range m {
+		if strings.HasPrefix(s.filename, node.Host+"-") {
+			names = append(names, s.filename)
+		}
+	}
+	archiveDir := filepath.Join(lp.GeneratedCertsDirectory, "archive", fmt.Sprintf("%s-%s", node.Host, time.Now().Format("2006-01-02-15-04-05")))
+	for _, name := range names {
+		exists, err := tls.CertKeyPairExists(name, lp.GeneratedCertsDirectory)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			continue
+		}
+		if err := os.MkdirAll(archiveDir, 0700); err != nil {
+			return "", fmt.Errorf("error creating archive directory %q: %v", archiveDir, err)
+		}
+		for _, f := range []string{name + ".pem", name + "-key.pem"} {
+			if err := os.Rename(filepath.Join(lp.GeneratedCertsDirectory, f), filepath.Join(archiveDir, f)); err != nil {
+				return "", fmt.Errorf("error archiving %q: %v", f, err)
+			}
+		}
+		util.PrettyPrintOk(lp.Log, "Archived certificate %s", name)
+	}
+	return archiveDir, nil
+}
+
+// RotateClusterCertificates moves the certificates of the cluster described in the
+// plan file into an archive directory, and generates new ones using the CA.
+// Returns the path of the archive directory.
+func (lp *LocalPKI) RotateClusterCertificates(plan *Plan, ca *tls.CA) (string, error) {
+	if lp.Log == nil {
+		lp.Log = ioutil.Discard
+	}
+	manifest, err := certManifestForCluster(*plan)
+	if err != nil {
+		return "", err
+	}
+	archiveDir := filepath.Join(lp.GeneratedCertsDirectory, "archive", fmt.Sprintf("rotate-%s", time.Now().Format("2006-01-02-15-04-05")))
+	rotated := map[string]bool{}
+	for _, s := range manifest {
+		// The service account tokens of the cluster are signed with this key,
+		// replacing it would invalidate all of them
+		if s.filename == serviceAccountCertFilename || rotated[s.filename] {
+			continue
+		}
+		rotated[s.filename] = true
+		exists, err := tls.CertKeyPairExists(s.filename, lp.GeneratedCertsDirectory)
+		if err != nil {
+			return "", err
+		}
+		if exists {
+			if err := os.MkdirAll(archiveDir, 0700); err != nil {
+				return "", fmt.Errorf("error creating archive directory %q: %v", archiveDir, err)
+			}
+			for _, f := range []string{s.filename + ".pem", s.filename + "-key.pem"} {
+				if err := os.Rename(filepath.Join(lp.GeneratedCertsDirectory, f), filepath.Join(archiveDir, f)); err != nil {
+					return "", fmt.Errorf("error archiving %q: %v", f, err)
+				}
+			}
+		}
+		if err := generateCert(ca, lp.GeneratedCertsDirectory, s, plan.Cluster.Certificates.Expiry, plan.Cluster.Certificates); err != nil {
+			return "", err
+		}
+		util.PrettyPrintOk(lp.Log, "Generated new certificate for %s", s.description)
+	}
+	return archiveDir, nil
+}
+
 // GenerateCertificate creates a private key and certificate for the given name, CN, subjectAlternateNames and organizations
 // If cert exists, will not fail
 // Pass overwrite to replace an existing cert
-func (lp *LocalPKI) GenerateCertificate(name string, validityPeriod string, commonName string, subjectAlternateNames []string, organizations []string, ca *tls.CA, overwrite bool) (bool, error) {
+func (lp *LocalPKI) GenerateCertificate(plan *Plan, name string, validityPeriod string, commonName string, subjectAlternateNames []string, organizations []string, ca *tls.CA, overwrite bool) (bool, error) {
 	if name == "" {
 		return false, fmt.Errorf("name cannot be empty")
 	}
@@ -452,32 +784,34 @@ func (lp *LocalPKI) GenerateCertificate(name string, validityPeriod string, comm
 		commonName:            commonName,
 		subjectAlternateNames: subjectAlternateNames,
 		organizations:         organizations,
+		// certificates without subject alternate names are client certificates
+		client: len(subjectAlternateNames) == 0,
 	}
 
-	if err := generateCert(ca, lp.GeneratedCertsDirectory, spec, validityPeriod); err != nil {
+	if err := generateCert(ca, lp.GeneratedCertsDirectory, spec, validityPeriod, plan.Cluster.Certificates); err != nil {
 		return exists, fmt.Errorf("could not generate certificate %s: %v", name, err)
 	}
 
 	return exists, nil
 }
 
-func generateCert(ca *tls.CA, certDir string, spec certificateSpec, expiryStr string) error {
+func generateCert(ca *tls.CA, certDir string, spec certificateSpec, expiryStr string, c CertsConfig) error {
 	expiry, err := time.ParseDuration(expiryStr)
 	if err != nil {
 		return fmt.Errorf("%q is not a valid duration for certificate expiry", expiryStr)
 	}
 	req := csr.CertificateRequest{
-		CN: spec.commonName,
-		KeyRequest: &csr.BasicKeyRequest{
-			A: "rsa",
-			S: 2048,
-		},
+		CN:         spec.commonName,
+		KeyRequest: c.keyRequest(),
 	}
 
 	if len(spec.subjectAlternateNames) > 0 {
 		req.Hosts = spec.subjectAlternateNames
 	}
 
+	if subject := c.certSubject(spec); subject != nil {
+		req.Names = append(req.Names, subject.CSRName())
+	}
 	for _, org := range spec.organizations {
 		name := csr.Name{O: org}
 		req.Names = append(req.Names, name)
@@ -535,3 +869,12 @@ func certSpecInManifest(spec certificateSpec, manifest []certificateSpec) bool {
 	}
 	return false
 }
+
+func certSpecFilenameInManifest(filename string, manifest []certificateSpec) bool {
+	for _, s := range manifest {
+		if s.filename == filename {
+			return true
+		}
+	}
+	return false
+}
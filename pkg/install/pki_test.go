@@ -1,10 +1,20 @@
 package install
 
 import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
 	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
 	"fmt"
 	"io/ioutil"
+	"math/big"
 	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -343,6 +353,468 @@ func TestNodeCertExistsSkipGeneration(t *testing.T) {
 	}
 }
 
+func TestArchiveNodeCertificates(t *testing.T) {
+	pki := getPKI(t)
+	defer cleanup(pki.GeneratedCertsDirectory, t)
+
+	p := getPlan()
+	node := p.Master.Nodes[0]
+
+	ca, err := pki.GenerateClusterCA(p)
+	if err != nil {
+		t.Fatalf("error generating CA for test: %v", err)
+	}
+	if err = pki.GenerateNodeCertificate(p, node, ca); err != nil {
+		t.Fatalf("failed to generate certs: %v", err)
+	}
+
+	archiveDir, err := pki.ArchiveNodeCertificates(p, node)
+	if err != nil {
+		t.Fatalf("failed to archive certs: %v", err)
+	}
+	for _, name := range []string{"master01-etcd", "master01-apiserver", "master01-kubelet"} {
+		exists, err := tls.CertKeyPairExists(name, pki.GeneratedCertsDirectory)
+		if err != nil {
+			t.Fatalf("error checking if cert exists: %v", err)
+		}
+		if exists {
+			t.Errorf("certificate %q was not removed from the generated certs dir", name)
+		}
+		exists, err = tls.CertKeyPairExists(name, archiveDir)
+		if err != nil {
+			t.Fatalf("error checking if cert exists: %v", err)
+		}
+		if !exists {
+			t.Errorf("certificate %q was not archived", name)
+		}
+	}
+	// certificates shared with other nodes must not be archived
+	for _, name := range []string{"ca", "kube-proxy", "etcd-client"} {
+		exists, err := tls.CertKeyPairExists(name, pki.GeneratedCertsDirectory)
+		if err != nil {
+			t.Fatalf("error checking if cert exists: %v", err)
+		}
+		if !exists {
+			t.Errorf("shared certificate %q was removed from the generated certs dir", name)
+		}
+	}
+}
+
+func TestRotateClusterCertificates(t *testing.T) {
+	pki := getPKI(t)
+	defer cleanup(pki.GeneratedCertsDirectory, t)
+
+	p := getPlan()
+	ca, err := pki.GenerateClusterCA(p)
+	if err != nil {
+		t.Fatalf("error generating CA for test: %v", err)
+	}
+	if err = pki.GenerateClusterCertificates(p, ca); err != nil {
+		t.Fatalf("error generating cluster certificates: %v", err)
+	}
+	certFile := func(dir, name string) string { return filepath.Join(dir, name+".pem") }
+	names := []string{"etcd01-etcd", "master01-apiserver", "worker01-kubelet", "kube-proxy", "etcd-client", "admin"}
+	serials := map[string]string{}
+	for _, name := range append(names, "service-account") {
+		serials[name] = mustReadCertFile(certFile(pki.GeneratedCertsDirectory, name), t).SerialNumber.String()
+	}
+
+	archiveDir, err := pki.RotateClusterCertificates(p, ca)
+	if err != nil {
+		t.Fatalf("error rotating cluster certificates: %v", err)
+	}
+	for _, name := range names {
+		cert := mustReadCertFile(certFile(pki.GeneratedCertsDirectory, name), t)
+		if cert.SerialNumber.String() == serials[name] {
+			t.Errorf("certificate %q was not regenerated", name)
+		}
+		archived := mustReadCertFile(certFile(archiveDir, name), t)
+		if archived.SerialNumber.String() != serials[name] {
+			t.Errorf("previous certificate %q was not archived", name)
+		}
+	}
+	// rotating the service account key would invalidate the service account tokens
+	cert := mustReadCertFile(certFile(pki.GeneratedCertsDirectory, "service-account"), t)
+	if cert.SerialNumber.String() != serials["service-account"] {
+		t.Errorf("service account certificate was regenerated")
+	}
+	// the CA is left untouched
+	if exists, _ := tls.CertKeyPairExists("ca", archiveDir); exists {
+		t.Errorf("CA was archived")
+	}
+}
+
+// writeIntermediateCA creates an intermediate CA signed by a new root CA, and
+// writes the intermediate CA certificate, key and chain in the directory
+func writeIntermediateCA(t *testing.T, dir string) CertsConfig {
+	rootKey, rootCert, err := tls.NewCACert("test/ca-csr.json", "root", "1h")
+	if err != nil {
+		t.Fatalf("error creating root CA: %v", err)
+	}
+	root, err := helpers.ParseCertificatePEM(rootCert)
+	if err != nil {
+		t.Fatalf("error parsing root CA certificate: %v", err)
+	}
+	rootPriv, err := helpers.ParsePrivateKeyPEM(rootKey)
+	if err != nil {
+		t.Fatalf("error parsing root CA key: %v", err)
+	}
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("error generating intermediate CA key: %v", err)
+	}
+	tpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(2),
+		Subject:               pkix.Name{CommonName: "intermediate"},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tpl, root, priv.Public(), rootPriv)
+	if err != nil {
+		t.Fatalf("error creating intermediate CA certificate: %v", err)
+	}
+	c := CertsConfig{
+		IntermediateCACert:  filepath.Join(dir, "intermediate.pem"),
+		IntermediateCAKey:   filepath.Join(dir, "intermediate-key.pem"),
+		IntermediateCAChain: filepath.Join(dir, "chain.pem"),
+	}
+	files := map[string][]byte{
+		c.IntermediateCACert:  pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		c.IntermediateCAKey:   pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(priv)}),
+		c.IntermediateCAChain: rootCert,
+	}
+	for file, data := range files {
+		if err := ioutil.WriteFile(file, data, 0600); err != nil {
+			t.Fatalf("error writing %q: %v", file, err)
+		}
+	}
+	return c
+}
+
+func TestGenerateClusterCAWithIntermediateCA(t *testing.T) {
+	pki := getPKI(t)
+	defer cleanup(pki.GeneratedCertsDirectory, t)
+	caDir, err := ioutil.TempDir("", "intermediate-ca")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer cleanup(caDir, t)
+
+	p := getPlan()
+	intermediate := writeIntermediateCA(t, caDir)
+	intermediate.Expiry = p.Cluster.Certificates.Expiry
+	p.Cluster.Certificates = intermediate
+
+	ca, err := pki.GenerateClusterCA(p)
+	if err != nil {
+		t.Fatalf("error setting up the intermediate CA: %v", err)
+	}
+	if err = pki.GenerateClusterCertificates(p, ca); err != nil {
+		t.Fatalf("error generating cluster certificates: %v", err)
+	}
+
+	// The CA certificate is the intermediate CA alone, as it is the trust anchor
+	// of the cluster components
+	caCerts, err := helpers.ParseCertificatesPEM(ca.Cert)
+	if err != nil {
+		t.Fatalf("error parsing CA certificate: %v", err)
+	}
+	if len(caCerts) != 1 || caCerts[0].Subject.CommonName != "intermediate" {
+		t.Fatalf("unexpected CA certificate %v", caCerts)
+	}
+	if local := mustReadCertFile(filepath.Join(pki.GeneratedCertsDirectory, "ca.pem"), t); !local.Equal(caCerts[0]) {
+		t.Errorf("expected ca.pem to be the intermediate CA, but got %q", local.Subject.CommonName)
+	}
+
+	// The CA chain contains the intermediate CA followed by the root CA
+	bundle, err := ioutil.ReadFile(filepath.Join(pki.GeneratedCertsDirectory, "ca-chain.pem"))
+	if err != nil {
+		t.Fatalf("error reading CA chain: %v", err)
+	}
+	chain, err := helpers.ParseCertificatesPEM(bundle)
+	if err != nil {
+		t.Fatalf("error parsing CA chain: %v", err)
+	}
+	if len(chain) != 2 || chain[0].Subject.CommonName != "intermediate" || chain[1].Subject.CommonName != "root" {
+		t.Fatalf("unexpected CA chain %v", chain)
+	}
+	if f := caBundleFile(pki.GeneratedCertsDirectory); f != filepath.Join(pki.GeneratedCertsDirectory, "ca-chain.pem") {
+		t.Errorf("expected the CA chain to be used to verify the cluster, but got %q", f)
+	}
+
+	// The cluster certificates are issued by the intermediate CA, and chain to the root
+	roots := x509.NewCertPool()
+	roots.AddCert(chain[1])
+	intermediates := x509.NewCertPool()
+	intermediates.AddCert(chain[0])
+	cert := mustReadCertFile(filepath.Join(pki.GeneratedCertsDirectory, "master01-apiserver.pem"), t)
+	if cert.Issuer.CommonName != "intermediate" {
+		t.Errorf("expected certificate to be issued by the intermediate CA, but was issued by %q", cert.Issuer.CommonName)
+	}
+	opts := x509.VerifyOptions{Roots: roots, Intermediates: intermediates, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny}}
+	if _, err := cert.Verify(opts); err != nil {
+		t.Errorf("certificate does not chain to the root CA: %v", err)
+	}
+
+	// Running again with the same intermediate CA is a no-op
+	if _, err = pki.GenerateClusterCA(p); err != nil {
+		t.Errorf("unexpected error when the intermediate CA already exists: %v", err)
+	}
+
+	// Switching to another intermediate CA is not allowed
+	otherDir, err := ioutil.TempDir("", "intermediate-ca")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer cleanup(otherDir, t)
+	other := writeIntermediateCA(t, otherDir)
+	p.Cluster.Certificates.IntermediateCACert = other.IntermediateCACert
+	p.Cluster.Certificates.IntermediateCAKey = other.IntermediateCAKey
+	p.Cluster.Certificates.IntermediateCAChain = other.IntermediateCAChain
+	if _, err = pki.GenerateClusterCA(p); err == nil {
+		t.Errorf("expected an error when the existing CA is not the intermediate CA")
+	}
+}
+
+// newCFSSLStandIn starts an HTTP server that implements the info and sign
+// endpoints of the CFSSL API using the given CA
+func newCFSSLStandIn(ca *tls.CA) *httptest.Server {
+	respond := func(w http.ResponseWriter, cert []byte, err error) {
+		resp := map[string]interface{}{"success": err == nil, "result": map[string]string{"certificate": string(cert)}}
+		if err != nil {
+			resp["errors"] = []map[string]interface{}{{"code": 1000, "message": err.Error()}}
+		}
+		json.NewEncoder(w).Encode(resp)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/cfssl/info", func(w http.ResponseWriter, r *http.Request) {
+		respond(w, ca.Cert, nil)
+	})
+	mux.HandleFunc("/api/v1/cfssl/sign", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			CertificateRequest string `json:"certificate_request"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respond(w, nil, err)
+			return
+		}
+		cert, err := tls.LocalSigner{CA: ca}.Sign([]byte(req.CertificateRequest), time.Hour)
+		respond(w, cert, err)
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestGenerateClusterCertificatesWithRemoteSigner(t *testing.T) {
+	pki := getPKI(t)
+	defer cleanup(pki.GeneratedCertsDirectory, t)
+
+	key, cert, err := tls.NewCACert("test/ca-csr.json", "remote", "1h")
+	if err != nil {
+		t.Fatalf("error creating remote CA: %v", err)
+	}
+	server := newCFSSLStandIn(&tls.CA{Key: key, Cert: cert})
+	defer server.Close()
+
+	p := getPlan()
+	p.Cluster.Certificates.RemoteSigner = &RemoteSigner{URL: server.URL}
+	ca, err := pki.GenerateClusterCA(p)
+	if err != nil {
+		t.Fatalf("error setting up the remote signer: %v", err)
+	}
+	if err = pki.GenerateClusterCertificates(p, ca); err != nil {
+		t.Fatalf("error generating cluster certificates: %v", err)
+	}
+
+	// Only the certificate of the remote CA is stored locally
+	exists, err := pki.CertificateAuthorityExists(p)
+	if err != nil || !exists {
+		t.Errorf("expected the CA to exist, but got exists=%v, err=%v", exists, err)
+	}
+	if _, err := os.Stat(filepath.Join(pki.GeneratedCertsDirectory, "ca-key.pem")); !os.IsNotExist(err) {
+		t.Errorf("expected the CA private key to not be stored locally")
+	}
+	caCert := mustReadCertFile(filepath.Join(pki.GeneratedCertsDirectory, "ca.pem"), t)
+	if caCert.Subject.CommonName != "remote" {
+		t.Errorf("expected the CA of the remote signer, but got %q", caCert.Subject.CommonName)
+	}
+	leaf := mustReadCertFile(filepath.Join(pki.GeneratedCertsDirectory, "worker01-kubelet.pem"), t)
+	if err := leaf.CheckSignatureFrom(caCert); err != nil {
+		t.Errorf("certificate was not signed by the remote CA: %v", err)
+	}
+
+	// New certificates are signed by the remote signer
+	existingCA, err := pki.GetClusterCA(p)
+	if err != nil {
+		t.Fatalf("error getting the cluster CA: %v", err)
+	}
+	if existingCA.Signer == nil {
+		t.Errorf("expected the cluster CA to use the remote signer")
+	}
+	if _, err = pki.GenerateCertificate(p, "alice", "1h", "alice", nil, nil, existingCA, false); err != nil {
+		t.Fatalf("error generating certificate: %v", err)
+	}
+	alice := mustReadCertFile(filepath.Join(pki.GeneratedCertsDirectory, "alice.pem"), t)
+	if err := alice.CheckSignatureFrom(caCert); err != nil {
+		t.Errorf("certificate was not signed by the remote CA: %v", err)
+	}
+}
+
+func TestGenerateClusterCertificatesKeyAndSubject(t *testing.T) {
+	pki := getPKI(t)
+	defer cleanup(pki.GeneratedCertsDirectory, t)
+
+	p := getPlan()
+	p.Cluster.Certificates.KeyAlgorithm = "ecdsa"
+	p.Cluster.Certificates.KeySize = 384
+	p.Cluster.Certificates.Subject = &CertificateSubject{
+		Country:            "CA",
+		Locality:           "Toronto",
+		Organization:       "Acme",
+		OrganizationalUnit: "Platform",
+	}
+	ca, err := pki.GenerateClusterCA(p)
+	if err != nil {
+		t.Fatalf("error generating CA for test: %v", err)
+	}
+	if err = pki.GenerateClusterCertificates(p, ca); err != nil {
+		t.Fatalf("error generating cluster certificates: %v", err)
+	}
+
+	for _, name := range []string{"ca", "master01-apiserver", "worker01-kubelet", "admin"} {
+		cert := mustReadCertFile(filepath.Join(pki.GeneratedCertsDirectory, name+".pem"), t)
+		pub, ok := cert.PublicKey.(*ecdsa.PublicKey)
+		if !ok {
+			t.Errorf("%s: expected an ECDSA key, but got %T", name, cert.PublicKey)
+		} else if pub.Curve.Params().BitSize != 384 {
+			t.Errorf("%s: expected a P-384 key, but got %d bits", name, pub.Curve.Params().BitSize)
+		}
+		if !reflect.DeepEqual(cert.Subject.Country, []string{"CA"}) ||
+			!reflect.DeepEqual(cert.Subject.Locality, []string{"Toronto"}) ||
+			!reflect.DeepEqual(cert.Subject.OrganizationalUnit, []string{"Platform"}) {
+			t.Errorf("%s: unexpected subject %v", name, cert.Subject)
+		}
+	}
+	// The organization is only set on the CA and the server certificates, as
+	// Kubernetes reads the organizations of client certificates as groups
+	for _, name := range []string{"ca", "master01-apiserver"} {
+		cert := mustReadCertFile(filepath.Join(pki.GeneratedCertsDirectory, name+".pem"), t)
+		if !util.Subset([]string{"Acme"}, cert.Subject.Organization) {
+			t.Errorf("%s: expected organization %q, but got %v", name, "Acme", cert.Subject.Organization)
+		}
+	}
+	for _, name := range []string{"worker01-kubelet", "admin", "kube-proxy", "kube-scheduler", "kube-controller-manager"} {
+		cert := mustReadCertFile(filepath.Join(pki.GeneratedCertsDirectory, name+".pem"), t)
+		if util.Subset([]string{"Acme"}, cert.Subject.Organization) {
+			t.Errorf("%s: expected no %q organization on a client certificate, but got %v", name, "Acme", cert.Subject.Organization)
+		}
+	}
+	// The organizations of the certificate spec are kept
+	kubelet := mustReadCertFile(filepath.Join(pki.GeneratedCertsDirectory, "worker01-kubelet.pem"), t)
+	if !util.Subset([]string{kubeletGroup}, kubelet.Subject.Organization) {
+		t.Errorf("expected kubelet certificate organizations to contain %q, but got %v", kubeletGroup, kubelet.Subject.Organization)
+	}
+}
+
+func TestValidateClusterCertificatesOutdatedKeyAndSubject(t *testing.T) {
+	pki := getPKI(t)
+	defer cleanup(pki.GeneratedCertsDirectory, t)
+	log := &bytes.Buffer{}
+	pki.Log = log
+
+	p := getPlan()
+	ca, err := pki.GenerateClusterCA(p)
+	if err != nil {
+		t.Fatalf("error generating CA for test: %v", err)
+	}
+	if err = pki.GenerateClusterCertificates(p, ca); err != nil {
+		t.Fatalf("error generating cluster certificates: %v", err)
+	}
+	outdated, err := pki.outdatedClusterCertificates(p)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(outdated) != 0 {
+		t.Errorf("expected no outdated certificates, but got %v", outdated)
+	}
+
+	// The certificates are still valid, but must be reissued to match the plan
+	p.Cluster.Certificates.KeyAlgorithm = "ecdsa"
+	p.Cluster.Certificates.Subject = &CertificateSubject{Organization: "Acme"}
+	warns, errs := pki.ValidateClusterCertificates(p)
+	if len(warns) != 0 || len(errs) != 0 {
+		t.Errorf("expected no validation warnings or errors, but got %v, %v", warns, errs)
+	}
+	outdated, err = pki.outdatedClusterCertificates(p)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(outdated) == 0 {
+		t.Errorf("expected outdated certificates, but didn't get any")
+	}
+	for _, w := range outdated {
+		if strings.Contains(w.Error(), serviceAccountCertFilename) {
+			t.Errorf("service account certificate should not be reported as outdated: %v", w)
+		}
+	}
+	if !strings.Contains(log.String(), "kismatic certificates rotate") {
+		t.Errorf("expected the output to offer reissuing the certificates, but got %q", log.String())
+	}
+	// The CA is reported separately, as it is not reissued
+	for _, w := range outdated {
+		if strings.Contains(w.Error(), "\"ca.pem\"") {
+			t.Errorf("CA should not be reported with the cluster certificates: %v", w)
+		}
+	}
+	outdatedCA, err := pki.outdatedCA(p)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(outdatedCA) == 0 {
+		t.Errorf("expected the CA to be outdated")
+	}
+	if !strings.Contains(log.String(), "The CA is not reissued") {
+		t.Errorf("expected the output to report that the CA is not reissued, but got %q", log.String())
+	}
+}
+
+func TestValidateClusterCertificatesClientCertificateWithSubjectOrganization(t *testing.T) {
+	pki := getPKI(t)
+	defer cleanup(pki.GeneratedCertsDirectory, t)
+
+	p := getPlan()
+	p.Cluster.Certificates.Subject = &CertificateSubject{Organization: "Acme"}
+	ca, err := pki.GenerateClusterCA(p)
+	if err != nil {
+		t.Fatalf("error generating CA for test: %v", err)
+	}
+	if err = pki.GenerateClusterCertificates(p, ca); err != nil {
+		t.Fatalf("error generating cluster certificates: %v", err)
+	}
+	outdated, err := pki.outdatedClusterCertificates(p)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(outdated) != 0 {
+		t.Errorf("expected no outdated certificates, but got %v", outdated)
+	}
+
+	// An admin certificate issued with the organization of the subject belongs to the Acme group
+	spec := certificateSpec{description: "admin client", filename: adminCertFilename, commonName: adminUser, organizations: []string{adminGroup}}
+	if err := generateCert(ca, pki.GeneratedCertsDirectory, spec, p.Cluster.Certificates.Expiry, p.Cluster.Certificates); err != nil {
+		t.Fatalf("error generating admin certificate: %v", err)
+	}
+	outdated, err = pki.outdatedClusterCertificates(p)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(outdated) != 1 || !strings.Contains(outdated[0].Error(), adminCertFilename) {
+		t.Errorf("expected the admin certificate to be outdated, but got %v", outdated)
+	}
+}
+
 func TestGenerateClusterCertificatesValidateCertificateInformation(t *testing.T) {
 	pki := getPKI(t)
 	defer cleanup(pki.GeneratedCertsDirectory, t)
@@ -1054,7 +1526,7 @@ func TestGenerateCertificate(t *testing.T) {
 		},
 	}
 	for i, test := range tests {
-		exists, err := pki.GenerateCertificate(test.name, test.validityPeriod, test.commonName, test.subjectAlternateNames, test.organizations, test.ca, test.overwrite)
+		exists, err := pki.GenerateCertificate(getPlan(), test.name, test.validityPeriod, test.commonName, test.subjectAlternateNames, test.organizations, test.ca, test.overwrite)
 
 		if (err != nil) == test.valid {
 			t.Errorf("test %d: expect valid to be %t, but got %v", i, test.valid, err)
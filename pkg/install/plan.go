@@ -63,9 +63,10 @@ func (fp *FilePlanner) Read() (*Plan, error) {
 	if err = yaml.Unmarshal(d, p); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal plan: %v", err)
 	}
-
-	// read deprecated fields and set it the new version of the cluster file
-	readDeprecatedFields(p)
+	// move the deprecated fields of older plan versions to the new fields
+	if _, err = migratePlan(p); err != nil {
+		return nil, err
+	}
 
 	// set nil values to defaults
 	setDefaults(p)
@@ -73,41 +74,12 @@ func (fp *FilePlanner) Read() (*Plan, error) {
 	return p, nil
 }
 
-func readDeprecatedFields(p *Plan) {
-	// only set if not already being set by the user
-	// package_manager moved from features: to add_ons: after KET v1.3.3
-	if p.Features != nil && p.Features.PackageManager != nil {
-		p.AddOns.PackageManager.Disable = !p.Features.PackageManager.Enabled
-		// KET v1.3.3 did not have a provider field
-		p.AddOns.PackageManager.Provider = ket133PackageManagerProvider
-	}
-	// allow_package_installation renamed to disable_package_installation after KET v1.4.0
-	if p.Cluster.AllowPackageInstallation != nil {
-		p.Cluster.DisablePackageInstallation = !*p.Cluster.AllowPackageInstallation
-	}
-
-	// Only read the deprecated dashboard field if the new one is not set
-	if p.AddOns.DashboardDeprecated != nil && p.AddOns.Dashboard == nil {
-		p.AddOns.Dashboard = &Dashboard{
-			Disable: p.AddOns.DashboardDeprecated.Disable,
-		}
-	}
-
-	if p.DockerRegistry.Server == "" && p.DockerRegistry.Address != "" && p.DockerRegistry.Port != 0 {
-		p.DockerRegistry.Server = fmt.Sprintf("%s:%d", p.DockerRegistry.Address, p.DockerRegistry.Port)
-	}
-}
-
 func setDefaults(p *Plan) {
 	if p.AddOns.CNI == nil {
 		p.AddOns.CNI = &CNI{}
 		p.AddOns.CNI.Provider = cniProviderCalico
 		p.AddOns.CNI.Options.Calico.Mode = "overlay"
 		p.AddOns.CNI.Options.Calico.LogLevel = "info"
-		// read KET <v1.5.0 plan option
-		if p.Cluster.Networking.Type != "" {
-			p.AddOns.CNI.Options.Calico.Mode = p.Cluster.Networking.Type
-		}
 	}
 	if p.AddOns.CNI.Options.Calico.LogLevel == "" {
 		p.AddOns.CNI.Options.Calico.LogLevel = "info"
@@ -119,19 +91,12 @@ func setDefaults(p *Plan) {
 	if p.AddOns.HeapsterMonitoring.Options.Heapster.Replicas == 0 {
 		p.AddOns.HeapsterMonitoring.Options.Heapster.Replicas = 2
 	}
-	// read field from KET < v1.5.0
-	if p.AddOns.HeapsterMonitoring.Options.HeapsterReplicas != 0 {
-		p.AddOns.HeapsterMonitoring.Options.Heapster.Replicas = p.AddOns.HeapsterMonitoring.Options.HeapsterReplicas
-	}
 	if p.AddOns.HeapsterMonitoring.Options.Heapster.Sink == "" {
 		p.AddOns.HeapsterMonitoring.Options.Heapster.Sink = "influxdb:http://heapster-influxdb.kube-system.svc:8086"
 	}
 	if p.AddOns.HeapsterMonitoring.Options.Heapster.ServiceType == "" {
 		p.AddOns.HeapsterMonitoring.Options.Heapster.ServiceType = "ClusterIP"
 	}
-	if p.AddOns.HeapsterMonitoring.Options.InfluxDBPVCName != "" {
-		p.AddOns.HeapsterMonitoring.Options.InfluxDB.PVCName = p.AddOns.HeapsterMonitoring.Options.InfluxDBPVCName
-	}
 
 	if p.Cluster.Certificates.CAExpiry == "" {
 		p.Cluster.Certificates.CAExpiry = defaultCAExpiry
@@ -140,6 +105,31 @@ func setDefaults(p *Plan) {
 	if p.AddOns.Dashboard == nil {
 		p.AddOns.Dashboard = &Dashboard{}
 	}
+
+	if p.Cluster.SecretsEncryption != nil && p.Cluster.SecretsEncryption.Provider == "" {
+		p.Cluster.SecretsEncryption.Provider = encryptionProviderAESCBC
+	}
+
+	if p.Cluster.Authentication != nil && p.Cluster.Authentication.OIDC != nil && p.Cluster.Authentication.OIDC.UsernameClaim == "" {
+		p.Cluster.Authentication.OIDC.UsernameClaim = "sub"
+	}
+
+	if audit := p.Cluster.APIServerOptions.Audit; audit != nil {
+		if audit.LogPath == "" {
+			audit.LogPath = defaultAuditLogPath
+		}
+		if audit.LogMaxAge == nil {
+			maxAge := 30
+			audit.LogMaxAge = &maxAge
+		}
+		if audit.LogMaxBackups == nil {
+			maxBackups := 10
+			audit.LogMaxBackups = &maxBackups
+		}
+		if audit.Webhook != nil && audit.Webhook.Mode == "" {
+			audit.Webhook.Mode = auditWebhookModeBatch
+		}
+	}
 }
 
 var yamlKeyRE = regexp.MustCompile(`[^a-zA-Z]*([a-z_\-A-Z]+)[ ]*:`)
@@ -270,6 +260,7 @@ func WritePlanTemplate(planTemplateOpts PlanTemplateOptions, w PlanReadWriter) e
 // template options
 func buildPlanFromTemplateOptions(templateOpts PlanTemplateOptions) Plan {
 	p := Plan{}
+	p.PlanVersion = CurrentPlanVersion
 	p.Cluster.Name = "kubernetes"
 	p.Cluster.AdminPassword = templateOpts.AdminPassword
 	p.Cluster.DisablePackageInstallation = false
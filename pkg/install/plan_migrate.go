@@ -0,0 +1,166 @@
+package install
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// CurrentPlanVersion is the version of the plan file format supported by
+// this release of KET
+const CurrentPlanVersion = 1
+
+// planMigration upgrades a plan to the version, and returns a description of
+// each field that was changed
+type planMigration struct {
+	version int
+	migrate func(p *Plan) []string
+}
+
+// planMigrations are run in order on the plans with an older version
+var planMigrations = []planMigration{
+	{version: 1, migrate: migrateDeprecatedFields},
+}
+
+// PlanMigration is the result of migrating a plan file
+type PlanMigration struct {
+	// Version of the plan file before the migration
+	FromVersion int
+	// Version of the plan file after the migration
+	ToVersion int
+	// Fields of the plan file that were moved, renamed or removed
+	Changes []string
+	// Path to the copy of the plan file before the migration. It is empty
+	// when the plan file was already at the current version.
+	BackupFile string
+}
+
+// checkPlanVersion returns an error if the plan is newer than the plan
+// versions supported by this release
+func checkPlanVersion(p *Plan) error {
+	if p.PlanVersion > CurrentPlanVersion {
+		return fmt.Errorf("plan file version %d is not supported, the latest version supported by this release is %d", p.PlanVersion, CurrentPlanVersion)
+	}
+	return nil
+}
+
+// MigratePlanFile upgrades the plan file to the current plan version. The
+// plan file is replaced, and the previous contents are kept in a backup file.
+// The plan file is left untouched when it is already at the current version.
+func MigratePlanFile(fp *FilePlanner) (*PlanMigration, error) {
+	d, err := ioutil.ReadFile(fp.File)
+	if err != nil {
+		return nil, fmt.Errorf("could not read file: %v", err)
+	}
+	p := &Plan{}
+	if err = yaml.Unmarshal(d, p); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal plan: %v", err)
+	}
+	from := p.PlanVersion
+	changes, err := migratePlan(p)
+	if err != nil {
+		return nil, err
+	}
+	m := &PlanMigration{FromVersion: from, ToVersion: p.PlanVersion, Changes: changes}
+	if from == p.PlanVersion {
+		return m, nil
+	}
+
+	m.BackupFile = fmt.Sprintf("%s.v%d.bak", fp.File, from)
+	// the plan file contains the admin password, the backup must only be readable by the owner
+	if err := ioutil.WriteFile(m.BackupFile, d, 0600); err != nil {
+		return nil, fmt.Errorf("error backing up plan file: %v", err)
+	}
+	if err := fp.Write(p); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// migratePlan runs the migrations of the versions newer than the version of
+// the plan
+func migratePlan(p *Plan) ([]string, error) {
+	if err := checkPlanVersion(p); err != nil {
+		return nil, err
+	}
+	changes := []string{}
+	for _, m := range planMigrations {
+		if p.PlanVersion >= m.version {
+			continue
+		}
+		changes = append(changes, m.migrate(p)...)
+		p.PlanVersion = m.version
+	}
+	return changes, nil
+}
+
+// migrateDeprecatedFields moves the fields deprecated by previous KET releases
+// to the fields that replaced them, and removes the deprecated fields
+func migrateDeprecatedFields(p *Plan) []string {
+	changes := []string{}
+	// package_manager moved from features: to add_ons: after KET v1.3.3
+	if p.Features != nil {
+		if p.Features.PackageManager != nil {
+			p.AddOns.PackageManager.Disable = !p.Features.PackageManager.Enabled
+			p.AddOns.PackageManager.Provider = ket133PackageManagerProvider
+			changes = append(changes, "features.package_manager.enabled moved to add_ons.package_manager.disable")
+		} else {
+			changes = append(changes, "features removed")
+		}
+		p.Features = nil
+	}
+	// allow_package_installation renamed to disable_package_installation after KET v1.4.0
+	if p.Cluster.AllowPackageInstallation != nil {
+		p.Cluster.DisablePackageInstallation = !*p.Cluster.AllowPackageInstallation
+		p.Cluster.AllowPackageInstallation = nil
+		changes = append(changes, "cluster.allow_package_installation renamed to cluster.disable_package_installation")
+	}
+	// networking.type moved to the calico options of the cni add-on in KET v1.5.0
+	if p.Cluster.Networking.Type != "" {
+		if p.AddOns.CNI == nil {
+			p.AddOns.CNI = &CNI{Provider: cniProviderCalico}
+			p.AddOns.CNI.Options.Calico.Mode = p.Cluster.Networking.Type
+			p.AddOns.CNI.Options.Calico.LogLevel = "info"
+			changes = append(changes, "cluster.networking.type moved to add_ons.cni.options.calico.mode")
+		} else {
+			changes = append(changes, "cluster.networking.type removed, as add_ons.cni is set")
+		}
+		p.Cluster.Networking.Type = ""
+	}
+	// heapster options moved in KET v1.5.0
+	if h := p.AddOns.HeapsterMonitoring; h != nil {
+		if h.Options.HeapsterReplicas != 0 {
+			h.Options.Heapster.Replicas = h.Options.HeapsterReplicas
+			h.Options.HeapsterReplicas = 0
+			changes = append(changes, "add_ons.heapster.options.heapster_replicas moved to add_ons.heapster.options.heapster.replicas")
+		}
+		if h.Options.InfluxDBPVCName != "" {
+			h.Options.InfluxDB.PVCName = h.Options.InfluxDBPVCName
+			h.Options.InfluxDBPVCName = ""
+			changes = append(changes, "add_ons.heapster.options.influxdb_pvc_name moved to add_ons.heapster.options.influxdb.pvc_name")
+		}
+	}
+	// the misspelled dashbard field was renamed to dashboard
+	if p.AddOns.DashboardDeprecated != nil {
+		if p.AddOns.Dashboard == nil {
+			p.AddOns.Dashboard = &Dashboard{Disable: p.AddOns.DashboardDeprecated.Disable}
+			changes = append(changes, "add_ons.dashbard renamed to add_ons.dashboard")
+		} else {
+			changes = append(changes, "add_ons.dashbard removed, as add_ons.dashboard is set")
+		}
+		p.AddOns.DashboardDeprecated = nil
+	}
+	// address and port of the docker registry were merged into server
+	if p.DockerRegistry.Address != "" || p.DockerRegistry.Port != 0 {
+		if p.DockerRegistry.Server == "" && p.DockerRegistry.Address != "" && p.DockerRegistry.Port != 0 {
+			p.DockerRegistry.Server = fmt.Sprintf("%s:%d", p.DockerRegistry.Address, p.DockerRegistry.Port)
+			changes = append(changes, "docker_registry.address and docker_registry.port moved to docker_registry.server")
+		} else {
+			changes = append(changes, "docker_registry.address and docker_registry.port removed")
+		}
+		p.DockerRegistry.Address = ""
+		p.DockerRegistry.Port = 0
+	}
+	return changes
+}
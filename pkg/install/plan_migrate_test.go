@@ -0,0 +1,164 @@
+package install
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const deprecatedPlan = `cluster:
+  name: test
+  allow_package_installation: true
+  networking:
+    type: routed
+    pod_cidr_block: 172.16.0.0/16
+    service_cidr_block: 172.20.0.0/16
+docker_registry:
+  address: registry.example.com
+  port: 5000
+add_ons:
+  heapster:
+    options:
+      heapster_replicas: 3
+      influxdb_pvc_name: influxdb
+  dashbard:
+    disable: true
+features:
+  package_manager:
+    enabled: false
+`
+
+func writeTestPlanFile(t *testing.T, contents string) (string, *FilePlanner) {
+	tmpDir, err := ioutil.TempDir("", "test-migrate-plan")
+	if err != nil {
+		t.Fatalf("error creating tmp dir: %v", err)
+	}
+	file := filepath.Join(tmpDir, "kismatic-cluster.yaml")
+	if err := ioutil.WriteFile(file, []byte(contents), 0644); err != nil {
+		t.Fatalf("error writing plan file: %v", err)
+	}
+	return tmpDir, &FilePlanner{File: file}
+}
+
+func TestMigratePlanFile(t *testing.T) {
+	tmpDir, fp := writeTestPlanFile(t, deprecatedPlan)
+	defer os.RemoveAll(tmpDir)
+
+	m, err := MigratePlanFile(fp)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m.FromVersion != 0 || m.ToVersion != CurrentPlanVersion {
+		t.Errorf("expected migration from version 0 to %d, but got %d to %d", CurrentPlanVersion, m.FromVersion, m.ToVersion)
+	}
+	expectedChanges := []string{
+		"features.package_manager.enabled moved to add_ons.package_manager.disable",
+		"cluster.allow_package_installation renamed to cluster.disable_package_installation",
+		"cluster.networking.type moved to add_ons.cni.options.calico.mode",
+		"add_ons.heapster.options.heapster_replicas moved to add_ons.heapster.options.heapster.replicas",
+		"add_ons.heapster.options.influxdb_pvc_name moved to add_ons.heapster.options.influxdb.pvc_name",
+		"add_ons.dashbard renamed to add_ons.dashboard",
+		"docker_registry.address and docker_registry.port moved to docker_registry.server",
+	}
+	assertEqual(t, m.Changes, expectedChanges)
+
+	backup, err := ioutil.ReadFile(m.BackupFile)
+	if err != nil {
+		t.Fatalf("error reading backup file: %v", err)
+	}
+	if string(backup) != deprecatedPlan {
+		t.Errorf("expected the backup to contain the previous plan file, but got:\n%s", backup)
+	}
+
+	migrated, err := ioutil.ReadFile(fp.File)
+	if err != nil {
+		t.Fatalf("error reading migrated plan file: %v", err)
+	}
+	for _, field := range []string{"allow_package_installation", "heapster_replicas", "influxdb_pvc_name", "dashbard", "features", "address:", "port: 5000"} {
+		if strings.Contains(string(migrated), field) {
+			t.Errorf("expected deprecated field %q to be removed, but got:\n%s", field, migrated)
+		}
+	}
+	// Only the migrated fields are changed, the defaults are not written to the plan file
+	for _, value := range []string{"influxdb:http://heapster-influxdb.kube-system.svc:8086", defaultCAExpiry} {
+		if strings.Contains(string(migrated), value) {
+			t.Errorf("expected default %q not to be written, but got:\n%s", value, migrated)
+		}
+	}
+	p, err := fp.Read()
+	if err != nil {
+		t.Fatalf("error reading migrated plan file: %v", err)
+	}
+	if p.PlanVersion != CurrentPlanVersion {
+		t.Errorf("expected plan version %d, but got %d", CurrentPlanVersion, p.PlanVersion)
+	}
+	if !p.AddOns.PackageManager.Disable || p.Cluster.DisablePackageInstallation {
+		t.Errorf("expected package manager to be disabled and package installation to be enabled")
+	}
+	if p.AddOns.CNI.Options.Calico.Mode != "routed" {
+		t.Errorf("expected calico mode routed, but got %s", p.AddOns.CNI.Options.Calico.Mode)
+	}
+	if p.AddOns.HeapsterMonitoring.Options.Heapster.Replicas != 3 || p.AddOns.HeapsterMonitoring.Options.InfluxDB.PVCName != "influxdb" {
+		t.Errorf("expected heapster options to be migrated, but got %+v", p.AddOns.HeapsterMonitoring.Options)
+	}
+	if p.AddOns.Dashboard == nil || !p.AddOns.Dashboard.Disable {
+		t.Errorf("expected dashboard to be disabled")
+	}
+	if p.DockerRegistry.Server != "registry.example.com:5000" {
+		t.Errorf("expected docker registry server registry.example.com:5000, but got %s", p.DockerRegistry.Server)
+	}
+
+	// A plan at the current version is not modified
+	m, err = MigratePlanFile(fp)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m.FromVersion != CurrentPlanVersion || len(m.Changes) != 0 || m.BackupFile != "" {
+		t.Errorf("expected no migration, but got %+v", m)
+	}
+}
+
+func TestReadMigratesDeprecatedFields(t *testing.T) {
+	tmpDir, fp := writeTestPlanFile(t, deprecatedPlan)
+	defer os.RemoveAll(tmpDir)
+
+	p, err := fp.Read()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.AddOns.CNI.Options.Calico.Mode != "routed" {
+		t.Errorf("expected calico mode routed, but got %s", p.AddOns.CNI.Options.Calico.Mode)
+	}
+	if p.AddOns.HeapsterMonitoring.Options.Heapster.Replicas != 3 || p.AddOns.HeapsterMonitoring.Options.InfluxDB.PVCName != "influxdb" {
+		t.Errorf("expected heapster options to be migrated, but got %+v", p.AddOns.HeapsterMonitoring.Options)
+	}
+	if p.DockerRegistry.Server != "registry.example.com:5000" {
+		t.Errorf("expected docker registry server registry.example.com:5000, but got %s", p.DockerRegistry.Server)
+	}
+
+	// The plan file is only migrated in memory
+	d, err := ioutil.ReadFile(fp.File)
+	if err != nil {
+		t.Fatalf("error reading plan file: %v", err)
+	}
+	if string(d) != deprecatedPlan {
+		t.Errorf("expected the plan file to be left untouched, but got:\n%s", d)
+	}
+}
+
+func TestMigratePlanFileNewerVersion(t *testing.T) {
+	tmpDir, fp := writeTestPlanFile(t, "plan_version: 100\ncluster:\n  name: test\n")
+	defer os.RemoveAll(tmpDir)
+
+	if _, err := MigratePlanFile(fp); err == nil {
+		t.Error("expected an error migrating a plan file newer than the supported version")
+	}
+	if _, err := fp.Read(); err == nil {
+		t.Error("expected an error reading a plan file newer than the supported version")
+	}
+	if _, err := os.Stat(fp.File + ".v100.bak"); !os.IsNotExist(err) {
+		t.Error("expected no backup of the plan file")
+	}
+}
@@ -0,0 +1,70 @@
+package install
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+type testSchema struct {
+	Type       interface{}            `json:"type"`
+	Properties map[string]*testSchema `json:"properties"`
+	Items      *testSchema            `json:"items"`
+	Required   []string               `json:"required"`
+}
+
+func TestPlanJSONSchema(t *testing.T) {
+	s := &testSchema{}
+	if err := json.Unmarshal([]byte(PlanJSONSchema), s); err != nil {
+		t.Fatalf("error parsing plan JSON schema: %v", err)
+	}
+	required := append([]string{}, s.Required...)
+	sort.Strings(required)
+	assertEqual(t, required, []string{"cluster", "etcd", "master", "worker"})
+	assertSchemaMatchesType(t, "", s, reflect.TypeOf(Plan{}))
+}
+
+// assertSchemaMatchesType verifies that the properties of the schema are the
+// fields of the plan type, which fails when the schema was not regenerated
+// after changing the plan types
+func assertSchemaMatchesType(t *testing.T, path string, s *testSchema, typ reflect.Type) {
+	fields := []string{}
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		name := strings.Split(f.Tag.Get("yaml"), ",")[0]
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		fields = append(fields, name)
+
+		prop, ok := s.Properties[name]
+		if !ok {
+			continue
+		}
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if ft.Kind() == reflect.Slice && prop.Items != nil {
+			ft = ft.Elem()
+			prop = prop.Items
+		}
+		if ft.Kind() == reflect.Struct && ft.PkgPath() == typ.PkgPath() {
+			assertSchemaMatchesType(t, path+name+".", prop, ft)
+		}
+	}
+	properties := []string{}
+	for p := range s.Properties {
+		properties = append(properties, p)
+	}
+	sort.Strings(fields)
+	sort.Strings(properties)
+	if !reflect.DeepEqual(fields, properties) {
+		t.Errorf("properties of %q in the plan JSON schema %v do not match the fields of %s %v, the schema must be regenerated", path, properties, typ.Name(), fields)
+	}
+}
@@ -87,7 +87,7 @@ func TestReadWithDeprecated(t *testing.T) {
 	}
 	b := false
 	p.Cluster.AllowPackageInstallation = &b
-	readDeprecatedFields(p)
+	migrateDeprecatedFields(p)
 
 	// features.package_manager should be set to add_ons.package_manager
 	if p.AddOns.PackageManager.Disable || p.AddOns.PackageManager.Provider != "helm" {
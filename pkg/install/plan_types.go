@@ -3,9 +3,13 @@ package install
 import (
 	"fmt"
 	"net"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/apprenda/kismatic/pkg/ssh"
+	"github.com/apprenda/kismatic/pkg/tls"
+	yaml "gopkg.in/yaml.v2"
 )
 
 const (
@@ -13,6 +17,11 @@ const (
 	cniProviderCalico = "calico"
 	cniProviderWeave  = "weave"
 	cniProviderCustom = "custom"
+
+	sshClientExternal = "external"
+	sshClientNative   = "native"
+
+	knownHostsFilename = "known_hosts"
 )
 
 func packageManagerProviders() []string {
@@ -35,12 +44,20 @@ func serviceTypes() []string {
 	return []string{"ClusterIP", "NodePort", "LoadBalancer", "ExternalName"}
 }
 
+func sshClients() []string {
+	return []string{sshClientExternal, sshClientNative}
+}
+
 func cloudProviders() []string {
 	return []string{"aws", "azure", "cloudstack", "fake", "gce", "mesos", "openstack", "ovirt", "photon", "rackspace", "vsphere"}
 }
 
 // Plan is the installation plan that the user intends to execute
 type Plan struct {
+	// Version of the plan file format. Plan files without a version were
+	// created by a previous KET release, and can be upgraded to the current
+	// version with the `install plan migrate` command.
+	PlanVersion int `yaml:"plan_version"`
 	// Kubernetes cluster configuration
 	// +required
 	Cluster Cluster
@@ -110,6 +127,58 @@ type Cluster struct {
 	KubeletOptions KubeletOptions `yaml:"kubelet"`
 	// The CloudProvider configuration for the cluster.
 	CloudProvider CloudProvider `yaml:"cloud_provider"`
+	// Encryption at rest of the Kubernetes secrets stored in etcd.
+	SecretsEncryption *SecretsEncryption `yaml:"secrets_encryption,omitempty"`
+	// Authentication configuration for the Kubernetes API server.
+	Authentication *Authentication `yaml:"authentication,omitempty"`
+	// Feature gates to enable or disable in all the Kubernetes components,
+	// i.e. the API server, controller manager, scheduler, proxy and kubelet.
+	// The feature gates must be known to the Kubernetes version installed by KET.
+	FeatureGates map[string]bool `yaml:"feature_gates,omitempty"`
+}
+
+// Authentication is the configuration of the authentication strategies of
+// the Kubernetes API server, in addition to the client certificates.
+type Authentication struct {
+	// OpenID Connect authentication of the users, with the ID tokens
+	// issued by an identity provider.
+	OIDC *OIDC `yaml:"oidc,omitempty"`
+}
+
+// OIDC is the configuration of the OpenID Connect authentication
+type OIDC struct {
+	// URL of the OpenID Connect identity provider. Must use the https scheme.
+	// +required
+	IssuerURL string `yaml:"issuer_url"`
+	// The client ID for the OpenID Connect client. All the ID tokens must
+	// be issued for this client ID.
+	// +required
+	ClientID string `yaml:"client_id"`
+	// The claim of the ID token to use as the user name.
+	// +default=sub
+	UsernameClaim string `yaml:"username_claim,omitempty"`
+	// The claim of the ID token to use as the groups of the user.
+	// The groups are not read from the ID tokens when empty.
+	GroupsClaim string `yaml:"groups_claim,omitempty"`
+	// Absolute path to the certificate of the CA that signed the certificate
+	// of the identity provider. This will be copied to all the master nodes
+	// in the cluster. The CAs of the host are used when empty.
+	CAFile string `yaml:"ca_file,omitempty"`
+}
+
+// SecretsEncryption is the configuration of the encryption at rest of the
+// Kubernetes secrets. The encryption keys are generated by KET, and stored
+// in the generated assets directory.
+type SecretsEncryption struct {
+	// Whether the Kubernetes secrets should be encrypted before they are
+	// stored in etcd.
+	// +default=false
+	Enabled bool
+	// The encryption provider used to encrypt the secrets with the
+	// generated keys.
+	// +default=aescbc
+	// +options=aescbc,secretbox
+	Provider string `yaml:"provider,omitempty"`
 }
 
 type APIServerOptions struct {
@@ -117,6 +186,47 @@ type APIServerOptions struct {
 	// API server configuration. This is an advanced feature that can prevent
 	// the API server from starting up if invalid configuration is provided.
 	Overrides map[string]string `yaml:"option_overrides"`
+	// Audit logging configuration for the Kubernetes API server.
+	Audit *AuditConfig `yaml:"audit,omitempty"`
+}
+
+// AuditConfig is the audit logging configuration of the Kubernetes API server.
+// The audit policy is defined with either policy_rules or policy_file.
+type AuditConfig struct {
+	// Rules of the audit policy, as found in the rules field of a
+	// Kubernetes audit Policy (audit.k8s.io/v1beta1). Each rule must set
+	// the level of the events it matches.
+	PolicyRules []yaml.MapSlice `yaml:"policy_rules,omitempty"`
+	// Absolute path to a Kubernetes audit Policy file (audit.k8s.io/v1beta1).
+	// This will be copied to all the master nodes in the cluster.
+	PolicyFile string `yaml:"policy_file,omitempty"`
+	// Absolute path of the audit log file on the master nodes.
+	// +default=/var/log/kubernetes/audit.log
+	LogPath string `yaml:"log_path,omitempty"`
+	// Maximum number of days to retain old audit log files.
+	// When set to 0, old files are not removed based on their age.
+	// +default=30
+	LogMaxAge *int `yaml:"log_max_age,omitempty"`
+	// Maximum number of old audit log files to retain.
+	// When set to 0, all the old files are retained.
+	// +default=10
+	LogMaxBackups *int `yaml:"log_max_backups,omitempty"`
+	// Configuration of the webhook audit backend, which sends the audit
+	// events to a remote API.
+	Webhook *AuditWebhook `yaml:"webhook,omitempty"`
+}
+
+// AuditWebhook is the configuration of the webhook audit backend
+type AuditWebhook struct {
+	// Absolute path to the kubeconfig formatted file that defines the
+	// remote API of the webhook backend. This will be copied to all the
+	// master nodes in the cluster.
+	// +required
+	ConfigFile string `yaml:"config_file"`
+	// Strategy for sending the audit events to the remote API.
+	// +default=batch
+	// +options=batch,blocking
+	Mode string `yaml:"mode,omitempty"`
 }
 
 type KubeControllerManagerOptions struct {
@@ -184,6 +294,79 @@ type CertsConfig struct {
 	// For example: "17520h" for 2 years.
 	// +required.
 	CAExpiry string `yaml:"ca_expiry"`
+	// Absolute path to the certificate of an existing intermediate Certificate Authority.
+	// When set, the cluster certificates are issued by this CA instead of a generated
+	// self-signed CA, and ca_expiry is ignored.
+	IntermediateCACert string `yaml:"intermediate_ca_cert,omitempty"`
+	// Absolute path to the private key of the intermediate Certificate Authority.
+	// Required when intermediate_ca_cert is set.
+	IntermediateCAKey string `yaml:"intermediate_ca_key,omitempty"`
+	// Absolute path to the certificate chain of the intermediate Certificate Authority,
+	// which must include the root CA. The chain is added to the trust stores of the nodes
+	// and to the generated kubeconfig files.
+	// Required when intermediate_ca_cert is set.
+	IntermediateCAChain string `yaml:"intermediate_ca_chain,omitempty"`
+	// The remote CFSSL server that signs the cluster certificates. When set, the
+	// private key of the CA is never stored locally, and the validity period of
+	// the certificates is controlled by the signing profile of the server.
+	// The certificates are signed locally when not set.
+	RemoteSigner *RemoteSigner `yaml:"remote_signer,omitempty"`
+	// The algorithm of the private keys of the generated CA and cluster certificates.
+	// +default=rsa
+	// +options=rsa,ecdsa
+	KeyAlgorithm string `yaml:"key_algorithm,omitempty"`
+	// The size of the private keys of the generated CA and cluster certificates, in bits.
+	// Must be 2048 or 4096 for RSA keys, and 256 (P-256) or 384 (P-384) for ECDSA keys.
+	// Defaults to 2048 for RSA keys, and to 256 for ECDSA keys.
+	KeySize int `yaml:"key_size,omitempty"`
+	// The subject fields of the generated CA and cluster certificates.
+	Subject *CertificateSubject `yaml:"subject,omitempty"`
+}
+
+// CertificateSubject contains the fields of the subject of the certificates
+type CertificateSubject struct {
+	// The country (C) of the certificates.
+	Country string `yaml:"country,omitempty"`
+	// The locality (L) of the certificates.
+	Locality string `yaml:"locality,omitempty"`
+	// The organization (O) of the CA and the server certificates. Kubernetes treats
+	// the organizations of client certificates as groups, so it is not set on the
+	// client certificates of the components and users.
+	Organization string `yaml:"organization,omitempty"`
+	// The organizational unit (OU) of the certificates.
+	OrganizationalUnit string `yaml:"organizational_unit,omitempty"`
+}
+
+// RemoteSigner is a CFSSL server that signs the cluster certificates
+type RemoteSigner struct {
+	// The URL of the CFSSL server. For example: "https://cfssl.example.com:8888".
+	// +required
+	URL string
+	// The signing profile of the server to use. The default profile of the server
+	// is used when not set.
+	Profile string `yaml:"profile,omitempty"`
+	// The hex encoded key used to authenticate the signing requests, when the
+	// server requires authentication.
+	AuthKey string `yaml:"auth_key,omitempty"`
+}
+
+// usesIntermediateCA returns true if the cluster certificates are issued by an
+// existing intermediate CA
+func (c CertsConfig) usesIntermediateCA() bool {
+	return c.IntermediateCACert != ""
+}
+
+// signer returns the signer of the cluster certificates, or nil if the
+// certificates are signed locally
+func (c CertsConfig) signer() *tls.RemoteSigner {
+	if c.RemoteSigner == nil {
+		return nil
+	}
+	return &tls.RemoteSigner{
+		URL:     c.RemoteSigner.URL,
+		Profile: c.RemoteSigner.Profile,
+		AuthKey: c.RemoteSigner.AuthKey,
+	}
 }
 
 // SSHConfig describes the cluster's SSH configuration for accessing nodes
@@ -193,12 +376,60 @@ type SSHConfig struct {
 	// +required
 	User string
 	// The absolute path of the SSH key that should be used for accessing the
-	// cluster nodes via SSH.
+	// cluster nodes via SSH. The key can be encrypted, in which case the passphrase
+	// is read from the KISMATIC_SSH_KEY_PASSPHRASE environment variable, or
+	// prompted for. Not required when use_agent is set.
 	// +required
 	Key string `yaml:"ssh_key"`
 	// The port number on which cluster nodes are listening for SSH connections.
 	// +required
 	Port int `yaml:"ssh_port"`
+	// The SSH client used for accessing the cluster nodes. The external client
+	// runs the ssh binary found in the PATH. The native client does not depend
+	// on the ssh binary, and reuses a single connection per node.
+	// +options=external,native
+	// +default=external
+	Client string `yaml:"client,omitempty"`
+	// The maximum amount of time to wait for an SSH connection to be established,
+	// when using the native client.
+	// +default=10s
+	ConnectTimeout string `yaml:"connect_timeout,omitempty"`
+	// The maximum amount of time a command run over SSH is allowed to take,
+	// when using the native client. Commands do not time out when empty.
+	CommandTimeout string `yaml:"command_timeout,omitempty"`
+	// The bastion host through which the cluster nodes are accessed, when
+	// they are not directly reachable from the machine running KET.
+	Bastion *Bastion `yaml:"bastion,omitempty"`
+	// Verify the host keys of the nodes. The host keys are recorded in the
+	// known hosts file the first time the SSH connections to the nodes are
+	// validated, and are verified on every SSH connection afterwards.
+	// +default=false
+	StrictHostKeyChecking bool `yaml:"strict_host_key_checking,omitempty"`
+	// The file in which the host keys of the nodes are recorded, when strict
+	// host key checking is enabled. Defaults to the known_hosts file in the
+	// generated assets directory.
+	// +default=generated/known_hosts
+	KnownHostsFile string `yaml:"known_hosts_file,omitempty"`
+	// Authenticate with the keys of the SSH agent listening on SSH_AUTH_SOCK,
+	// in addition to the SSH key, if any.
+	// +default=false
+	UseAgent bool `yaml:"use_agent,omitempty"`
+}
+
+// Bastion is a host through which SSH connections to the cluster nodes are proxied
+type Bastion struct {
+	// Hostname or IP address of the bastion host.
+	// +required
+	Host string
+	// The user for accessing the bastion host via SSH.
+	// Defaults to the user of the cluster nodes.
+	User string `yaml:"user,omitempty"`
+	// The absolute path of the SSH key that should be used for accessing the
+	// bastion host via SSH. Defaults to the SSH key of the cluster nodes.
+	Key string `yaml:"ssh_key,omitempty"`
+	// The port number on which the bastion host is listening for SSH connections.
+	// +default=22
+	Port int `yaml:"ssh_port,omitempty"`
 }
 
 // CloudProvider controls the Kubernetes cloud providers feature
@@ -460,6 +691,15 @@ type Node struct {
 	// The internal (or private) IP address of the node.
 	// If set, this IP will be used when configuring cluster components.
 	InternalIP string
+	// The user for accessing the node via SSH.
+	// If set, it overrides the SSH user of the cluster.
+	SSHUser string `yaml:"ssh_user,omitempty"`
+	// The absolute path of the SSH key that should be used for accessing the node via SSH.
+	// If set, it overrides the SSH key of the cluster.
+	SSHKey string `yaml:"ssh_key,omitempty"`
+	// The port number on which the node is listening for SSH connections.
+	// If set, it overrides the SSH port of the cluster.
+	SSHPort int `yaml:"ssh_port,omitempty"`
 	// Labels to add when installing the node in the cluster.
 	// If a node is defined under multiple roles, the labels for that node will be merged.
 	// If a label is repeated for the same node,
@@ -623,16 +863,23 @@ func (p *Plan) GetSSHConnection(host string) (*SSHConnection, error) {
 		return nil, notFoundErr
 	}
 
-	return &SSHConnection{&p.Cluster.SSH, foundNode}, nil
+	return p.NodeSSHConnection(*foundNode), nil
+}
+
+// NodeSSHConnection returns the SSHConnection struct for the node, with the
+// SSH settings of the node overriding the ones of the cluster
+func (p *Plan) NodeSSHConnection(node Node) *SSHConnection {
+	sshConfig := p.Cluster.SSH.forNode(node)
+	return &SSHConnection{&sshConfig, &node}
 }
 
 // GetSSHClient is a convience method that calls GetSSHConnection and returns an SSH client with the result
-func (p *Plan) GetSSHClient(host string) (ssh.Client, error) {
+func (p *Plan) GetSSHClient(host string, generatedAssetsDir string) (ssh.Client, error) {
 	con, err := p.GetSSHConnection(host)
 	if err != nil {
 		return nil, err
 	}
-	client, err := ssh.NewClient(con.Node.IP, con.SSHConfig.Port, con.SSHConfig.User, con.SSHConfig.Key)
+	client, err := con.SSHConfig.newClient(*con.Node, generatedAssetsDir)
 	if err != nil {
 		return nil, fmt.Errorf("error creating SSH client for host %s: %v", host, err)
 	}
@@ -640,6 +887,132 @@ func (p *Plan) GetSSHClient(host string) (ssh.Client, error) {
 	return client, nil
 }
 
+// newClient returns an SSH client for the node, of the type selected in the SSH configuration
+func (s SSHConfig) newClient(node Node, generatedAssetsDir string) (ssh.Client, error) {
+	if s.Client != sshClientNative {
+		return ssh.NewClient(node.IP, s.Port, s.User, s.Key, s.clientOptions(generatedAssetsDir))
+	}
+	opts, err := s.nativeClientOptions(node, generatedAssetsDir)
+	if err != nil {
+		return nil, err
+	}
+	client, err := ssh.NewNativeClient(node.IP, s.Port, s.User, s.Key, opts)
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+func (s SSHConfig) clientOptions(generatedAssetsDir string) ssh.ClientOptions {
+	return ssh.ClientOptions{
+		Bastion:        s.bastion(),
+		KnownHostsFile: s.knownHostsFile(generatedAssetsDir),
+		UseAgent:       s.UseAgent,
+	}
+}
+
+func (s SSHConfig) nativeClientOptions(node Node, generatedAssetsDir string) (ssh.NativeClientOptions, error) {
+	opts := ssh.NativeClientOptions{NodeName: node.Host, ClientOptions: s.clientOptions(generatedAssetsDir)}
+	var err error
+	if s.ConnectTimeout != "" {
+		if opts.ConnectTimeout, err = time.ParseDuration(s.ConnectTimeout); err != nil {
+			return opts, fmt.Errorf("invalid SSH connect timeout %q: %v", s.ConnectTimeout, err)
+		}
+	}
+	if s.CommandTimeout != "" {
+		if opts.CommandTimeout, err = time.ParseDuration(s.CommandTimeout); err != nil {
+			return opts, fmt.Errorf("invalid SSH command timeout %q: %v", s.CommandTimeout, err)
+		}
+	}
+	return opts, nil
+}
+
+// knownHostsFile returns the absolute path of the file in which the host keys
+// are recorded. The file is in the generated assets directory, unless it is set
+// in the plan. Returns an empty string when host keys are not verified.
+func (s SSHConfig) knownHostsFile(generatedAssetsDir string) string {
+	if !s.StrictHostKeyChecking {
+		return ""
+	}
+	file := s.KnownHostsFile
+	if file == "" {
+		file = filepath.Join(generatedAssetsDir, knownHostsFilename)
+	}
+	if abs, err := filepath.Abs(file); err == nil {
+		file = abs
+	}
+	return file
+}
+
+// forNode returns the SSH configuration of the node, which is the
+// configuration of the cluster with the overrides of the node applied.
+// The bastion is shared by all the nodes, so it defaults to the user and key
+// of the cluster regardless of the overrides of the node.
+func (s SSHConfig) forNode(n Node) SSHConfig {
+	if b := s.bastion(); b != nil {
+		s.Bastion = &Bastion{Host: b.Host, User: b.User, Key: b.Key, Port: b.Port}
+	}
+	if n.SSHUser != "" {
+		s.User = n.SSHUser
+	}
+	if n.SSHKey != "" {
+		s.Key = n.SSHKey
+	}
+	if n.SSHPort != 0 {
+		s.Port = n.SSHPort
+	}
+	return s
+}
+
+// bastion returns the bastion host of the SSH configuration, with the
+// defaults applied. Returns nil if there is no bastion.
+func (s SSHConfig) bastion() *ssh.Bastion {
+	if s.Bastion == nil {
+		return nil
+	}
+	b := &ssh.Bastion{
+		Host: s.Bastion.Host,
+		Port: s.Bastion.Port,
+		User: s.Bastion.User,
+		Key:  s.Bastion.Key,
+	}
+	if b.Port == 0 {
+		b.Port = 22
+	}
+	if b.User == "" {
+		b.User = s.User
+	}
+	if b.Key == "" {
+		b.Key = s.Key
+	}
+	return b
+}
+
+// testConnection connects to the node and immediately exits. With strict host key
+// checking, the host key is recorded if the node is not known yet.
+func (s SSHConfig) testConnection(node Node, generatedAssetsDir string) error {
+	if !s.StrictHostKeyChecking {
+		client, err := s.newClient(node, generatedAssetsDir)
+		if err != nil {
+			return err
+		}
+		return client.Shell(false, "exit")
+	}
+	// The native client is used regardless of the configured client, as the
+	// ssh binary cannot be relied upon for recording the host keys
+	opts, err := s.nativeClientOptions(node, generatedAssetsDir)
+	if err != nil {
+		return err
+	}
+	opts.RecordHostKeys = true
+	client, err := ssh.NewNativeClient(node.IP, s.Port, s.User, s.Key, opts)
+	if err != nil {
+		return err
+	}
+	_, err = client.Output(false, "exit")
+	return err
+}
+
 func firstIfItExists(nodes []Node) *Node {
 	if len(nodes) > 0 {
 		return &nodes[0]
@@ -3,6 +3,9 @@ package install
 import (
 	"gopkg.in/yaml.v2"
 	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -13,3 +16,119 @@ func TestCanReadAPIServerOverrides(t *testing.T) {
 
 	assertEqual(t, p.Cluster.APIServerOptions.Overrides["runtime-config"], "beta/v2api=true,alpha/v1api=true")
 }
+
+func TestSSHConfigBastionDefaults(t *testing.T) {
+	s := SSHConfig{User: "alice", Key: "/keys/cluster", Port: 2222}
+	if b := s.bastion(); b != nil {
+		t.Errorf("expected no bastion, but got %+v", b)
+	}
+
+	s.Bastion = &Bastion{Host: "bastion.example.com"}
+	b := s.bastion()
+	if b.Host != "bastion.example.com" || b.User != "alice" || b.Key != "/keys/cluster" || b.Port != 22 {
+		t.Errorf("unexpected bastion defaults: %+v", b)
+	}
+
+	s.Bastion = &Bastion{Host: "bastion.example.com", User: "bob", Key: "/keys/bastion", Port: 2200}
+	b = s.bastion()
+	if b.User != "bob" || b.Key != "/keys/bastion" || b.Port != 2200 {
+		t.Errorf("expected bastion settings to be used, but got %+v", b)
+	}
+}
+
+func TestNodeSSHConnectionOverrides(t *testing.T) {
+	p := &Plan{}
+	p.Cluster.SSH = SSHConfig{User: "alice", Key: "/keys/cluster", Port: 22}
+	p.Master.Nodes = []Node{
+		{Host: "master01", IP: "10.0.0.1"},
+		{Host: "master02", IP: "10.0.0.2", SSHUser: "bob", SSHKey: "/keys/bob", SSHPort: 2222},
+	}
+
+	con, err := p.GetSSHConnection("master01")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *con.SSHConfig != p.Cluster.SSH {
+		t.Errorf("expected the cluster SSH config, but got %+v", con.SSHConfig)
+	}
+
+	con, err = p.GetSSHConnection("10.0.0.2")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if con.SSHConfig.User != "bob" || con.SSHConfig.Key != "/keys/bob" || con.SSHConfig.Port != 2222 {
+		t.Errorf("expected the node SSH overrides to be applied, but got %+v", con.SSHConfig)
+	}
+	if p.Cluster.SSH.User != "alice" {
+		t.Errorf("cluster SSH config was modified: %+v", p.Cluster.SSH)
+	}
+
+	inv := buildInventoryFromPlan(p, "generated")
+	n := inv.Roles[1].Nodes[1]
+	if n.SSHUser != "bob" || n.SSHPrivateKey != "/keys/bob" || n.SSHPort != 2222 {
+		t.Errorf("expected the node SSH overrides in the inventory, but got %+v", n)
+	}
+}
+
+func TestNodeSSHConnectionOverridesBastion(t *testing.T) {
+	p := &Plan{}
+	p.Cluster.SSH = SSHConfig{User: "alice", Key: "/keys/cluster", Port: 22, Bastion: &Bastion{Host: "bastion.example.com"}}
+	p.Master.Nodes = []Node{
+		{Host: "master01", IP: "10.0.0.1", SSHUser: "bob", SSHKey: "/keys/bob"},
+	}
+
+	// The bastion defaults to the cluster user and key, not to the node overrides
+	con, err := p.GetSSHConnection("master01")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if con.SSHConfig.User != "bob" {
+		t.Errorf("expected the node SSH overrides to be applied, but got %+v", con.SSHConfig)
+	}
+	b := con.SSHConfig.bastion()
+	if b.User != "alice" || b.Key != "/keys/cluster" {
+		t.Errorf("expected the bastion to use the cluster user and key, but got %+v", b)
+	}
+	if p.Cluster.SSH.Bastion.User != "" {
+		t.Errorf("cluster bastion config was modified: %+v", p.Cluster.SSH.Bastion)
+	}
+
+	inv := buildInventoryFromPlan(p, "generated")
+	n := inv.Roles[1].Nodes[0]
+	if !strings.Contains(n.SSHProxyCommand, "alice@bastion.example.com") {
+		t.Errorf("expected the proxy command to log into the bastion as alice, but got %q", n.SSHProxyCommand)
+	}
+}
+
+func TestSSHConfigKnownHostsFile(t *testing.T) {
+	s := SSHConfig{}
+	if f := s.knownHostsFile("generated"); f != "" {
+		t.Errorf("expected no known hosts file when host key checking is disabled, but got %q", f)
+	}
+
+	s.StrictHostKeyChecking = true
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("error getting working directory: %v", err)
+	}
+	if f := s.knownHostsFile("generated"); f != filepath.Join(wd, "generated", "known_hosts") {
+		t.Errorf("expected the known hosts file in the generated assets directory, but got %q", f)
+	}
+	if f := s.knownHostsFile("/tmp/assets"); f != "/tmp/assets/known_hosts" {
+		t.Errorf("expected the known hosts file in the generated assets directory, but got %q", f)
+	}
+	n := installNodeToAnsibleNode(&Node{Host: "etcd01", IP: "10.0.0.1"}, &s, "/tmp/assets")
+	if n.SSHKnownHostsFile != "/tmp/assets/known_hosts" {
+		t.Errorf("expected the known hosts file of the generated assets directory in the inventory, but got %q", n.SSHKnownHostsFile)
+	}
+
+	s.KnownHostsFile = "/etc/kismatic/known_hosts"
+	if f := s.knownHostsFile("generated"); f != "/etc/kismatic/known_hosts" {
+		t.Errorf("expected the configured known hosts file, but got %q", f)
+	}
+
+	n = installNodeToAnsibleNode(&Node{Host: "etcd01", IP: "10.0.0.1"}, &s, "generated")
+	if n.SSHKnownHostsFile != "/etc/kismatic/known_hosts" {
+		t.Errorf("expected the known hosts file in the inventory, but got %q", n.SSHKnownHostsFile)
+	}
+}
@@ -0,0 +1,265 @@
+package install
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/apprenda/kismatic/pkg/util"
+)
+
+type etcdNodeRemovalCountErr struct{}
+
+func (e etcdNodeRemovalCountErr) Error() string {
+	return "This node is part of an etcd cluster that will have less than 3 members after removing it. " +
+		"The etcd cluster will not be able to tolerate the failure of a member."
+}
+
+type masterNodeRemovalLoadBalancingErr struct{}
+
+func (e masterNodeRemovalLoadBalancingErr) Error() string {
+	return "This node is acting as the load balanced endpoint for the master nodes. " +
+		"Removing it will make the cluster unavailable."
+}
+
+type ingressNodeRemovalErr struct{}
+
+func (e ingressNodeRemovalErr) Error() string {
+	return "Removing this node may result in service unavailability if clients are accessing services directly through this ingress point."
+}
+
+type storageNodeRemovalErr struct{}
+
+func (e storageNodeRemovalErr) Error() string {
+	return "Removing this node may result in data loss if storage volumes have bricks on this node."
+}
+
+// DetectNodeRemovalSafety determines whether it's safe to remove a specific node
+// listed in the plan file. If any condition that could result in data or availability
+// loss is detected, the removal is deemed unsafe, and the conditions are returned as errors.
+func DetectNodeRemovalSafety(plan Plan, node Node, kubeClient upgradeKubeInfoClient) []error {
+	errs := []error{}
+	roles := plan.GetRolesForIP(node.IP)
+	for _, role := range roles {
+		switch role {
+		case "etcd":
+			if plan.Etcd.ExpectedCount <= 3 {
+				errs = append(errs, etcdNodeRemovalCountErr{})
+			}
+		case "master":
+			lbFQDN := plan.Master.LoadBalancedFQDN
+			if lbFQDN == node.Host || lbFQDN == node.IP {
+				errs = append(errs, masterNodeRemovalLoadBalancingErr{})
+			}
+		case "ingress":
+			errs = append(errs, ingressNodeRemovalErr{})
+		case "storage":
+			errs = append(errs, storageNodeRemovalErr{})
+		case "worker":
+			if workerErrs := detectWorkerNodeUpgradeSafety(node, kubeClient); workerErrs != nil {
+				errs = append(errs, workerErrs...)
+			}
+		}
+	}
+	return errs
+}
+
+// RemoveNode removes the node from the cluster described in the plan.
+// The node is drained, removed from the etcd clusters it is a member of,
+// and its services are stopped. If successful, the updated plan is returned.
+func (ae *ansibleExecutor) RemoveNode(originalPlan *Plan, node Node) (*Plan, error) {
+	if err := checkRemoveNodePrereqs(*originalPlan, node); err != nil {
+		return nil, err
+	}
+	roles := originalPlan.GetRolesForIP(node.IP)
+	isKubeNode := containsAny([]string{"master", "worker", "ingress", "storage"}, roles)
+	isEtcdNode := contains("etcd", roles)
+	updatedPlan := removeNodeFromPlan(*originalPlan, node)
+
+	// The plays that target the node being removed use the original inventory,
+	// while the plays that target the rest of the cluster use the updated one.
+	inventory := buildInventoryFromPlan(originalPlan, ae.options.GeneratedAssetsDirectory)
+	cc, err := ae.buildClusterCatalog(originalPlan)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate ansible vars: %v", err)
+	}
+	cc.NodeToRemove = node.Host
+	updatedInventory := buildInventoryFromPlan(&updatedPlan, ae.options.GeneratedAssetsDirectory)
+	updatedCC, err := ae.buildClusterCatalog(&updatedPlan)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate ansible vars: %v", err)
+	}
+	updatedCC.NodeToRemove = node.Host
+
+	if isKubeNode {
+		util.PrintHeader(ae.stdout, "Draining Node", '=')
+		t := task{
+			name:           "remove-node-drain",
+			playbook:       "_kube-drain-node.yaml",
+			plan:           *originalPlan,
+			inventory:      inventory,
+			clusterCatalog: *cc,
+			explainer:      ae.defaultExplainer(),
+			limit:          []string{node.Host},
+		}
+		if err = ae.execute(t); err != nil {
+			return nil, fmt.Errorf("error draining node: %v", err)
+		}
+	}
+
+	if isEtcdNode {
+		util.PrintHeader(ae.stdout, "Removing Etcd Member", '=')
+		t := task{
+			name:           "remove-node-etcd-member",
+			playbook:       "_etcd-member-remove.yaml",
+			plan:           updatedPlan,
+			inventory:      updatedInventory,
+			clusterCatalog: *updatedCC,
+			explainer:      ae.defaultExplainer(),
+		}
+		if err = ae.execute(t); err != nil {
+			return nil, fmt.Errorf("error removing etcd member: %v", err)
+		}
+	}
+
+	util.PrintHeader(ae.stdout, "Stopping Services On Node", '=')
+	t := task{
+		name:           "remove-node-stop-services",
+		playbook:       "_node-services-stop.yaml",
+		plan:           *originalPlan,
+		inventory:      inventory,
+		clusterCatalog: *cc,
+		explainer:      ae.defaultExplainer(),
+		limit:          []string{node.Host},
+	}
+	if err = ae.execute(t); err != nil {
+		return nil, fmt.Errorf("error stopping services on node: %v", err)
+	}
+
+	if isKubeNode {
+		util.PrintHeader(ae.stdout, "Deleting Node From Cluster", '=')
+		t = task{
+			name:           "remove-node-delete",
+			playbook:       "_kube-delete-node.yaml",
+			plan:           updatedPlan,
+			inventory:      updatedInventory,
+			clusterCatalog: *updatedCC,
+			explainer:      ae.defaultExplainer(),
+		}
+		if err = ae.execute(t); err != nil {
+			return nil, fmt.Errorf("error deleting node from cluster: %v", err)
+		}
+	}
+
+	if !originalPlan.Cluster.DisablePackageInstallation {
+		util.PrintHeader(ae.stdout, "Removing Packages From Node", '=')
+		t = task{
+			name:           "remove-node-packages-cleanup",
+			playbook:       "_packages-cleanup.yaml",
+			plan:           *originalPlan,
+			inventory:      inventory,
+			clusterCatalog: *cc,
+			explainer:      ae.defaultExplainer(),
+			limit:          []string{node.Host},
+		}
+		if err = ae.execute(t); err != nil {
+			return nil, fmt.Errorf("error removing packages from node: %v", err)
+		}
+	}
+
+	// We need to run ansible against all hosts to update the hosts files
+	if err = ae.updateHostsFiles("remove-node-update-hosts", updatedPlan, updatedInventory, *updatedCC); err != nil {
+		return nil, err
+	}
+
+	// The API server count and the etcd endpoints are set on every master
+	if contains("master", roles) || isEtcdNode {
+		util.PrintHeader(ae.stdout, "Reconfiguring Control Plane", '=')
+		t = task{
+			name:           "remove-node-reconfigure-control-plane",
+			playbook:       "reconfigure-control-plane.yaml",
+			plan:           updatedPlan,
+			inventory:      updatedInventory,
+			clusterCatalog: *updatedCC,
+			explainer:      ae.defaultExplainer(),
+		}
+		if err = ae.execute(t); err != nil {
+			return nil, fmt.Errorf("error reconfiguring the control plane: %v", err)
+		}
+	}
+	if isEtcdNode && updatedCC.CNI.Enabled && updatedCC.CNI.Provider == cniProviderCalico {
+		util.PrintHeader(ae.stdout, "Reconfiguring Cluster Network", '=')
+		t = task{
+			name:           "remove-node-reconfigure-networking",
+			playbook:       "reconfigure-networking.yaml",
+			plan:           updatedPlan,
+			inventory:      updatedInventory,
+			clusterCatalog: *updatedCC,
+			explainer:      ae.defaultExplainer(),
+		}
+		if err = ae.execute(t); err != nil {
+			return nil, fmt.Errorf("error reconfiguring the cluster network: %v", err)
+		}
+	}
+
+	if !ae.options.DryRun {
+		util.PrintHeader(ae.stdout, "Archiving Node Certificates", '=')
+		if _, err = ae.pki.ArchiveNodeCertificates(originalPlan, node); err != nil {
+			return nil, fmt.Errorf("error archiving node certificates: %v", err)
+		}
+	}
+	return &updatedPlan, nil
+}
+
+// ensure the node can be removed without leaving the plan in an invalid state
+func checkRemoveNodePrereqs(plan Plan, node Node) error {
+	roles := plan.GetRolesForIP(node.IP)
+	if len(roles) == 0 {
+		return fmt.Errorf("node %q was not found in the plan", node.Host)
+	}
+	if contains("etcd", roles) && len(plan.Etcd.Nodes) < 2 {
+		return errors.New("This is the only etcd node in the cluster, and it cannot be removed.")
+	}
+	if contains("master", roles) && len(plan.Master.Nodes) < 2 {
+		return errors.New("This is the only master node in the cluster, and it cannot be removed.")
+	}
+	if contains("worker", roles) && len(plan.Worker.Nodes) < 2 {
+		return errors.New("This is the only worker node in the cluster, and it cannot be removed.")
+	}
+	return nil
+}
+
+// removes the node from every group it belongs to
+func removeNodeFromPlan(plan Plan, node Node) Plan {
+	if hasIP(&plan.Etcd.Nodes, node.IP) {
+		plan.Etcd.Nodes = removeNode(plan.Etcd.Nodes, node)
+		plan.Etcd.ExpectedCount--
+	}
+	if hasIP(&plan.Master.Nodes, node.IP) {
+		plan.Master.Nodes = removeNode(plan.Master.Nodes, node)
+		plan.Master.ExpectedCount--
+	}
+	if hasIP(&plan.Worker.Nodes, node.IP) {
+		plan.Worker.Nodes = removeNode(plan.Worker.Nodes, node)
+		plan.Worker.ExpectedCount--
+	}
+	if hasIP(&plan.Ingress.Nodes, node.IP) {
+		plan.Ingress.Nodes = removeNode(plan.Ingress.Nodes, node)
+		plan.Ingress.ExpectedCount--
+	}
+	if hasIP(&plan.Storage.Nodes, node.IP) {
+		plan.Storage.Nodes = removeNode(plan.Storage.Nodes, node)
+		plan.Storage.ExpectedCount--
+	}
+	return plan
+}
+
+// returns a new slice that does not contain the node
+func removeNode(nodes []Node, node Node) []Node {
+	remaining := []Node{}
+	for _, n := range nodes {
+		if n.IP != node.IP {
+			remaining = append(remaining, n)
+		}
+	}
+	return remaining
+}
@@ -0,0 +1,222 @@
+package install
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/apprenda/kismatic/pkg/ansible"
+	"github.com/apprenda/kismatic/pkg/install/explain"
+)
+
+func getRemoveNodePlan() *Plan {
+	return &Plan{
+		Etcd: NodeGroup{
+			ExpectedCount: 3,
+			Nodes: []Node{
+				{Host: "etcd01", IP: "10.0.0.1"},
+				{Host: "etcd02", IP: "10.0.0.2"},
+				{Host: "etcd03", IP: "10.0.0.3"},
+			},
+		},
+		Master: MasterNodeGroup{
+			ExpectedCount: 2,
+			Nodes: []Node{
+				{Host: "master01", IP: "10.0.0.4"},
+				{Host: "master02", IP: "10.0.0.5"},
+			},
+		},
+		Worker: NodeGroup{
+			ExpectedCount: 2,
+			Nodes: []Node{
+				{Host: "worker01", IP: "10.0.0.6"},
+				{Host: "master02", IP: "10.0.0.5"},
+			},
+		},
+		Cluster: Cluster{
+			Networking: NetworkConfig{
+				ServiceCIDRBlock: "10.0.0.0/16",
+			},
+		},
+		AddOns: AddOns{
+			CNI: &CNI{Provider: cniProviderCalico},
+		},
+	}
+}
+
+func TestRemoveNodeOnlyMasterNode(t *testing.T) {
+	e := ansibleExecutor{
+		options:                ExecutorOptions{RunsDirectory: mustGetTempDir(t)},
+		stdout:                 ioutil.Discard,
+		consoleOutputFormat:    ansible.RawFormat,
+		pki:                    &fakePKI{},
+		runnerExplainerFactory: fakeRunnerExplainer(nil),
+		certsDir:               mustGetTempDir(t),
+	}
+	plan := getRemoveNodePlan()
+	plan.Master.ExpectedCount = 1
+	plan.Master.Nodes = plan.Master.Nodes[:1]
+	updatedPlan, err := e.RemoveNode(plan, plan.Master.Nodes[0])
+	if err == nil {
+		t.Errorf("expected an error, but didn't get one")
+	}
+	if updatedPlan != nil {
+		t.Error("plan was updated, even though the node cannot be removed")
+	}
+}
+
+func TestRemoveNodeNotInPlan(t *testing.T) {
+	e := ansibleExecutor{
+		options:                ExecutorOptions{RunsDirectory: mustGetTempDir(t)},
+		stdout:                 ioutil.Discard,
+		consoleOutputFormat:    ansible.RawFormat,
+		pki:                    &fakePKI{},
+		runnerExplainerFactory: fakeRunnerExplainer(nil),
+		certsDir:               mustGetTempDir(t),
+	}
+	_, err := e.RemoveNode(getRemoveNodePlan(), Node{Host: "foo", IP: "10.0.0.100"})
+	if err == nil {
+		t.Errorf("expected an error, but didn't get one")
+	}
+}
+
+func TestRemoveNodePlanIsUpdated(t *testing.T) {
+	fakeRunner := fakeRunner{}
+	pki := &fakePKI{}
+	e := ansibleExecutor{
+		options:             ExecutorOptions{RunsDirectory: mustGetTempDir(t)},
+		stdout:              ioutil.Discard,
+		consoleOutputFormat: ansible.RawFormat,
+		pki:                 pki,
+		runnerExplainerFactory: func(explain.AnsibleEventExplainer, io.Writer) (ansible.Runner, *explain.AnsibleEventStreamExplainer, error) {
+			return &fakeRunner, &explain.AnsibleEventStreamExplainer{}, nil
+		},
+		certsDir: mustGetTempDir(t),
+	}
+	plan := getRemoveNodePlan()
+	node := plan.Master.Nodes[1]
+	updatedPlan, err := e.RemoveNode(plan, node)
+	if err != nil {
+		t.Fatalf("unexpected error while removing node: %v", err)
+	}
+	if updatedPlan.Master.ExpectedCount != 1 || len(updatedPlan.Master.Nodes) != 1 {
+		t.Errorf("the node was not removed from the master group")
+	}
+	if updatedPlan.Worker.ExpectedCount != 1 || len(updatedPlan.Worker.Nodes) != 1 {
+		t.Errorf("the node was not removed from the worker group")
+	}
+	if updatedPlan.Etcd.ExpectedCount != 3 {
+		t.Errorf("the etcd group was modified")
+	}
+	// the original plan must not be modified
+	if len(plan.Master.Nodes) != 2 || len(plan.Worker.Nodes) != 2 {
+		t.Errorf("the original plan was modified")
+	}
+	if !pki.archiveNodeCertsCalled {
+		t.Errorf("the node certificates were not archived")
+	}
+	expected := []string{"_kube-delete-node.yaml", "reconfigure-control-plane.yaml"}
+	for _, e := range expected {
+		found := false
+		for _, p := range fakeRunner.allNodesPlaybooks {
+			if p == e {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("expected playbook %s was not run during remove-node. The following plays ran: %v", e, fakeRunner.allNodesPlaybooks)
+		}
+	}
+	for _, p := range fakeRunner.allNodesPlaybooks {
+		if p == "_etcd-member-remove.yaml" {
+			t.Errorf("etcd member was removed, even though the node is not an etcd node")
+		}
+	}
+}
+
+func TestRemoveNodeEtcdMemberRemoved(t *testing.T) {
+	fakeRunner := fakeRunner{}
+	e := ansibleExecutor{
+		options:             ExecutorOptions{RunsDirectory: mustGetTempDir(t)},
+		stdout:              ioutil.Discard,
+		consoleOutputFormat: ansible.RawFormat,
+		pki:                 &fakePKI{},
+		runnerExplainerFactory: func(explain.AnsibleEventExplainer, io.Writer) (ansible.Runner, *explain.AnsibleEventStreamExplainer, error) {
+			return &fakeRunner, &explain.AnsibleEventStreamExplainer{}, nil
+		},
+		certsDir: mustGetTempDir(t),
+	}
+	plan := getRemoveNodePlan()
+	updatedPlan, err := e.RemoveNode(plan, plan.Etcd.Nodes[0])
+	if err != nil {
+		t.Fatalf("unexpected error while removing node: %v", err)
+	}
+	if updatedPlan.Etcd.ExpectedCount != 2 || len(updatedPlan.Etcd.Nodes) != 2 {
+		t.Errorf("the node was not removed from the etcd group")
+	}
+	expected := []string{"_etcd-member-remove.yaml", "reconfigure-control-plane.yaml", "reconfigure-networking.yaml"}
+	for _, e := range expected {
+		found := false
+		for _, p := range fakeRunner.allNodesPlaybooks {
+			if p == e {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("expected playbook %s was not run during remove-node. The following plays ran: %v", e, fakeRunner.allNodesPlaybooks)
+		}
+	}
+}
+
+func TestRemoveNodePlanNotUpdatedAfterFailure(t *testing.T) {
+	pki := &fakePKI{}
+	e := ansibleExecutor{
+		options:                ExecutorOptions{RunsDirectory: mustGetTempDir(t)},
+		stdout:                 ioutil.Discard,
+		consoleOutputFormat:    ansible.RawFormat,
+		pki:                    pki,
+		runnerExplainerFactory: fakeRunnerExplainer(errors.New("exec error")),
+		certsDir:               mustGetTempDir(t),
+	}
+	plan := getRemoveNodePlan()
+	updatedPlan, err := e.RemoveNode(plan, plan.Worker.Nodes[0])
+	if err == nil {
+		t.Errorf("expected an error, but didn't get one")
+	}
+	if updatedPlan != nil {
+		t.Error("plan was updated, even though removing the node failed")
+	}
+	if pki.archiveNodeCertsCalled {
+		t.Error("certificates were archived, even though removing the node failed")
+	}
+}
+
+func TestDetectNodeRemovalSafetyEtcdCountUnsafe(t *testing.T) {
+	plan := getRemoveNodePlan()
+	errs := DetectNodeRemovalSafety(*plan, plan.Etcd.Nodes[0], fakeUpgradeKubeClient{})
+	if len(errs) != 1 {
+		t.Errorf("Expected %d errors, but got %v", 1, errs)
+	} else if _, ok := errs[0].(etcdNodeRemovalCountErr); !ok {
+		t.Errorf("Expected etcdNodeRemovalCountErr, but got %v", errs[0])
+	}
+}
+
+func TestDetectNodeRemovalSafetyMasterLoadBalancingUnsafe(t *testing.T) {
+	plan := getRemoveNodePlan()
+	plan.Master.LoadBalancedFQDN = "master01"
+	errs := DetectNodeRemovalSafety(*plan, plan.Master.Nodes[0], fakeUpgradeKubeClient{})
+	if len(errs) != 1 {
+		t.Errorf("Expected %d errors, but got %v", 1, errs)
+	} else if _, ok := errs[0].(masterNodeRemovalLoadBalancingErr); !ok {
+		t.Errorf("Expected masterNodeRemovalLoadBalancingErr, but got %v", errs[0])
+	}
+}
+
+func TestDetectNodeRemovalSafetyWorkerSafe(t *testing.T) {
+	plan := getRemoveNodePlan()
+	errs := DetectNodeRemovalSafety(*plan, plan.Worker.Nodes[0], fakeUpgradeKubeClient{})
+	if len(errs) != 0 {
+		t.Errorf("Expected no errors, but got %v", errs)
+	}
+}
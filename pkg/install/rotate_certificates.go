@@ -0,0 +1,142 @@
+package install
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/apprenda/kismatic/pkg/util"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const certificateRotationFilename = "rotation-in-progress.yaml"
+
+// certificateRotation records the progress of a certificate rotation, so that a
+// rotation that failed on a node can be resumed with the certificates that were
+// already deployed to the previous nodes
+type certificateRotation struct {
+	// ArchiveDirectory contains the certificates that were replaced
+	ArchiveDirectory string `yaml:"archive_directory"`
+	// DeployedNodes are the nodes on which the new certificates were deployed
+	DeployedNodes []string `yaml:"deployed_nodes"`
+}
+
+// readCertificateRotation returns the rotation in progress, or nil if there is none
+func readCertificateRotation(file string) (*certificateRotation, error) {
+	b, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading certificate rotation file: %v", err)
+	}
+	r := &certificateRotation{}
+	if err := yaml.Unmarshal(b, r); err != nil {
+		return nil, fmt.Errorf("error parsing certificate rotation file %q: %v", file, err)
+	}
+	return r, nil
+}
+
+func (r certificateRotation) write(file string) error {
+	b, err := yaml.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("error marshalling certificate rotation: %v", err)
+	}
+	if err := ioutil.WriteFile(file, b, 0644); err != nil {
+		return fmt.Errorf("error writing certificate rotation file: %v", err)
+	}
+	return nil
+}
+
+// RotateCertificates generates new certificates for the cluster using the existing
+// CA, and deploys them one node at a time. The components that use the certificates
+// are restarted on each node, and their health is verified before moving on to
+// the next node. When a previous rotation did not complete, the certificates are
+// not generated again, and the nodes that already received them are skipped.
+func (ae *ansibleExecutor) RotateCertificates(plan Plan) error {
+	caExists, err := ae.pki.CertificateAuthorityExists(&plan)
+	if err != nil {
+		return fmt.Errorf("error while checking if cluster CA exists: %v", err)
+	}
+	if !caExists {
+		return errMissingClusterCA
+	}
+	ca, err := ae.pki.GetClusterCA(&plan)
+	if err != nil {
+		return err
+	}
+
+	util.PrintHeader(ae.stdout, "Rotating Certificates", '=')
+	rotationFile := filepath.Join(ae.certsDir, certificateRotationFilename)
+	rotation, err := readCertificateRotation(rotationFile)
+	if err != nil {
+		return err
+	}
+	if rotation != nil {
+		util.PrettyPrintOk(ae.stdout, "Resuming the previous rotation, the previous certificates were archived in %q", rotation.ArchiveDirectory)
+	} else {
+		archiveDir, err := ae.pki.RotateClusterCertificates(&plan, ca)
+		if err != nil {
+			return fmt.Errorf("error generating certificates for the cluster: %v", err)
+		}
+		rotation = &certificateRotation{ArchiveDirectory: archiveDir}
+		if err := rotation.write(rotationFile); err != nil {
+			return err
+		}
+		util.PrettyPrintOk(ae.stdout, "The previous certificates were archived in %q", archiveDir)
+	}
+
+	inventory := buildInventoryFromPlan(&plan, ae.options.GeneratedAssetsDirectory)
+	cc, err := ae.buildClusterCatalog(&plan)
+	if err != nil {
+		return err
+	}
+	cc.ForceEtcdRestart = true
+	cc.ForceKubeletRestart = true
+	for _, node := range nodesInRotationOrder(plan) {
+		util.PrintHeader(ae.stdout, fmt.Sprintf("Deploy Certificates: %s %v", node.Host, plan.GetRolesForIP(node.IP)), '=')
+		if contains(node.Host, rotation.DeployedNodes) {
+			util.PrettyPrintOk(ae.stdout, "The certificates were deployed during the previous run, skipping node")
+			continue
+		}
+		t := task{
+			name:           "rotate-certificates",
+			playbook:       "rotate-certificates.yaml",
+			inventory:      inventory,
+			clusterCatalog: *cc,
+			plan:           plan,
+			explainer:      ae.defaultExplainer(),
+			limit:          []string{node.Host},
+		}
+		if err := ae.execute(t); err != nil {
+			return fmt.Errorf("error deploying certificates to node %q: %v", node.Host, err)
+		}
+		rotation.DeployedNodes = append(rotation.DeployedNodes, node.Host)
+		if err := rotation.write(rotationFile); err != nil {
+			return err
+		}
+	}
+	if err := os.Remove(rotationFile); err != nil {
+		return fmt.Errorf("error removing certificate rotation file: %v", err)
+	}
+	return nil
+}
+
+// nodesInRotationOrder returns the unique nodes of the cluster in the order in
+// which the certificates are deployed: the etcd nodes first, then the master
+// nodes, then the rest of the nodes.
+func nodesInRotationOrder(plan Plan) []Node {
+	nodes := []Node{}
+	seen := map[string]bool{}
+	for _, group := range [][]Node{plan.Etcd.Nodes, plan.Master.Nodes, plan.Worker.Nodes, plan.Ingress.Nodes, plan.Storage.Nodes} {
+		for _, n := range group {
+			if seen[n.HashCode()] {
+				continue
+			}
+			seen[n.HashCode()] = true
+			nodes = append(nodes, n)
+		}
+	}
+	return nodes
+}
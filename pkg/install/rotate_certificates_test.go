@@ -0,0 +1,138 @@
+package install
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/apprenda/kismatic/pkg/ansible"
+	"github.com/apprenda/kismatic/pkg/install/explain"
+)
+
+func TestRotateCertificatesCAMissing(t *testing.T) {
+	pki := &fakePKI{}
+	e := ansibleExecutor{
+		options:             ExecutorOptions{RunsDirectory: mustGetTempDir(t)},
+		stdout:              ioutil.Discard,
+		consoleOutputFormat: ansible.RawFormat,
+		pki:                 pki,
+		certsDir:            mustGetTempDir(t),
+	}
+	if err := e.RotateCertificates(Plan{}); err != errMissingClusterCA {
+		t.Errorf("RotateCertificates did not return the expected error. Instead returned: %v", err)
+	}
+	if pki.rotateCertsCalled {
+		t.Errorf("certificates were rotated without a CA")
+	}
+}
+
+func TestRotateCertificatesNodeByNode(t *testing.T) {
+	fakeRunner := fakeRunner{}
+	pki := &fakePKI{caExists: true}
+	e := ansibleExecutor{
+		options:             ExecutorOptions{RunsDirectory: mustGetTempDir(t)},
+		stdout:              ioutil.Discard,
+		consoleOutputFormat: ansible.RawFormat,
+		pki:                 pki,
+		runnerExplainerFactory: func(explain.AnsibleEventExplainer, io.Writer) (ansible.Runner, *explain.AnsibleEventStreamExplainer, error) {
+			return &fakeRunner, &explain.AnsibleEventStreamExplainer{}, nil
+		},
+		certsDir: mustGetTempDir(t),
+	}
+	plan := rotationTestPlan()
+	if err := e.RotateCertificates(plan); err != nil {
+		t.Fatalf("unexpected error rotating certificates: %v", err)
+	}
+	if !pki.rotateCertsCalled {
+		t.Errorf("certificates were not rotated")
+	}
+	expected := [][]string{{"etcd01"}, {"etcd02"}, {"master01"}, {"worker01"}, {"ingress01"}}
+	if !reflect.DeepEqual(fakeRunner.limitedNodes, expected) {
+		t.Errorf("expected the certificates to be deployed to %v, but they were deployed to %v", expected, fakeRunner.limitedNodes)
+	}
+	if !fakeRunner.incomingCatalog.ForceEtcdRestart || !fakeRunner.incomingCatalog.ForceKubeletRestart {
+		t.Errorf("expected etcd and kubelet to be restarted")
+	}
+}
+
+// failingNodeRunner fails the playbooks that are run on the node
+type failingNodeRunner struct {
+	fakeRunner
+	failNode string
+}
+
+func (f *failingNodeRunner) StartPlaybookOnNode(playbookFile string, inventory ansible.Inventory, cc ansible.ClusterCatalog, node ...string) (<-chan ansible.Event, error) {
+	f.limitedNodes = append(f.limitedNodes, node)
+	if len(node) == 1 && node[0] == f.failNode {
+		return nil, errors.New("playbook failed")
+	}
+	return f.eventChan, nil
+}
+
+func TestRotateCertificatesResume(t *testing.T) {
+	runner := &failingNodeRunner{failNode: "master01"}
+	pki := &fakePKI{caExists: true}
+	certsDir := mustGetTempDir(t)
+	e := ansibleExecutor{
+		options:             ExecutorOptions{RunsDirectory: mustGetTempDir(t)},
+		stdout:              ioutil.Discard,
+		consoleOutputFormat: ansible.RawFormat,
+		pki:                 pki,
+		runnerExplainerFactory: func(explain.AnsibleEventExplainer, io.Writer) (ansible.Runner, *explain.AnsibleEventStreamExplainer, error) {
+			return runner, &explain.AnsibleEventStreamExplainer{}, nil
+		},
+		certsDir: certsDir,
+	}
+	plan := rotationTestPlan()
+	if err := e.RotateCertificates(plan); err == nil {
+		t.Fatal("expected an error when the deployment fails on a node")
+	}
+	if !pki.rotateCertsCalled {
+		t.Errorf("certificates were not rotated")
+	}
+
+	// The rotation is resumed from the node that failed, with the same certificates
+	pki.rotateCertsCalled = false
+	runner.failNode = ""
+	runner.limitedNodes = nil
+	if err := e.RotateCertificates(plan); err != nil {
+		t.Fatalf("unexpected error resuming the rotation: %v", err)
+	}
+	if pki.rotateCertsCalled {
+		t.Errorf("certificates were rotated again when resuming")
+	}
+	expected := [][]string{{"master01"}, {"worker01"}, {"ingress01"}}
+	if !reflect.DeepEqual(runner.limitedNodes, expected) {
+		t.Errorf("expected the certificates to be deployed to %v, but they were deployed to %v", expected, runner.limitedNodes)
+	}
+	if _, err := os.Stat(filepath.Join(certsDir, certificateRotationFilename)); !os.IsNotExist(err) {
+		t.Errorf("expected the rotation file to be removed once the rotation is complete")
+	}
+}
+
+func rotationTestPlan() Plan {
+	return Plan{
+		Cluster: Cluster{
+			Networking: NetworkConfig{
+				ServiceCIDRBlock: "10.0.0.0/16",
+			},
+		},
+		Etcd: NodeGroup{
+			Nodes: []Node{{Host: "etcd01", IP: "10.0.0.1"}, {Host: "etcd02", IP: "10.0.0.2"}},
+		},
+		Master: MasterNodeGroup{
+			Nodes: []Node{{Host: "master01", IP: "10.0.0.3", InternalIP: "10.10.2.20"}},
+		},
+		Worker: NodeGroup{
+			// the master is also a worker
+			Nodes: []Node{{Host: "worker01", IP: "10.0.0.4"}, {Host: "master01", IP: "10.0.0.3", InternalIP: "10.10.2.20"}},
+		},
+		Ingress: OptionalNodeGroup{
+			Nodes: []Node{{Host: "ingress01", IP: "10.0.0.5"}},
+		},
+	}
+}
@@ -0,0 +1,96 @@
+package install
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/apprenda/kismatic/pkg/util"
+)
+
+var errSecretsEncryptionDisabled = errors.New("secrets encryption is not enabled in the plan file")
+
+// RotateEncryptionKey replaces the key used to encrypt the Kubernetes secrets.
+// The new key is first added to the encryption config of all the API servers,
+// and only becomes the encryption key once all of them can decrypt with it.
+// All the secrets are then re-encrypted with the new key, and the previous keys
+// are removed. The previous keys are kept until the secrets are re-encrypted,
+// so a failed rotation can be run again.
+func (ae *ansibleExecutor) RotateEncryptionKey(plan Plan) error {
+	if plan.Cluster.SecretsEncryption == nil || !plan.Cluster.SecretsEncryption.Enabled {
+		return errSecretsEncryptionDisabled
+	}
+	file, err := filepath.Abs(EncryptionConfigFile(ae.options.GeneratedAssetsDirectory))
+	if err != nil {
+		return fmt.Errorf("failed to determine absolute path to %s: %v", encryptionConfigFilename, err)
+	}
+	keys, err := readEncryptionKeys(file)
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf("encryption config file %q was not found, the cluster must be installed with secrets encryption enabled", file)
+	}
+	newKey, err := newEncryptionKey(plan.Cluster.SecretsEncryption.Provider, keys)
+	if err != nil {
+		return err
+	}
+
+	util.PrintHeader(ae.stdout, "Rotating Encryption Key", '=')
+	steps := []struct {
+		header string
+		keys   []encryptionKey
+	}{
+		{header: "Add New Encryption Key", keys: append(append([]encryptionKey{}, keys...), newKey)},
+		{header: "Encrypt With New Encryption Key", keys: append([]encryptionKey{newKey}, keys...)},
+	}
+	for _, s := range steps {
+		if err := ae.deployEncryptionConfig(plan, file, s.header, s.keys); err != nil {
+			return err
+		}
+	}
+
+	util.PrintHeader(ae.stdout, "Re-encrypt Secrets", '=')
+	cc, err := ae.buildClusterCatalog(&plan)
+	if err != nil {
+		return err
+	}
+	t := task{
+		name:           "rotate-encryption-key",
+		playbook:       "_secrets-reencrypt.yaml",
+		inventory:      buildInventoryFromPlan(&plan, ae.options.GeneratedAssetsDirectory),
+		clusterCatalog: *cc,
+		plan:           plan,
+		explainer:      ae.defaultExplainer(),
+	}
+	if err := ae.execute(t); err != nil {
+		return fmt.Errorf("error re-encrypting secrets: %v", err)
+	}
+
+	return ae.deployEncryptionConfig(plan, file, "Remove Previous Encryption Keys", []encryptionKey{newKey})
+}
+
+// deployEncryptionConfig writes the encryption config with the keys, and
+// deploys it to the master nodes one at a time
+func (ae *ansibleExecutor) deployEncryptionConfig(plan Plan, file string, header string, keys []encryptionKey) error {
+	util.PrintHeader(ae.stdout, header, '=')
+	if err := writeEncryptionConfig(file, keys); err != nil {
+		return err
+	}
+	cc, err := ae.buildClusterCatalog(&plan)
+	if err != nil {
+		return err
+	}
+	t := task{
+		name:           "rotate-encryption-key",
+		playbook:       "reconfigure-control-plane.yaml",
+		inventory:      buildInventoryFromPlan(&plan, ae.options.GeneratedAssetsDirectory),
+		clusterCatalog: *cc,
+		plan:           plan,
+		explainer:      ae.defaultExplainer(),
+	}
+	if err := ae.execute(t); err != nil {
+		return fmt.Errorf("error deploying the encryption config: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package install
+
+import (
+	"io"
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"github.com/apprenda/kismatic/pkg/ansible"
+	"github.com/apprenda/kismatic/pkg/install/explain"
+)
+
+func encryptionTestPlan() Plan {
+	return Plan{
+		Cluster: Cluster{
+			Networking: NetworkConfig{
+				ServiceCIDRBlock: "10.0.0.0/16",
+			},
+			SecretsEncryption: &SecretsEncryption{Enabled: true, Provider: encryptionProviderAESCBC},
+		},
+		Master: MasterNodeGroup{
+			Nodes: []Node{{Host: "master01", IP: "10.0.0.3"}, {Host: "master02", IP: "10.0.0.4"}},
+		},
+	}
+}
+
+func TestRotateEncryptionKeyDisabled(t *testing.T) {
+	e := ansibleExecutor{
+		options: ExecutorOptions{GeneratedAssetsDirectory: mustGetTempDir(t)},
+		stdout:  ioutil.Discard,
+	}
+	plan := encryptionTestPlan()
+	plan.Cluster.SecretsEncryption.Enabled = false
+	if err := e.RotateEncryptionKey(plan); err != errSecretsEncryptionDisabled {
+		t.Errorf("RotateEncryptionKey did not return the expected error. Instead returned: %v", err)
+	}
+}
+
+func TestRotateEncryptionKeyMissingConfig(t *testing.T) {
+	e := ansibleExecutor{
+		options: ExecutorOptions{GeneratedAssetsDirectory: mustGetTempDir(t)},
+		stdout:  ioutil.Discard,
+	}
+	if err := e.RotateEncryptionKey(encryptionTestPlan()); err == nil {
+		t.Errorf("expected an error when the encryption config does not exist")
+	}
+}
+
+func TestRotateEncryptionKey(t *testing.T) {
+	fakeRunner := fakeRunner{}
+	generatedDir := mustGetTempDir(t)
+	e := ansibleExecutor{
+		options:             ExecutorOptions{GeneratedAssetsDirectory: generatedDir, RunsDirectory: mustGetTempDir(t)},
+		stdout:              ioutil.Discard,
+		consoleOutputFormat: ansible.RawFormat,
+		runnerExplainerFactory: func(explain.AnsibleEventExplainer, io.Writer) (ansible.Runner, *explain.AnsibleEventStreamExplainer, error) {
+			return &fakeRunner, &explain.AnsibleEventStreamExplainer{}, nil
+		},
+		certsDir: mustGetTempDir(t),
+	}
+	plan := encryptionTestPlan()
+	if err := GenerateSecretsEncryptionConfig(&plan, generatedDir); err != nil {
+		t.Fatalf("error creating encryption config: %v", err)
+	}
+	file, err := encryptionConfigFile(generatedDir, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := e.RotateEncryptionKey(plan); err != nil {
+		t.Fatalf("unexpected error rotating the encryption key: %v", err)
+	}
+	expected := []string{"reconfigure-control-plane.yaml", "reconfigure-control-plane.yaml", "_secrets-reencrypt.yaml", "reconfigure-control-plane.yaml"}
+	if !reflect.DeepEqual(fakeRunner.allNodesPlaybooks, expected) {
+		t.Errorf("expected playbooks %v, but got %v", expected, fakeRunner.allNodesPlaybooks)
+	}
+	keys, err := readEncryptionKeys(file)
+	if err != nil {
+		t.Fatalf("error reading encryption keys: %v", err)
+	}
+	if len(keys) != 1 || keys[0].Name != "key2" {
+		t.Errorf("expected only the new key2 to remain, but got %v", keys)
+	}
+}
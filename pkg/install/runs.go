@@ -0,0 +1,292 @@
+package install
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apprenda/kismatic/pkg/ansible"
+)
+
+const (
+	runMetadataFilename = "run.json"
+	runPlanFilename     = "kismatic-cluster.yaml"
+	runTimestampFormat  = "2006-01-02-15-04-05"
+
+	// RunResultRunning is the result of a run that has not finished
+	RunResultRunning = "running"
+	// RunResultSuccess is the result of a run that completed successfully
+	RunResultSuccess = "success"
+	// RunResultFailure is the result of a run that failed
+	RunResultFailure = "failure"
+	// RunResultDryRun is the result of a run that was not performed, because
+	// the executor was in dry run mode
+	RunResultDryRun = "dry-run"
+	// RunResultUnknown is the result of a run that has no metadata
+	RunResultUnknown = "unknown"
+)
+
+// RunMetadata describes a single execution of a playbook
+type RunMetadata struct {
+	Operation   string       `json:"operation"`
+	Playbook    string       `json:"playbook,omitempty"`
+	Limit       []string     `json:"limit,omitempty"`
+	Start       time.Time    `json:"start"`
+	End         *time.Time   `json:"end,omitempty"`
+	Result      string       `json:"result"`
+	Error       string       `json:"error,omitempty"`
+	FailedHosts []string     `json:"failedHosts,omitempty"`
+	FailedTasks []FailedTask `json:"failedTasks,omitempty"`
+}
+
+// FailedTask is a task that failed on a host during a run
+type FailedTask struct {
+	Host    string `json:"host"`
+	Task    string `json:"task"`
+	Message string `json:"message,omitempty"`
+}
+
+// Run is an execution recorded in the runs directory
+type Run struct {
+	// ID of the run, in the form <operation>/<timestamp>
+	ID string `json:"id"`
+	// Directory that contains the files of the run
+	Directory string      `json:"directory"`
+	Metadata  RunMetadata `json:"metadata"`
+}
+
+// PlanFile returns the path of the plan file that was used for the run
+func (r Run) PlanFile() string {
+	return filepath.Join(r.Directory, runPlanFilename)
+}
+
+// runRecorder keeps the metadata file in the run directory up to date
+// with the events coming out of the ansible event stream
+type runRecorder struct {
+	sync.Mutex
+	file        string
+	meta        RunMetadata
+	currentTask string
+	// dryRun is true when the playbook is not run
+	dryRun bool
+}
+
+// record consumes the events, and forwards them on the returned channel
+func (r *runRecorder) record(in <-chan ansible.Event) <-chan ansible.Event {
+	out := make(chan ansible.Event)
+	go func() {
+		for e := range in {
+			r.Lock()
+			if r.update(e) {
+				r.write()
+			}
+			r.Unlock()
+			out <- e
+		}
+		close(out)
+	}()
+	return out
+}
+
+// update the metadata with the event. Returns true if the metadata changed.
+func (r *runRecorder) update(e ansible.Event) bool {
+	switch event := e.(type) {
+	case *ansible.TaskStartEvent:
+		r.currentTask = event.Name
+	case *ansible.HandlerTaskStartEvent:
+		r.currentTask = event.Name
+	case *ansible.RunnerFailedEvent:
+		if event.IgnoreErrors {
+			return false
+		}
+		r.addFailure(event.Host, event.Result.Message)
+		return true
+	case *ansible.RunnerItemFailedEvent:
+		if event.IgnoreErrors {
+			return false
+		}
+		r.addFailure(event.Host, event.Result.Message)
+		return true
+	case *ansible.RunnerUnreachableEvent:
+		r.addFailure(event.Host, "host is unreachable")
+		return true
+	}
+	return false
+}
+
+func (r *runRecorder) addFailure(host, msg string) {
+	if !contains(host, r.meta.FailedHosts) {
+		r.meta.FailedHosts = append(r.meta.FailedHosts, host)
+	}
+	r.meta.FailedTasks = append(r.meta.FailedTasks, FailedTask{Host: host, Task: r.currentTask, Message: msg})
+}
+
+// finish records the outcome of the run, once ansible has exited
+func (r *runRecorder) finish(runErr error) error {
+	r.Lock()
+	defer r.Unlock()
+	end := time.Now()
+	r.meta.End = &end
+	r.meta.Result = RunResultSuccess
+	if r.dryRun {
+		r.meta.Result = RunResultDryRun
+	}
+	if runErr != nil {
+		r.meta.Result = RunResultFailure
+		r.meta.Error = runErr.Error()
+	}
+	return r.write()
+}
+
+func (r *runRecorder) write() error {
+	b, err := json.MarshalIndent(r.meta, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling run metadata: %v", err)
+	}
+	if err := ioutil.WriteFile(r.file, b, 0644); err != nil {
+		return fmt.Errorf("error writing run metadata file %q: %v", r.file, err)
+	}
+	return nil
+}
+
+// ListRuns returns the runs found in the runs directory, ordered by their start time
+func ListRuns(runsDir string) ([]Run, error) {
+	operations, err := ioutil.ReadDir(runsDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Run{}, nil
+		}
+		return nil, fmt.Errorf("error listing runs in %q: %v", runsDir, err)
+	}
+	runs := []Run{}
+	for _, op := range operations {
+		if !op.IsDir() {
+			continue
+		}
+		dirs, err := ioutil.ReadDir(filepath.Join(runsDir, op.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("error listing runs in %q: %v", filepath.Join(runsDir, op.Name()), err)
+		}
+		for _, d := range dirs {
+			if !d.IsDir() {
+				continue
+			}
+			run, err := readRun(runsDir, op.Name(), d.Name())
+			if err != nil {
+				return nil, err
+			}
+			if run != nil {
+				runs = append(runs, *run)
+			}
+		}
+	}
+	sort.SliceStable(runs, func(i, j int) bool {
+		if runs[i].Metadata.Start.Equal(runs[j].Metadata.Start) {
+			return runs[i].ID < runs[j].ID
+		}
+		return runs[i].Metadata.Start.Before(runs[j].Metadata.Start)
+	})
+	return runs, nil
+}
+
+// GetRun returns the run with the given ID. The ID can also be the
+// timestamp of the run, as long as a single run started at that time.
+func GetRun(runsDir, id string) (*Run, error) {
+	runs, err := ListRuns(runsDir)
+	if err != nil {
+		return nil, err
+	}
+	matches := []Run{}
+	for _, r := range runs {
+		if r.ID == id || filepath.Base(r.Directory) == id {
+			matches = append(matches, r)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("run %q was not found in %q", id, runsDir)
+	case 1:
+		return &matches[0], nil
+	default:
+		ids := []string{}
+		for _, m := range matches {
+			ids = append(ids, m.ID)
+		}
+		return nil, fmt.Errorf("run %q is ambiguous, it matches %s", id, strings.Join(ids, ", "))
+	}
+}
+
+// PreviousRun returns the most recent run that started before the given one,
+// and that recorded a plan file. Returns nil if there is no such run.
+func PreviousRun(runsDir string, run Run) (*Run, error) {
+	runs, err := ListRuns(runsDir)
+	if err != nil {
+		return nil, err
+	}
+	var prev *Run
+	for i, r := range runs {
+		if r.ID == run.ID {
+			break
+		}
+		if _, err := os.Stat(r.PlanFile()); err == nil {
+			prev = &runs[i]
+		}
+	}
+	return prev, nil
+}
+
+// readRun reads the run stored in runsDir/operation/name. Runs that were
+// recorded before the metadata file existed get their details from the
+// directory names. Returns nil if the directory is not a run.
+func readRun(runsDir, operation, name string) (*Run, error) {
+	dir := filepath.Join(runsDir, operation, name)
+	run := &Run{
+		ID:        operation + "/" + name,
+		Directory: dir,
+	}
+	b, err := ioutil.ReadFile(filepath.Join(dir, runMetadataFilename))
+	if err == nil {
+		if err := json.Unmarshal(b, &run.Metadata); err != nil {
+			return nil, fmt.Errorf("error reading metadata of run %q: %v", run.ID, err)
+		}
+		return run, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("error reading metadata of run %q: %v", run.ID, err)
+	}
+	start, err := parseRunDirectoryName(name)
+	if err != nil {
+		return nil, nil
+	}
+	run.Metadata = RunMetadata{
+		Operation: operation,
+		Start:     start,
+		Result:    RunResultUnknown,
+	}
+	return run, nil
+}
+
+// parseRunDirectoryName returns the start time of the run stored in the
+// directory. The name of the directory is the start time of the run, followed
+// by a counter when another run started at the same time.
+func parseRunDirectoryName(name string) (time.Time, error) {
+	start, err := time.ParseInLocation(runTimestampFormat, name, time.Local)
+	if err == nil {
+		return start, nil
+	}
+	i := strings.LastIndex(name, "-")
+	if i < 0 {
+		return start, err
+	}
+	if _, cerr := strconv.Atoi(name[i+1:]); cerr != nil {
+		return start, err
+	}
+	return time.ParseInLocation(runTimestampFormat, name[:i], time.Local)
+}
@@ -0,0 +1,168 @@
+package install
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/apprenda/kismatic/pkg/ansible"
+)
+
+func taskStart(name string) *ansible.TaskStartEvent {
+	e := &ansible.TaskStartEvent{}
+	e.Name = name
+	return e
+}
+
+func TestRunRecorderFailures(t *testing.T) {
+	dir := mustGetTempDir(t)
+	r := &runRecorder{
+		file: filepath.Join(dir, runMetadataFilename),
+		meta: RunMetadata{Operation: "apply", Start: time.Now(), Result: RunResultRunning},
+	}
+	unreachable := &ansible.RunnerUnreachableEvent{}
+	unreachable.Host = "node3"
+	events := []ansible.Event{
+		taskStart("install packages"),
+		runnerOK("node1"),
+		runnerFailed("node2", true),
+		taskStart("start kubelet"),
+		runnerFailed("node2", false),
+		unreachable,
+	}
+	for _, e := range events {
+		r.update(e)
+	}
+	if err := r.finish(errors.New("ansible failed")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.meta.Result != RunResultFailure {
+		t.Errorf("expected result %q, got %q", RunResultFailure, r.meta.Result)
+	}
+	if !reflect.DeepEqual(r.meta.FailedHosts, []string{"node2", "node3"}) {
+		t.Errorf("unexpected failed hosts: %v", r.meta.FailedHosts)
+	}
+	if len(r.meta.FailedTasks) != 2 || r.meta.FailedTasks[0].Task != "start kubelet" {
+		t.Errorf("unexpected failed tasks: %v", r.meta.FailedTasks)
+	}
+
+}
+
+func TestListRuns(t *testing.T) {
+	runsDir := mustGetTempDir(t)
+	// a run recorded before the metadata file existed
+	legacy := filepath.Join(runsDir, "apply", "2017-09-01-10-00-00")
+	if err := os.MkdirAll(legacy, 0755); err != nil {
+		t.Fatalf("error creating run directory: %v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(legacy, runPlanFilename), []byte("cluster:\n  name: old\n"), 0644); err != nil {
+		t.Fatalf("error writing plan: %v", err)
+	}
+	// a directory that is not a run
+	if err := os.MkdirAll(filepath.Join(runsDir, "apply", "not-a-run"), 0755); err != nil {
+		t.Fatalf("error creating directory: %v", err)
+	}
+	ae := &ansibleExecutor{options: ExecutorOptions{RunsDirectory: runsDir}}
+	dir, start, err := ae.createRunDirectory("add-worker")
+	if err != nil {
+		t.Fatalf("error creating run directory: %v", err)
+	}
+	r := &runRecorder{
+		file: filepath.Join(dir, runMetadataFilename),
+		meta: RunMetadata{Operation: "add-worker", Start: start, Result: RunResultRunning},
+	}
+	if err := r.finish(nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	runs, err := ListRuns(runsDir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(runs) != 2 {
+		t.Fatalf("expected 2 runs, got %d", len(runs))
+	}
+	if runs[0].ID != "apply/2017-09-01-10-00-00" || runs[0].Metadata.Result != RunResultUnknown {
+		t.Errorf("unexpected first run: %+v", runs[0])
+	}
+	if runs[1].Metadata.Operation != "add-worker" || runs[1].Metadata.Result != RunResultSuccess {
+		t.Errorf("unexpected second run: %+v", runs[1])
+	}
+
+	run, err := GetRun(runsDir, filepath.Base(dir))
+	if err != nil {
+		t.Fatalf("unexpected error getting run: %v", err)
+	}
+	prev, err := PreviousRun(runsDir, *run)
+	if err != nil {
+		t.Fatalf("unexpected error getting previous run: %v", err)
+	}
+	if prev == nil || prev.ID != runs[0].ID {
+		t.Errorf("expected previous run to be %q, got %v", runs[0].ID, prev)
+	}
+	if _, err := GetRun(runsDir, "apply/does-not-exist"); err == nil {
+		t.Errorf("expected an error getting a run that does not exist")
+	}
+}
+
+func TestListRunsNoRunsDirectory(t *testing.T) {
+	runs, err := ListRuns(filepath.Join(mustGetTempDir(t), "runs"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(runs) != 0 {
+		t.Errorf("expected no runs, got %d", len(runs))
+	}
+}
+
+func TestCreateRunDirectoryIsUnique(t *testing.T) {
+	runsDir := mustGetTempDir(t)
+	ae := &ansibleExecutor{options: ExecutorOptions{RunsDirectory: runsDir}}
+	dirs := map[string]bool{}
+	for i := 0; i < 5; i++ {
+		dir, start, err := ae.createRunDirectory("upgrade-nodes")
+		if err != nil {
+			t.Fatalf("error creating run directory: %v", err)
+		}
+		if dirs[dir] {
+			t.Fatalf("run directory %q was created twice", dir)
+		}
+		dirs[dir] = true
+		parsed, err := parseRunDirectoryName(filepath.Base(dir))
+		if err != nil {
+			t.Fatalf("error parsing run directory name %q: %v", filepath.Base(dir), err)
+		}
+		if !parsed.Equal(start.Truncate(time.Second)) {
+			t.Errorf("expected start time %v, got %v", start.Truncate(time.Second), parsed)
+		}
+	}
+}
+
+func TestParseRunDirectoryName(t *testing.T) {
+	expected := time.Date(2017, 9, 1, 10, 0, 0, 0, time.Local)
+	tests := []struct {
+		name  string
+		start time.Time
+		valid bool
+	}{
+		{name: "2017-09-01-10-00-00", start: expected, valid: true},
+		{name: "2017-09-01-10-00-00-2", start: expected, valid: true},
+		{name: "2017-09-01-10-00-00-12", start: expected, valid: true},
+		{name: "not-a-run"},
+		{name: "2017-09-01-10-00-00-abc"},
+	}
+	for _, test := range tests {
+		start, err := parseRunDirectoryName(test.name)
+		if test.valid != (err == nil) {
+			t.Errorf("%s: expected valid to be %v, but got error %v", test.name, test.valid, err)
+			continue
+		}
+		if test.valid && !start.Equal(test.start) {
+			t.Errorf("%s: expected %v, got %v", test.name, test.start, start)
+		}
+	}
+}
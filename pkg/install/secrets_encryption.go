@@ -0,0 +1,245 @@
+package install
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/apprenda/kismatic/pkg/util"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	encryptionProviderAESCBC       = "aescbc"
+	encryptionProviderSecretbox    = "secretbox"
+	encryptionConfigFilename       = "encryption-config.yaml"
+	encryptionProviderConfigOption = "experimental-encryption-provider-config"
+	// both aescbc and secretbox use 32 byte keys
+	encryptionKeySize = 32
+)
+
+func encryptionProviders() []string {
+	return []string{encryptionProviderAESCBC, encryptionProviderSecretbox}
+}
+
+func (s *SecretsEncryption) validate() (bool, []error) {
+	v := newValidator()
+	if s.Provider != "" && !util.Contains(s.Provider, encryptionProviders()) {
+		v.addError(fmt.Errorf("%q is not a valid secrets encryption provider. Options are %v", s.Provider, encryptionProviders()))
+	}
+	return v.valid()
+}
+
+// encryptionConfig is the EncryptionConfig file read by the API server
+type encryptionConfig struct {
+	Kind       string                     `yaml:"kind"`
+	APIVersion string                     `yaml:"apiVersion"`
+	Resources  []encryptionResourceConfig `yaml:"resources"`
+}
+
+type encryptionResourceConfig struct {
+	Resources []string             `yaml:"resources"`
+	Providers []encryptionProvider `yaml:"providers"`
+}
+
+type encryptionProvider struct {
+	AESCBC    *encryptionKeys `yaml:"aescbc,omitempty"`
+	Secretbox *encryptionKeys `yaml:"secretbox,omitempty"`
+	Identity  *struct{}       `yaml:"identity,omitempty"`
+}
+
+type encryptionKeys struct {
+	Keys []encryptionKey `yaml:"keys"`
+}
+
+type encryptionKey struct {
+	Name   string `yaml:"name"`
+	Secret string `yaml:"secret"`
+	// provider of the key, which is not part of the file format
+	provider string
+}
+
+// EncryptionConfigFile returns the path to the encryption config file in the
+// generated assets directory
+func EncryptionConfigFile(generatedAssetsDir string) string {
+	return filepath.Join(generatedAssetsDir, encryptionConfigFilename)
+}
+
+// validateEncryptionNotDisabled returns an error when secrets encryption is not
+// enabled in the plan, but the encryption config file exists. The secrets that
+// were encrypted with the keys of the file cannot be read by the API server
+// once the file is not used anymore.
+func validateEncryptionNotDisabled(p Plan, generatedAssetsDir string) error {
+	if p.Cluster.SecretsEncryption != nil && p.Cluster.SecretsEncryption.Enabled {
+		return nil
+	}
+	file := EncryptionConfigFile(generatedAssetsDir)
+	if _, err := os.Stat(file); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("error reading encryption config file: %v", err)
+	}
+	return fmt.Errorf("Secrets encryption cannot be disabled, as the secrets of the cluster are encrypted with the keys in %q. Set cluster.secrets_encryption.enabled to true", file)
+}
+
+// GenerateSecretsEncryptionConfig writes the encryption config file with a new
+// key to the generated assets directory when secrets encryption is enabled.
+// The existing keys are kept when the file already exists.
+func GenerateSecretsEncryptionConfig(p *Plan, generatedAssetsDir string) error {
+	s := p.Cluster.SecretsEncryption
+	if s == nil || !s.Enabled {
+		return nil
+	}
+	file := EncryptionConfigFile(generatedAssetsDir)
+	keys, err := readEncryptionKeys(file)
+	if err != nil {
+		return err
+	}
+	if len(keys) > 0 {
+		return nil
+	}
+	key, err := newEncryptionKey(s.Provider, keys)
+	if err != nil {
+		return err
+	}
+	return writeEncryptionConfig(file, []encryptionKey{key})
+}
+
+// encryptionConfigFile returns the absolute path to the encryption config file
+// that should be copied to the master nodes. An error is returned when the file
+// does not exist, unless mustExist is false.
+func encryptionConfigFile(generatedAssetsDir string, mustExist bool) (string, error) {
+	file, err := filepath.Abs(EncryptionConfigFile(generatedAssetsDir))
+	if err != nil {
+		return "", fmt.Errorf("failed to determine absolute path to %s: %v", encryptionConfigFilename, err)
+	}
+	if !mustExist {
+		return file, nil
+	}
+	if _, err := os.Stat(file); err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("Secrets encryption is enabled, but the encryption config file was not found at %q. Run \"kismatic install apply\" to generate it", file)
+		}
+		return "", fmt.Errorf("error reading encryption config file: %v", err)
+	}
+	return file, nil
+}
+
+// readEncryptionKeys returns the keys of the encryption config file, in the
+// order in which they are tried by the API server. The first key is used to
+// encrypt the secrets. No keys are returned when the file does not exist.
+func readEncryptionKeys(file string) ([]encryptionKey, error) {
+	b, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading encryption config file: %v", err)
+	}
+	var config encryptionConfig
+	if err := yaml.Unmarshal(b, &config); err != nil {
+		return nil, fmt.Errorf("error parsing encryption config file %q: %v", file, err)
+	}
+	keys := []encryptionKey{}
+	for _, r := range config.Resources {
+		if !util.Contains("secrets", r.Resources) {
+			continue
+		}
+		for _, p := range r.Providers {
+			switch {
+			case p.AESCBC != nil:
+				for _, k := range p.AESCBC.Keys {
+					k.provider = encryptionProviderAESCBC
+					keys = append(keys, k)
+				}
+			case p.Secretbox != nil:
+				for _, k := range p.Secretbox.Keys {
+					k.provider = encryptionProviderSecretbox
+					keys = append(keys, k)
+				}
+			}
+		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("encryption config file %q does not contain any key for secrets", file)
+	}
+	return keys, nil
+}
+
+// writeEncryptionConfig writes an encryption config file for secrets with the
+// keys, in order. The identity provider is added last, so that the secrets that
+// are not encrypted yet can be read.
+func writeEncryptionConfig(file string, keys []encryptionKey) error {
+	if len(keys) == 0 {
+		return errors.New("at least one encryption key is required")
+	}
+	providers := []encryptionProvider{}
+	for i, k := range keys {
+		// keys of the same provider are grouped when they are next to each other
+		if i == 0 || keys[i-1].provider != k.provider {
+			providers = append(providers, encryptionProvider{})
+		}
+		p := &providers[len(providers)-1]
+		switch k.provider {
+		case encryptionProviderAESCBC:
+			if p.AESCBC == nil {
+				p.AESCBC = &encryptionKeys{}
+			}
+			p.AESCBC.Keys = append(p.AESCBC.Keys, k)
+		case encryptionProviderSecretbox:
+			if p.Secretbox == nil {
+				p.Secretbox = &encryptionKeys{}
+			}
+			p.Secretbox.Keys = append(p.Secretbox.Keys, k)
+		default:
+			return fmt.Errorf("encryption key %q has an invalid provider %q", k.Name, k.provider)
+		}
+	}
+	providers = append(providers, encryptionProvider{Identity: &struct{}{}})
+	config := encryptionConfig{
+		Kind:       "EncryptionConfig",
+		APIVersion: "v1",
+		Resources: []encryptionResourceConfig{
+			{
+				Resources: []string{"secrets"},
+				Providers: providers,
+			},
+		},
+	}
+	b, err := yaml.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("error marshalling encryption config: %v", err)
+	}
+	// the file contains the encryption keys, it must only be readable by the owner
+	if err := ioutil.WriteFile(file, b, 0600); err != nil {
+		return fmt.Errorf("error writing encryption config file: %v", err)
+	}
+	return nil
+}
+
+// newEncryptionKey returns a new random key for the provider, named after the
+// existing keys, e.g. key2 when key1 exists
+func newEncryptionKey(provider string, existing []encryptionKey) (encryptionKey, error) {
+	last := 0
+	for _, k := range existing {
+		if n, err := strconv.Atoi(strings.TrimPrefix(k.Name, "key")); err == nil && n > last {
+			last = n
+		}
+	}
+	secret := make([]byte, encryptionKeySize)
+	if _, err := rand.Read(secret); err != nil {
+		return encryptionKey{}, fmt.Errorf("error generating encryption key: %v", err)
+	}
+	return encryptionKey{
+		Name:     fmt.Sprintf("key%d", last+1),
+		Secret:   base64.StdEncoding.EncodeToString(secret),
+		provider: provider,
+	}, nil
+}
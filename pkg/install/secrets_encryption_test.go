@@ -0,0 +1,180 @@
+package install
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGenerateSecretsEncryptionConfig(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "test-encryption-config")
+	if err != nil {
+		t.Fatalf("error creating tmp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// Nothing is generated when encryption is not enabled
+	p := &Plan{}
+	p.Cluster.SecretsEncryption = &SecretsEncryption{Provider: encryptionProviderSecretbox}
+	if err := GenerateSecretsEncryptionConfig(p, tmpDir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := encryptionConfigFile(tmpDir, true); err == nil {
+		t.Error("expected an error when the encryption config file does not exist")
+	}
+
+	p.Cluster.SecretsEncryption.Enabled = true
+	if err := GenerateSecretsEncryptionConfig(p, tmpDir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	file, err := encryptionConfigFile(tmpDir, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if file != filepath.Join(tmpDir, encryptionConfigFilename) {
+		t.Errorf("expected encryption config in %s, but got %s", tmpDir, file)
+	}
+	keys, err := readEncryptionKeys(file)
+	if err != nil {
+		t.Fatalf("error reading encryption keys: %v", err)
+	}
+	if len(keys) != 1 || keys[0].Name != "key1" || keys[0].provider != encryptionProviderSecretbox {
+		t.Fatalf("expected a single secretbox key named key1, but got %v", keys)
+	}
+	secret, err := base64.StdEncoding.DecodeString(keys[0].Secret)
+	if err != nil || len(secret) != encryptionKeySize {
+		t.Errorf("expected a base64 encoded %d byte secret, but got %q", encryptionKeySize, keys[0].Secret)
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		t.Fatalf("error reading encryption config file: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("expected the encryption config file mode to be 0600, but got %v", info.Mode().Perm())
+	}
+
+	// The existing keys are kept
+	p.Cluster.SecretsEncryption.Provider = encryptionProviderAESCBC
+	if err := GenerateSecretsEncryptionConfig(p, tmpDir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	again, err := readEncryptionKeys(file)
+	if err != nil {
+		t.Fatalf("error reading encryption keys: %v", err)
+	}
+	if !reflect.DeepEqual(keys, again) {
+		t.Errorf("expected the keys to be kept, but got %v", again)
+	}
+}
+
+func TestWriteEncryptionConfig(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "test-write-encryption-config")
+	if err != nil {
+		t.Fatalf("error creating tmp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	file := filepath.Join(tmpDir, encryptionConfigFilename)
+	keys := []encryptionKey{
+		{Name: "key3", Secret: "c2VjcmV0Mw==", provider: encryptionProviderAESCBC},
+		{Name: "key2", Secret: "c2VjcmV0Mg==", provider: encryptionProviderSecretbox},
+		{Name: "key1", Secret: "c2VjcmV0MQ==", provider: encryptionProviderAESCBC},
+	}
+	if err := writeEncryptionConfig(file, keys); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatalf("error reading encryption config file: %v", err)
+	}
+	expected := `kind: EncryptionConfig
+apiVersion: v1
+resources:
+- resources:
+  - secrets
+  providers:
+  - aescbc:
+      keys:
+      - name: key3
+        secret: c2VjcmV0Mw==
+  - secretbox:
+      keys:
+      - name: key2
+        secret: c2VjcmV0Mg==
+  - aescbc:
+      keys:
+      - name: key1
+        secret: c2VjcmV0MQ==
+  - identity: {}
+`
+	if string(b) != expected {
+		t.Errorf("expected encryption config:\n%s\nbut got:\n%s", expected, b)
+	}
+	read, err := readEncryptionKeys(file)
+	if err != nil {
+		t.Fatalf("error reading encryption keys: %v", err)
+	}
+	if !reflect.DeepEqual(read, keys) {
+		t.Errorf("expected keys %v, but got %v", keys, read)
+	}
+
+	key, err := newEncryptionKey(encryptionProviderAESCBC, keys)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if key.Name != "key4" {
+		t.Errorf("expected the new key to be named key4, but got %s", key.Name)
+	}
+}
+
+func TestValidateSecretsEncryption(t *testing.T) {
+	tests := []struct {
+		provider string
+		valid    bool
+	}{
+		{provider: "", valid: true},
+		{provider: "aescbc", valid: true},
+		{provider: "secretbox", valid: true},
+		{provider: "aesgcm"},
+	}
+	for _, test := range tests {
+		s := SecretsEncryption{Enabled: true, Provider: test.provider}
+		if ok, errs := s.validate(); ok != test.valid {
+			t.Errorf("provider %q: expected valid to be %t, but got %t: %v", test.provider, test.valid, ok, errs)
+		}
+	}
+}
+
+func TestValidateEncryptionNotDisabled(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrets-encryption")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	p := Plan{}
+	// Encryption can be left disabled when it was never enabled
+	if ok, errs := ValidateSecretsEncryption(&p, dir); !ok {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+
+	p.Cluster.SecretsEncryption = &SecretsEncryption{Enabled: true, Provider: encryptionProviderAESCBC}
+	if err := GenerateSecretsEncryptionConfig(&p, dir); err != nil {
+		t.Fatalf("error creating encryption config file: %v", err)
+	}
+	if ok, errs := ValidateSecretsEncryption(&p, dir); !ok {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+
+	// Secrets encrypted with the keys of the config file cannot be read once encryption is disabled
+	p.Cluster.SecretsEncryption.Enabled = false
+	if ok, _ := ValidateSecretsEncryption(&p, dir); ok {
+		t.Errorf("expected an error when encryption is disabled")
+	}
+	p.Cluster.SecretsEncryption = nil
+	if ok, _ := ValidateSecretsEncryption(&p, dir); ok {
+		t.Errorf("expected an error when the secrets encryption section is removed")
+	}
+}
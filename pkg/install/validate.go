@@ -1,9 +1,11 @@
 package install
 
 import (
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"net"
+	"net/url"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -48,10 +50,10 @@ func ValidateNodes(nodes []Node) (bool, []error) {
 }
 
 // ValidatePlanSSHConnections tries to establish SSH connections to all nodes in the cluster
-func ValidatePlanSSHConnections(p *Plan) (bool, []error) {
+func ValidatePlanSSHConnections(p *Plan, generatedAssetsDir string) (bool, []error) {
 	v := newValidator()
 
-	s := sshConnectionSet{p.Cluster.SSH, p.GetUniqueNodes()}
+	s := sshConnectionSet{p.Cluster.SSH, p.GetUniqueNodes(), generatedAssetsDir}
 
 	v.validateWithErrPrefix("Node Connnection", s)
 
@@ -61,12 +63,14 @@ func ValidatePlanSSHConnections(p *Plan) (bool, []error) {
 type sshConnectionSet struct {
 	SSHConfig SSHConfig
 	Nodes     []Node
+	// GeneratedAssetsDir contains the known hosts file of the nodes
+	GeneratedAssetsDir string
 }
 
 // ValidateSSHConnection tries to establish SSH connection with the details provieded for a single node
-func ValidateSSHConnection(con *SSHConnection, prefix string) (bool, []error) {
+func ValidateSSHConnection(con *SSHConnection, generatedAssetsDir string, prefix string) (bool, []error) {
 	v := newValidator()
-	s := sshConnectionSet{*con.SSHConfig, []Node{*con.Node}}
+	s := sshConnectionSet{*con.SSHConfig, []Node{*con.Node}, generatedAssetsDir}
 	v.validateWithErrPrefix(prefix, s)
 	return v.valid()
 }
@@ -86,6 +90,16 @@ func ValidateCertificates(p *Plan, pki *LocalPKI) (bool, []error) {
 	return v.valid()
 }
 
+// ValidateSecretsEncryption checks that secrets encryption is not disabled
+// after secrets were encrypted with the keys in the generated assets directory
+func ValidateSecretsEncryption(p *Plan, generatedAssetsDir string) (bool, []error) {
+	v := newValidator()
+	if err := validateEncryptionNotDisabled(*p, generatedAssetsDir); err != nil {
+		v.addError(err)
+	}
+	return v.valid()
+}
+
 // ValidateStorageVolume validates the storage volume attributes
 func ValidateStorageVolume(sv StorageVolume) (bool, []error) {
 	return sv.validate()
@@ -138,6 +152,14 @@ func (p *Plan) validate() (bool, []error) {
 	v := newValidator()
 
 	v.validate(&p.Cluster)
+	if len(p.Cluster.FeatureGates) > 0 {
+		for _, n := range p.getAllNodes() {
+			if value, ok := n.KubeletOptions.Overrides[featureGatesOption]; ok {
+				o := featureGatesOverride{component: fmt.Sprintf("Kubelet of node %q", n.Host), value: value}
+				v.addError(validateFeatureGatesOverride(p.Cluster.FeatureGates, o)...)
+			}
+		}
+	}
 	v.validate(&p.DockerRegistry)
 	if p.Cluster.DisconnectedInstallation && !p.PrivateRegistryProvided() {
 		v.addError(fmt.Errorf("A container image registry is required when disconnected_installation is true"))
@@ -173,6 +195,31 @@ func (c *Cluster) validate() (bool, []error) {
 	v.validate(&c.KubeSchedulerOptions)
 	v.validate(&c.KubeletOptions)
 	v.validate(&c.CloudProvider)
+	if c.SecretsEncryption != nil {
+		v.validate(c.SecretsEncryption)
+		if _, ok := c.APIServerOptions.Overrides[encryptionProviderConfigOption]; ok && c.SecretsEncryption.Enabled {
+			v.addError(fmt.Errorf("Kube ApiServer Option %q cannot be overridden when secrets encryption is enabled", encryptionProviderConfigOption))
+		}
+	}
+	if c.Authentication != nil {
+		v.validate(c.Authentication)
+		if c.Authentication.OIDC != nil {
+			if oidc := optionOverridesWithPrefix(c.APIServerOptions.Overrides, "oidc-"); len(oidc) > 0 {
+				v.addError(fmt.Errorf("Kube ApiServer Option(s) [%v] cannot be overridden when OIDC authentication is configured", strings.Join(oidc, ", ")))
+			}
+		}
+	}
+	if c.APIServerOptions.Audit != nil {
+		if audit := optionOverridesWithPrefix(c.APIServerOptions.Overrides, "audit-"); len(audit) > 0 {
+			v.addError(fmt.Errorf("Kube ApiServer Option(s) [%v] cannot be overridden when audit is configured", strings.Join(audit, ", ")))
+		}
+	}
+	if len(c.FeatureGates) > 0 {
+		v.addError(validateFeatureGates(c.FeatureGates, kubernetesMinorVersion)...)
+		for _, o := range featureGatesOverrides(*c) {
+			v.addError(validateFeatureGatesOverride(c.FeatureGates, o)...)
+		}
+	}
 
 	return v.valid()
 }
@@ -203,6 +250,58 @@ func (c *CertsConfig) validate() (bool, []error) {
 	if _, err := time.ParseDuration(c.CAExpiry); c.CAExpiry != "" && err != nil { // don't error when empty for backwards compat
 		v.addError(fmt.Errorf("Invalid CA certificate expiry %q provider: %v", c.CAExpiry, err))
 	}
+	if c.usesIntermediateCA() || c.IntermediateCAKey != "" || c.IntermediateCAChain != "" {
+		files := []struct{ field, path string }{
+			{"intermediate_ca_cert", c.IntermediateCACert},
+			{"intermediate_ca_key", c.IntermediateCAKey},
+			{"intermediate_ca_chain", c.IntermediateCAChain},
+		}
+		for _, f := range files {
+			if f.path == "" {
+				v.addError(fmt.Errorf("The %s field is required when using an intermediate CA", f.field))
+				continue
+			}
+			if !filepath.IsAbs(f.path) {
+				v.addError(fmt.Errorf("The %s path %q must be an absolute path", f.field, f.path))
+				continue
+			}
+			if _, err := os.Stat(f.path); err != nil {
+				v.addError(fmt.Errorf("The %s file %q could not be read: %v", f.field, f.path, err))
+			}
+		}
+	}
+	switch c.KeyAlgorithm {
+	case "", keyAlgorithmRSA:
+		if c.KeySize != 0 && c.KeySize != 2048 && c.KeySize != 4096 {
+			v.addError(fmt.Errorf("Invalid RSA key size %d provided, options are 2048 or 4096", c.KeySize))
+		}
+	case keyAlgorithmECDSA:
+		if c.KeySize != 0 && c.KeySize != 256 && c.KeySize != 384 {
+			v.addError(fmt.Errorf("Invalid ECDSA key size %d provided, options are 256 or 384", c.KeySize))
+		}
+	default:
+		v.addError(fmt.Errorf("Invalid key algorithm %q provided, options are %q or %q", c.KeyAlgorithm, keyAlgorithmRSA, keyAlgorithmECDSA))
+	}
+	if c.RemoteSigner != nil {
+		if c.usesIntermediateCA() {
+			v.addError(errors.New("An intermediate CA cannot be used with a remote signer"))
+		}
+		v.validate(c.RemoteSigner)
+	}
+	return v.valid()
+}
+
+func (rs *RemoteSigner) validate() (bool, []error) {
+	v := newValidator()
+	u, err := url.Parse(rs.URL)
+	if rs.URL == "" {
+		v.addError(errors.New("The remote signer URL is required"))
+	} else if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		v.addError(fmt.Errorf("The remote signer URL %q is not a valid http or https URL", rs.URL))
+	}
+	if _, err := hex.DecodeString(rs.AuthKey); err != nil {
+		v.addError(fmt.Errorf("The remote signer auth key must be hex encoded: %v", err))
+	}
 	return v.valid()
 }
 
@@ -211,18 +310,51 @@ func (s *SSHConfig) validate() (bool, []error) {
 	if s.User == "" {
 		v.addError(errors.New("SSH user field is required"))
 	}
-	if s.Key == "" {
-		v.addError(errors.New("SSH key field is required"))
+	if s.Key == "" && !s.UseAgent {
+		v.addError(errors.New("SSH key field is required when not using the SSH agent"))
 	}
-	if _, err := os.Stat(s.Key); os.IsNotExist(err) {
-		v.addError(fmt.Errorf("SSH Key file was not found at %q", s.Key))
-	}
-	if !filepath.IsAbs(s.Key) {
-		v.addError(errors.New("SSH Key field must be an absolute path"))
+	if s.Key != "" {
+		if _, err := os.Stat(s.Key); os.IsNotExist(err) {
+			v.addError(fmt.Errorf("SSH Key file was not found at %q", s.Key))
+		}
+		if !filepath.IsAbs(s.Key) {
+			v.addError(errors.New("SSH Key field must be an absolute path"))
+		}
 	}
 	if s.Port < 1 || s.Port > 65535 {
 		v.addError(fmt.Errorf("SSH port %d is invalid. Port must be in the range 1-65535", s.Port))
 	}
+	if s.Client != "" && !util.Contains(s.Client, sshClients()) {
+		v.addError(fmt.Errorf("%q is not a valid SSH client. Options are %v", s.Client, sshClients()))
+	}
+	if _, err := time.ParseDuration(s.ConnectTimeout); s.ConnectTimeout != "" && err != nil {
+		v.addError(fmt.Errorf("SSH connect timeout %q is invalid: %v", s.ConnectTimeout, err))
+	}
+	if _, err := time.ParseDuration(s.CommandTimeout); s.CommandTimeout != "" && err != nil {
+		v.addError(fmt.Errorf("SSH command timeout %q is invalid: %v", s.CommandTimeout, err))
+	}
+	if s.Bastion != nil {
+		v.validate(s.Bastion)
+	}
+	return v.valid()
+}
+
+func (b *Bastion) validate() (bool, []error) {
+	v := newValidator()
+	if b.Host == "" {
+		v.addError(errors.New("Bastion host field is required"))
+	}
+	if b.Key != "" {
+		if _, err := os.Stat(b.Key); os.IsNotExist(err) {
+			v.addError(fmt.Errorf("Bastion SSH Key file was not found at %q", b.Key))
+		}
+		if !filepath.IsAbs(b.Key) {
+			v.addError(errors.New("Bastion SSH Key field must be an absolute path"))
+		}
+	}
+	if b.Port < 0 || b.Port > 65535 {
+		v.addError(fmt.Errorf("Bastion SSH port %d is invalid. Port must be in the range 1-65535", b.Port))
+	}
 	return v.valid()
 }
 
@@ -271,7 +403,7 @@ func (h *HeapsterMonitoring) validate() (bool, []error) {
 	v := newValidator()
 	if h != nil && !h.Disable {
 		if h.Options.Heapster.Replicas <= 0 {
-			v.addError(fmt.Errorf("Heapster replicas %d is not valid, must be greater than 0", h.Options.HeapsterReplicas))
+			v.addError(fmt.Errorf("Heapster replicas %d is not valid, must be greater than 0", h.Options.Heapster.Replicas))
 		}
 		if !util.Contains(h.Options.Heapster.ServiceType, serviceTypes()) {
 			v.addError(fmt.Errorf("Heapster Service Type %q is not a valid option %v", h.Options.Heapster.ServiceType, serviceTypes()))
@@ -294,25 +426,45 @@ func (p *PackageManager) validate() (bool, []error) {
 func (s sshConnectionSet) validate() (bool, []error) {
 	v := newValidator()
 
-	err := ssh.ValidUnencryptedPrivateKey(s.SSHConfig.Key)
-	if err != nil {
-		v.addError(fmt.Errorf("SSH key validation error: %v", err))
-	} else {
+	// validate each key once, as most nodes share the key of the cluster
+	keysValid := true
+	seenKeys := map[string]bool{}
+	for _, node := range s.Nodes {
+		key := s.SSHConfig.forNode(node).Key
+		if key == "" || seenKeys[key] {
+			continue
+		}
+		seenKeys[key] = true
+		if err := ssh.ValidPrivateKey(key); err != nil {
+			v.addError(fmt.Errorf("SSH key validation error: %v", err))
+			keysValid = false
+		}
+	}
+	if err := s.validBastionKey(); err != nil {
+		v.addError(fmt.Errorf("Bastion SSH key validation error: %v", err))
+		keysValid = false
+	}
+	if s.SSHConfig.UseAgent && os.Getenv("SSH_AUTH_SOCK") == "" {
+		v.addError(errors.New("SSH agent authentication is enabled, but SSH_AUTH_SOCK is not set. Start an SSH agent and add the SSH keys to it"))
+		keysValid = false
+	}
+
+	if keysValid {
 		var wg sync.WaitGroup
 		errQueue := make(chan error, len(s.Nodes))
 		// number of nodes
 		wg.Add(len(s.Nodes))
 		for _, node := range s.Nodes {
-			go func(ip string) {
+			go func(node Node) {
 				defer wg.Done()
-				sshErr := ssh.TestConnection(ip, s.SSHConfig.Port, s.SSHConfig.User, s.SSHConfig.Key)
+				sshErr := s.SSHConfig.forNode(node).testConnection(node, s.GeneratedAssetsDir)
 				// Need to send something the buffered channel
 				if sshErr != nil {
-					errQueue <- fmt.Errorf("SSH connectivity validation failed for %q: %v", ip, sshErr)
+					errQueue <- fmt.Errorf("SSH connectivity validation failed for %q: %v", node.IP, sshErr)
 				} else {
 					errQueue <- nil
 				}
-			}(node.IP)
+			}(node)
 		}
 
 		// Wait for all nodes to complete, then close channel
@@ -332,6 +484,15 @@ func (s sshConnectionSet) validate() (bool, []error) {
 	return v.valid()
 }
 
+// validBastionKey verifies the SSH key of the bastion host, if there is one
+func (s sshConnectionSet) validBastionKey() error {
+	b := s.SSHConfig.bastion()
+	if b == nil || b.Key == "" {
+		return nil
+	}
+	return ssh.ValidPrivateKey(b.Key)
+}
+
 type nodeList struct {
 	Nodes []Node
 }
@@ -340,6 +501,7 @@ func (nl nodeList) validate() (bool, []error) {
 	v := newValidator()
 	v.addError(validateNoDuplicateNodeInfo(nl.Nodes)...)
 	v.addError(validateKubeletOptionsDefinedOnce(nl.Nodes)...)
+	v.addError(validateSSHOptionsDefinedOnce(nl.Nodes)...)
 	return v.valid()
 }
 
@@ -384,6 +546,19 @@ func validateKubeletOptionsDefinedOnce(nodes []Node) []error {
 	return errs
 }
 
+func validateSSHOptionsDefinedOnce(nodes []Node) []error {
+	errs := []error{}
+	seenNodes := map[string]Node{}
+	for _, n := range nodes {
+		if val, ok := seenNodes[n.HashCode()]; ok && (val.SSHUser != n.SSHUser || val.SSHKey != n.SSHKey || val.SSHPort != n.SSHPort) {
+			errs = append(errs, fmt.Errorf("Cannot redefine SSH options for node %q", n.Host))
+		} else {
+			seenNodes[n.HashCode()] = n
+		}
+	}
+	return errs
+}
+
 func (ng *NodeGroup) validate() (bool, []error) {
 	v := newValidator()
 	if ng == nil || len(ng.Nodes) <= 0 {
@@ -462,6 +637,18 @@ func (n *Node) validate() (bool, []error) {
 	if ip := net.ParseIP(n.InternalIP); n.InternalIP != "" && ip == nil {
 		v.addError(fmt.Errorf("Invalid InternalIP provided"))
 	}
+	if n.SSHKey != "" {
+		if _, err := os.Stat(n.SSHKey); os.IsNotExist(err) {
+			v.addError(fmt.Errorf("Node SSH Key file was not found at %q", n.SSHKey))
+		}
+		if !filepath.IsAbs(n.SSHKey) {
+			v.addError(errors.New("Node SSH Key field must be an absolute path"))
+		}
+	}
+	if n.SSHPort < 0 || n.SSHPort > 65535 {
+		v.addError(fmt.Errorf("Node SSH port %d is invalid. Port must be in the range 1-65535", n.SSHPort))
+	}
+	v.addError(validateOptionOverrides("Kubelet", n.KubeletOptions.Overrides, knownComponentFlags[kubernetesMinorVersion].kubelet)...)
 	// validate node labels don't start with 'kismatic/' as that is reserved
 	for key, val := range n.Labels {
 		if strings.HasPrefix(key, "kismatic/") {
@@ -2,6 +2,7 @@ package install
 
 import (
 	"fmt"
+	"os"
 	"testing"
 )
 
@@ -168,6 +169,84 @@ func TestValidatePlanInvalidCACertificatesExpiry(t *testing.T) {
 	assertInvalidPlan(t, p)
 }
 
+func TestValidatePlanIntermediateCAMissingKey(t *testing.T) {
+	p := validPlan
+	p.Cluster.Certificates.IntermediateCACert = "/etc/ssl/intermediate.pem"
+	p.Cluster.Certificates.IntermediateCAChain = "/etc/ssl/chain.pem"
+	assertInvalidPlan(t, p)
+}
+
+func TestValidatePlanIntermediateCARelativePath(t *testing.T) {
+	p := validPlan
+	p.Cluster.Certificates.IntermediateCACert = "intermediate.pem"
+	p.Cluster.Certificates.IntermediateCAKey = "intermediate-key.pem"
+	p.Cluster.Certificates.IntermediateCAChain = "chain.pem"
+	assertInvalidPlan(t, p)
+}
+
+func TestValidatePlanRemoteSigner(t *testing.T) {
+	tests := []struct {
+		signer RemoteSigner
+		valid  bool
+	}{
+		{
+			signer: RemoteSigner{URL: "https://cfssl.example.com:8888", Profile: "kubernetes", AuthKey: "0123456789abcdef"},
+			valid:  true,
+		},
+		{
+			signer: RemoteSigner{},
+		},
+		{
+			signer: RemoteSigner{URL: "cfssl.example.com"},
+		},
+		{
+			signer: RemoteSigner{URL: "https://cfssl.example.com", AuthKey: "not-hex"},
+		},
+	}
+	for i, test := range tests {
+		p := validPlan
+		p.Cluster.Certificates.RemoteSigner = &test.signer
+		if valid, errs := p.validate(); valid != test.valid {
+			t.Errorf("test %d: expected valid to be %v, but got %v: %v", i, test.valid, valid, errs)
+		}
+	}
+}
+
+func TestValidatePlanRemoteSignerWithIntermediateCA(t *testing.T) {
+	p := validPlan
+	p.Cluster.Certificates.RemoteSigner = &RemoteSigner{URL: "https://cfssl.example.com:8888"}
+	p.Cluster.Certificates.IntermediateCACert = "/etc/ssl/intermediate.pem"
+	p.Cluster.Certificates.IntermediateCAKey = "/etc/ssl/intermediate-key.pem"
+	p.Cluster.Certificates.IntermediateCAChain = "/etc/ssl/chain.pem"
+	assertInvalidPlan(t, p)
+}
+
+func TestValidatePlanCertificateKey(t *testing.T) {
+	tests := []struct {
+		algorithm string
+		size      int
+		valid     bool
+	}{
+		{valid: true},
+		{algorithm: "rsa", valid: true},
+		{algorithm: "rsa", size: 4096, valid: true},
+		{algorithm: "ecdsa", valid: true},
+		{algorithm: "ecdsa", size: 384, valid: true},
+		{algorithm: "rsa", size: 1024},
+		{algorithm: "ecdsa", size: 2048},
+		{size: 256},
+		{algorithm: "dsa"},
+	}
+	for _, test := range tests {
+		p := validPlan
+		p.Cluster.Certificates.KeyAlgorithm = test.algorithm
+		p.Cluster.Certificates.KeySize = test.size
+		if valid, errs := p.validate(); valid != test.valid {
+			t.Errorf("%s %d: expected valid to be %v, but got %v: %v", test.algorithm, test.size, test.valid, valid, errs)
+		}
+	}
+}
+
 func TestValidatePlanEmptySSHUser(t *testing.T) {
 	p := validPlan
 	p.Cluster.SSH.User = ""
@@ -180,6 +259,26 @@ func TestValidatePlanEmptySSHKey(t *testing.T) {
 	assertInvalidPlan(t, p)
 }
 
+func TestValidatePlanSSHAgentWithoutKey(t *testing.T) {
+	p := validPlan
+	p.Cluster.SSH.Key = ""
+	p.Cluster.SSH.UseAgent = true
+	valid, errs := p.Cluster.SSH.validate()
+	if !valid {
+		t.Errorf("expected SSH config to be valid, but got errors: %v", errs)
+	}
+}
+
+func TestValidateSSHConnectionsAgentNotRunning(t *testing.T) {
+	orig := os.Getenv("SSH_AUTH_SOCK")
+	os.Unsetenv("SSH_AUTH_SOCK")
+	defer os.Setenv("SSH_AUTH_SOCK", orig)
+	s := sshConnectionSet{SSHConfig: SSHConfig{User: "kismatic", Port: 22, UseAgent: true}}
+	if valid, _ := s.validate(); valid {
+		t.Errorf("expected SSH connections to be invalid without a running SSH agent")
+	}
+}
+
 func TestValidatePlanNonExistentSSHKey(t *testing.T) {
 	p := validPlan
 	p.Cluster.SSH.Key = "/foo"
@@ -192,6 +291,57 @@ func TestValidatePlanNegativeSSHPort(t *testing.T) {
 	assertInvalidPlan(t, p)
 }
 
+func TestValidatePlanInvalidSSHClient(t *testing.T) {
+	p := validPlan
+	p.Cluster.SSH.Client = "foo"
+	assertInvalidPlan(t, p)
+}
+
+func TestValidatePlanNativeSSHClient(t *testing.T) {
+	p := validPlan
+	p.Cluster.SSH.Client = "native"
+	p.Cluster.SSH.ConnectTimeout = "30s"
+	p.Cluster.SSH.CommandTimeout = "5m"
+	valid, errs := p.Cluster.SSH.validate()
+	if !valid {
+		t.Errorf("expected SSH config to be valid, but got errors: %v", errs)
+	}
+}
+
+func TestValidatePlanInvalidSSHTimeouts(t *testing.T) {
+	p := validPlan
+	p.Cluster.SSH.ConnectTimeout = "foo"
+	assertInvalidPlan(t, p)
+
+	p = validPlan
+	p.Cluster.SSH.CommandTimeout = "10"
+	assertInvalidPlan(t, p)
+}
+
+func TestValidatePlanBastion(t *testing.T) {
+	p := validPlan
+	p.Cluster.SSH.Bastion = &Bastion{Host: "bastion.example.com"}
+	valid, errs := p.Cluster.SSH.validate()
+	if !valid {
+		t.Errorf("expected SSH config to be valid, but got errors: %v", errs)
+	}
+}
+
+func TestValidatePlanInvalidBastion(t *testing.T) {
+	tests := []Bastion{
+		{},
+		{Host: "bastion", Key: "relative/key"},
+		{Host: "bastion", Key: "/foo"},
+		{Host: "bastion", Port: 70000},
+	}
+	for _, b := range tests {
+		p := validPlan
+		bastion := b
+		p.Cluster.SSH.Bastion = &bastion
+		assertInvalidPlan(t, p)
+	}
+}
+
 func TestValidatePlanEmptyLoadBalancedFQDN(t *testing.T) {
 	p := validPlan
 	p.Master.LoadBalancedFQDN = ""
@@ -1536,3 +1686,83 @@ func TestNodeKubeletOptions(t *testing.T) {
 		}
 	}
 }
+
+func TestNodeSSHOptions(t *testing.T) {
+	tests := []struct {
+		nl    nodeList
+		valid bool
+	}{
+		{
+			nl: nodeList{
+				[]Node{
+					{Host: "host1", IP: "10.0.0.1", SSHUser: "alice", SSHPort: 2222},
+					{Host: "host2", IP: "10.0.0.2", SSHUser: "bob"},
+				},
+			},
+			valid: true,
+		},
+		{
+			nl: nodeList{
+				[]Node{
+					{Host: "host1", IP: "10.0.0.1", SSHUser: "alice"},
+					{Host: "host1", IP: "10.0.0.1", SSHUser: "alice"},
+				},
+			},
+			valid: true,
+		},
+		{
+			nl: nodeList{
+				[]Node{
+					{Host: "host1", IP: "10.0.0.1", SSHUser: "alice"},
+					{Host: "host1", IP: "10.0.0.1", SSHUser: "bob"},
+				},
+			},
+			valid: false,
+		},
+		{
+			nl: nodeList{
+				[]Node{
+					{Host: "host1", IP: "10.0.0.1", SSHPort: 2222},
+					{Host: "host1", IP: "10.0.0.1"},
+				},
+			},
+			valid: false,
+		},
+	}
+	for i, test := range tests {
+		ok, _ := test.nl.validate()
+		if ok != test.valid {
+			t.Errorf("test %d: expect %t, but got %t", i, test.valid, ok)
+		}
+	}
+}
+
+func TestValidateNodeSSHOverrides(t *testing.T) {
+	tests := []struct {
+		node  Node
+		valid bool
+	}{
+		{
+			node:  Node{Host: "host1", IP: "10.0.0.1", SSHUser: "alice", SSHKey: "/bin/sh", SSHPort: 2222},
+			valid: true,
+		},
+		{
+			node:  Node{Host: "host1", IP: "10.0.0.1", SSHKey: "bin/sh"},
+			valid: false,
+		},
+		{
+			node:  Node{Host: "host1", IP: "10.0.0.1", SSHKey: "/foo"},
+			valid: false,
+		},
+		{
+			node:  Node{Host: "host1", IP: "10.0.0.1", SSHPort: 65536},
+			valid: false,
+		},
+	}
+	for i, test := range tests {
+		ok, _ := test.node.validate()
+		if ok != test.valid {
+			t.Errorf("test %d: expect %t, but got %t", i, test.valid, ok)
+		}
+	}
+}
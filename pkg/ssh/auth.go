@@ -0,0 +1,277 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// PassphraseEnvVar is the environment variable that holds the passphrase of
+// encrypted SSH keys. The passphrase is prompted for when it is not set.
+const PassphraseEnvVar = "KISMATIC_SSH_KEY_PASSPHRASE"
+
+// ReadPassphrase returns the passphrase of the encrypted key file
+var ReadPassphrase = func(keyFile string) ([]byte, error) {
+	if p, ok := os.LookupEnv(PassphraseEnvVar); ok {
+		return []byte(p), nil
+	}
+	fd := int(os.Stdin.Fd())
+	if !terminal.IsTerminal(fd) {
+		return nil, fmt.Errorf("SSH key %q is encrypted: set the passphrase in the %s environment variable", keyFile, PassphraseEnvVar)
+	}
+	fmt.Fprintf(os.Stderr, "Enter passphrase for SSH key %q: ", keyFile)
+	p, err := terminal.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return nil, fmt.Errorf("error reading passphrase: %v", err)
+	}
+	return p, nil
+}
+
+// privateKey is a parsed, and if needed decrypted, private key
+type privateKey struct {
+	raw       interface{}
+	signer    ssh.Signer
+	encrypted bool
+}
+
+// keys caches the private keys, so that the passphrase of encrypted keys is only read once
+var keys = struct {
+	sync.Mutex
+	byFile map[string]*privateKey
+}{byFile: map[string]*privateKey{}}
+
+// ValidPrivateKey verifies that the SSH private key can be used. Encrypted keys
+// are decrypted with the passphrase returned by ReadPassphrase.
+func ValidPrivateKey(file string) error {
+	fi, err := os.Stat(file)
+	if err != nil {
+		// Abort if key not accessible
+		return err
+	}
+	if err := validKeyPermissions(file, fi); err != nil {
+		return err
+	}
+	_, err = loadPrivateKey(file)
+	return err
+}
+
+func validKeyPermissions(file string, fi os.FileInfo) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	// Private key file should have strict permissions
+	perm := fi.Mode().Perm()
+	if perm&0400 == 0 {
+		return fmt.Errorf("'%s' is not readable", file)
+	}
+	if perm&0077 != 0 {
+		return fmt.Errorf("permissions %#o for '%s' are too open. Permissions should be set to 0600.", perm, file)
+	}
+	return nil
+}
+
+func loadPrivateKey(file string) (*privateKey, error) {
+	keys.Lock()
+	defer keys.Unlock()
+	if k, ok := keys.byFile[file]; ok {
+		return k, nil
+	}
+	buffer, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	k := &privateKey{}
+	k.raw, err = ssh.ParseRawPrivateKey(buffer)
+	if _, ok := err.(*ssh.PassphraseMissingError); ok {
+		k.encrypted = true
+		passphrase, err := ReadPassphrase(file)
+		if err != nil {
+			return nil, err
+		}
+		if k.raw, err = ssh.ParseRawPrivateKeyWithPassphrase(buffer, passphrase); err != nil {
+			return nil, fmt.Errorf("Parse SSH key error: %v", err)
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("Parse SSH key error: %v", err)
+	}
+	if k.signer, err = ssh.NewSignerFromKey(k.raw); err != nil {
+		return nil, fmt.Errorf("Parse SSH key error: %v", err)
+	}
+	keys.byFile[file] = k
+	return k, nil
+}
+
+// authMethods returns the methods for authenticating with the key and,
+// if useAgent is true, with the keys of the running SSH agent
+func authMethods(key string, useAgent bool) ([]ssh.AuthMethod, error) {
+	auth := []ssh.AuthMethod{}
+	if key != "" {
+		k, err := loadPrivateKey(key)
+		if err != nil {
+			return nil, err
+		}
+		auth = append(auth, ssh.PublicKeys(k.signer))
+	}
+	if useAgent {
+		a, err := runningAgent()
+		if err != nil {
+			return nil, err
+		}
+		auth = append(auth, ssh.PublicKeysCallback(a.Signers))
+	}
+	if len(auth) == 0 {
+		return nil, errors.New("an SSH key or an SSH agent is required for authentication")
+	}
+	return auth, nil
+}
+
+// runningAgent returns a client of the SSH agent listening on SSH_AUTH_SOCK
+func runningAgent() (agent.ExtendedAgent, error) {
+	sock := os.Getenv("SSH_AUTH_SOCK")
+	if sock == "" {
+		return nil, errors.New("SSH agent authentication requires a running SSH agent, but SSH_AUTH_SOCK is not set")
+	}
+	conn, err := net.Dial("unix", sock)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to the SSH agent at %q: %v", sock, err)
+	}
+	return agent.NewClient(conn), nil
+}
+
+// localAgent serves the decrypted keys to the ssh processes started by
+// kismatic, as they cannot prompt for the passphrase of encrypted keys
+var localAgent struct {
+	sync.Mutex
+	dir      string
+	sock     string
+	listener net.Listener
+	keyring  agent.Agent
+	added    map[string]bool
+}
+
+// AgentSocket loads the encrypted keys into an SSH agent run by kismatic, and
+// returns the socket the agent listens on. Keys that are not encrypted are
+// ignored. An empty string is returned if none of the keys are encrypted. The
+// agent forwards the requests for other keys to the SSH agent that was running
+// when it started, if any.
+func AgentSocket(keyFiles ...string) (string, error) {
+	localAgent.Lock()
+	defer localAgent.Unlock()
+	for _, file := range keyFiles {
+		if file == "" || localAgent.added[file] {
+			continue
+		}
+		k, err := loadPrivateKey(file)
+		if err != nil {
+			return "", err
+		}
+		if !k.encrypted {
+			continue
+		}
+		if localAgent.keyring == nil {
+			if err := startLocalAgent(); err != nil {
+				return "", err
+			}
+		}
+		if err := localAgent.keyring.Add(agent.AddedKey{PrivateKey: k.raw, Comment: file}); err != nil {
+			return "", fmt.Errorf("error adding SSH key %q to the SSH agent: %v", file, err)
+		}
+		localAgent.added[file] = true
+	}
+	return localAgent.sock, nil
+}
+
+func startLocalAgent() error {
+	dir, err := ioutil.TempDir("", "kismatic-ssh-agent")
+	if err != nil {
+		return fmt.Errorf("error creating directory for the SSH agent socket: %v", err)
+	}
+	sock := filepath.Join(dir, "agent.sock")
+	l, err := net.Listen("unix", sock)
+	if err != nil {
+		os.RemoveAll(dir)
+		return fmt.Errorf("error starting SSH agent: %v", err)
+	}
+	keyring := &forwardingAgent{ExtendedAgent: agent.NewKeyring().(agent.ExtendedAgent)}
+	if os.Getenv("SSH_AUTH_SOCK") != "" {
+		if upstream, err := runningAgent(); err == nil {
+			keyring.upstream = upstream
+		}
+	}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				agent.ServeAgent(keyring, conn)
+				conn.Close()
+			}()
+		}
+	}()
+	localAgent.dir = dir
+	localAgent.sock = sock
+	localAgent.listener = l
+	localAgent.keyring = keyring
+	localAgent.added = map[string]bool{}
+	return nil
+}
+
+// stopLocalAgent stops the local agent and removes its socket, if it was started
+func stopLocalAgent() {
+	localAgent.Lock()
+	defer localAgent.Unlock()
+	if localAgent.listener == nil {
+		return
+	}
+	localAgent.listener.Close()
+	os.RemoveAll(localAgent.dir)
+	localAgent.dir = ""
+	localAgent.sock = ""
+	localAgent.listener = nil
+	localAgent.keyring = nil
+	localAgent.added = nil
+}
+
+// forwardingAgent holds keys, and forwards the requests for the other
+// keys to an upstream agent
+type forwardingAgent struct {
+	agent.ExtendedAgent
+	upstream agent.ExtendedAgent
+}
+
+func (a *forwardingAgent) List() ([]*agent.Key, error) {
+	list, err := a.ExtendedAgent.List()
+	if err != nil || a.upstream == nil {
+		return list, err
+	}
+	upstream, err := a.upstream.List()
+	if err != nil {
+		// the upstream agent might be gone, the local keys are still usable
+		return list, nil
+	}
+	return append(list, upstream...), nil
+}
+
+func (a *forwardingAgent) Sign(key ssh.PublicKey, data []byte) (*ssh.Signature, error) {
+	return a.SignWithFlags(key, data, 0)
+}
+
+func (a *forwardingAgent) SignWithFlags(key ssh.PublicKey, data []byte, flags agent.SignatureFlags) (*ssh.Signature, error) {
+	sig, err := a.ExtendedAgent.SignWithFlags(key, data, flags)
+	if err != nil && a.upstream != nil {
+		return a.upstream.SignWithFlags(key, data, flags)
+	}
+	return sig, err
+}
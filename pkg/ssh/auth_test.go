@@ -0,0 +1,154 @@
+package ssh
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func writeEncryptedTestKey(t *testing.T, dir string, passphrase string) (string, *rsa.PrivateKey) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("error generating key: %v", err)
+	}
+	block, err := x509.EncryptPEMBlock(rand.Reader, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(key), []byte(passphrase), x509.PEMCipherAES256)
+	if err != nil {
+		t.Fatalf("error encrypting key: %v", err)
+	}
+	file := filepath.Join(dir, "id_rsa_encrypted")
+	if err = ioutil.WriteFile(file, pem.EncodeToMemory(block), 0600); err != nil {
+		t.Fatalf("error writing key: %v", err)
+	}
+	return file, key
+}
+
+// withPassphrase replaces the passphrase reader for the duration of the test
+func withPassphrase(passphrase string) func() {
+	orig := ReadPassphrase
+	ReadPassphrase = func(string) ([]byte, error) { return []byte(passphrase), nil }
+	return func() { ReadPassphrase = orig }
+}
+
+func TestValidPrivateKey(t *testing.T) {
+	defer withPassphrase("secret")()
+	dir, err := ioutil.TempDir("", "ssh-auth-test")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	writeTestKey(t, dir)
+	encrypted, _ := writeEncryptedTestKey(t, dir, "secret")
+
+	if err := ValidPrivateKey(filepath.Join(dir, "id_rsa")); err != nil {
+		t.Errorf("unexpected error for unencrypted key: %v", err)
+	}
+	if err := ValidPrivateKey(encrypted); err != nil {
+		t.Errorf("unexpected error for encrypted key: %v", err)
+	}
+	if err := ValidUnencryptedPrivateKey(encrypted); err == nil {
+		t.Errorf("expected an error for encrypted key, but didn't get one")
+	}
+}
+
+func TestValidPrivateKeyWrongPassphrase(t *testing.T) {
+	defer withPassphrase("wrong")()
+	dir, err := ioutil.TempDir("", "ssh-auth-test")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	encrypted, _ := writeEncryptedTestKey(t, dir, "secret")
+
+	if err := ValidPrivateKey(encrypted); err == nil {
+		t.Errorf("expected an error, but didn't get one")
+	}
+}
+
+func TestNativeClientEncryptedKey(t *testing.T) {
+	defer withPassphrase("secret")()
+	dir, err := ioutil.TempDir("", "ssh-auth-test")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	encrypted, key := writeEncryptedTestKey(t, dir, "secret")
+	pub, err := ssh.NewPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("error getting public key: %v", err)
+	}
+	server := newTestServer(t, pub)
+	defer server.listener.Close()
+
+	client, err := NewNativeClient("127.0.0.1", server.port(), "kismatic", encrypted, NativeClientOptions{})
+	if err != nil {
+		t.Fatalf("error creating client: %v", err)
+	}
+	client.pool = NewConnectionPool()
+	defer client.pool.Close()
+	if out, err := client.Output(false, "hostname"); err != nil || out != "ran: hostname" {
+		t.Errorf("unexpected output %q, error: %v", out, err)
+	}
+}
+
+func TestAgentSocket(t *testing.T) {
+	defer withPassphrase("secret")()
+	defer stopLocalAgent()
+	dir, err := ioutil.TempDir("", "ssh-auth-test")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	writeTestKey(t, dir)
+	encrypted, key := writeEncryptedTestKey(t, dir, "secret")
+
+	// unencrypted keys don't need an agent
+	sock, err := AgentSocket(filepath.Join(dir, "id_rsa"), "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sock != "" {
+		t.Errorf("expected no agent socket, but got %q", sock)
+	}
+
+	sock, err = AgentSocket(encrypted)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sock == "" {
+		t.Fatalf("expected an agent socket, but didn't get one")
+	}
+
+	// authenticate with the agent, without a key file
+	pub, err := ssh.NewPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("error getting public key: %v", err)
+	}
+	server := newTestServer(t, pub)
+	defer server.listener.Close()
+	origSock := os.Getenv("SSH_AUTH_SOCK")
+	os.Setenv("SSH_AUTH_SOCK", sock)
+	defer os.Setenv("SSH_AUTH_SOCK", origSock)
+
+	client, err := NewNativeClient("127.0.0.1", server.port(), "kismatic", "", NativeClientOptions{ClientOptions: ClientOptions{UseAgent: true}})
+	if err != nil {
+		t.Fatalf("error creating client: %v", err)
+	}
+	client.pool = NewConnectionPool()
+	defer client.pool.Close()
+	if out, err := client.Output(false, "hostname"); err != nil || out != "ran: hostname" {
+		t.Errorf("unexpected output %q, error: %v", out, err)
+	}
+}
+
+func TestNativeClientRequiresKeyOrAgent(t *testing.T) {
+	if _, err := NewNativeClient("127.0.0.1", 22, "kismatic", "", NativeClientOptions{}); err == nil {
+		t.Errorf("expected an error, but didn't get one")
+	}
+}
@@ -0,0 +1,76 @@
+package ssh
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// knownHostsMu serializes the access to the known hosts files, as the
+// connections to the nodes are usually established in parallel
+var knownHostsMu sync.Mutex
+
+// knownHosts verifies the host keys against a known_hosts file
+type knownHosts struct {
+	file string
+	// record the keys of the hosts that are not in the file yet,
+	// instead of rejecting them
+	record bool
+	// node is the name of the node reported in the errors, if any
+	node string
+}
+
+// hostKeyCallback returns the callback that verifies the host keys. Host keys are
+// not verified when the known hosts file is empty.
+func hostKeyCallback(knownHostsFile string, record bool, node string) ssh.HostKeyCallback {
+	if knownHostsFile == "" {
+		return ssh.InsecureIgnoreHostKey()
+	}
+	return knownHosts{file: knownHostsFile, record: record, node: node}.verify
+}
+
+// describe returns the name of the host used in the errors
+func (k knownHosts) describe(hostname string) string {
+	if k.node == "" {
+		return hostname
+	}
+	return fmt.Sprintf("node %q (%s)", k.node, hostname)
+}
+
+func (k knownHosts) verify(hostname string, remote net.Addr, key ssh.PublicKey) error {
+	knownHostsMu.Lock()
+	defer knownHostsMu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(k.file), 0700); err != nil {
+		return fmt.Errorf("error creating directory for known hosts file %q: %v", k.file, err)
+	}
+	f, err := os.OpenFile(k.file, os.O_CREATE|os.O_APPEND|os.O_RDWR, 0600)
+	if err != nil {
+		return fmt.Errorf("error opening known hosts file %q: %v", k.file, err)
+	}
+	defer f.Close()
+
+	check, err := knownhosts.New(k.file)
+	if err != nil {
+		return fmt.Errorf("error reading known hosts file %q: %v", k.file, err)
+	}
+	err = check(hostname, remote, key)
+	keyErr, ok := err.(*knownhosts.KeyError)
+	if !ok {
+		return err
+	}
+	if len(keyErr.Want) > 0 {
+		return fmt.Errorf("host key of %s does not match the key recorded in %q. The host might have been reinstalled, or the connection might have been intercepted. If the new key is expected, remove the line for %s from the known hosts file", k.describe(hostname), k.file, knownhosts.Normalize(hostname))
+	}
+	if !k.record {
+		return fmt.Errorf("host key of %s is not recorded in %q. Validate the SSH connections to the nodes to record it", k.describe(hostname), k.file)
+	}
+	if _, err := fmt.Fprintln(f, knownhosts.Line([]string{knownhosts.Normalize(hostname)}, key)); err != nil {
+		return fmt.Errorf("error recording host key of %s in %q: %v", k.describe(hostname), k.file, err)
+	}
+	return nil
+}
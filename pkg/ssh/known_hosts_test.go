@@ -0,0 +1,99 @@
+package ssh
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+func TestNativeClientRecordsAndVerifiesHostKeys(t *testing.T) {
+	client, server, cleanup := newTestClient(t, NativeClientOptions{})
+	defer cleanup()
+	dir, err := ioutil.TempDir("", "known-hosts-test")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	knownHostsFile := filepath.Join(dir, "generated", "known_hosts")
+
+	// the host is unknown
+	client.config.HostKeyCallback = hostKeyCallback(knownHostsFile, false, "")
+	if _, err := client.Output(false, "hostname"); err == nil || !strings.Contains(err.Error(), "is not recorded") {
+		t.Errorf("expected an unknown host error, but got %v", err)
+	}
+
+	// record the host key
+	client.config.HostKeyCallback = hostKeyCallback(knownHostsFile, true, "")
+	if _, err := client.Output(false, "hostname"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b, err := ioutil.ReadFile(knownHostsFile)
+	if err != nil {
+		t.Fatalf("error reading known hosts file: %v", err)
+	}
+	addr := knownhosts.Normalize(net.JoinHostPort("127.0.0.1", strconv.Itoa(server.port())))
+	if !strings.HasPrefix(string(b), addr+" ssh-rsa ") {
+		t.Errorf("expected host key of %s to be recorded, but got %q", addr, string(b))
+	}
+
+	// verify the recorded host key on a new connection
+	client.pool.Close()
+	client.config.HostKeyCallback = hostKeyCallback(knownHostsFile, false, "")
+	if _, err := client.Output(false, "hostname"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestNativeClientHostKeyMismatch(t *testing.T) {
+	client, server, cleanup := newTestClient(t, NativeClientOptions{})
+	defer cleanup()
+	dir, err := ioutil.TempDir("", "known-hosts-test")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	knownHostsFile := filepath.Join(dir, "known_hosts")
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("error generating key: %v", err)
+	}
+	pub, err := ssh.NewPublicKey(&otherKey.PublicKey)
+	if err != nil {
+		t.Fatalf("error getting public key: %v", err)
+	}
+	addr := knownhosts.Normalize(net.JoinHostPort("127.0.0.1", strconv.Itoa(server.port())))
+	if err := ioutil.WriteFile(knownHostsFile, []byte(knownhosts.Line([]string{addr}, pub)+"\n"), 0600); err != nil {
+		t.Fatalf("error writing known hosts file: %v", err)
+	}
+
+	// a mismatch fails, even when recording host keys
+	client.config.HostKeyCallback = hostKeyCallback(knownHostsFile, true, "master01")
+	_, err = client.Output(false, "hostname")
+	if err == nil || !strings.Contains(err.Error(), "does not match") || !strings.Contains(err.Error(), `node "master01"`) || !strings.Contains(err.Error(), "127.0.0.1") {
+		t.Errorf("expected a host key mismatch error naming the node, but got %v", err)
+	}
+}
+
+func TestSSHArgsHostKeyChecking(t *testing.T) {
+	args := strings.Join(sshArgs(""), " ")
+	if !strings.Contains(args, "StrictHostKeyChecking=no") || !strings.Contains(args, "UserKnownHostsFile=/dev/null") {
+		t.Errorf("expected host key checking to be disabled, but got %q", args)
+	}
+	args = strings.Join(sshArgs("/generated/known_hosts"), " ")
+	if !strings.Contains(args, "StrictHostKeyChecking=yes") || !strings.Contains(args, "UserKnownHostsFile=/generated/known_hosts") {
+		t.Errorf("expected host key checking to be enabled, but got %q", args)
+	}
+	if strings.Contains(strings.Join(baseSSHArgs, " "), "StrictHostKeyChecking=yes") {
+		t.Errorf("base ssh arguments were modified")
+	}
+}
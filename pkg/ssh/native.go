@@ -0,0 +1,382 @@
+package ssh
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+const (
+	defaultConnectTimeout = 10 * time.Second
+	// retry 3 times if SSH connection fails, same as the external client
+	connectionAttempts = 3
+)
+
+// NativeClientOptions are used to configure the native SSH client
+type NativeClientOptions struct {
+	// ConnectTimeout is the maximum amount of time to wait for a connection
+	// to be established. Defaults to 10 seconds.
+	ConnectTimeout time.Duration
+	// CommandTimeout is the maximum amount of time a command is allowed to run.
+	// Commands are not timed out when zero.
+	CommandTimeout time.Duration
+	// RecordHostKeys adds the keys of the hosts that are not in the known
+	// hosts file to the file, instead of rejecting the connection
+	RecordHostKeys bool
+	// NodeName is the name of the node, reported in the host key
+	// verification errors in addition to its address
+	NodeName string
+	ClientOptions
+}
+
+// NativeClient is an SSH client that is implemented using golang.org/x/crypto/ssh.
+// Connections are kept open, and reused by all the clients of the same host.
+type NativeClient struct {
+	addr    string
+	config  *ssh.ClientConfig
+	options NativeClientOptions
+	pool    *ConnectionPool
+	// bastion is nil when connecting to the host directly
+	bastion *NativeClient
+}
+
+// NewNativeClient returns an SSH client that does not depend on the ssh binary
+func NewNativeClient(host string, port int, user string, key string, options NativeClientOptions) (*NativeClient, error) {
+	if options.ConnectTimeout == 0 {
+		options.ConnectTimeout = defaultConnectTimeout
+	}
+	config, err := clientConfig(user, key, options.UseAgent, options.ConnectTimeout, hostKeyCallback(options.KnownHostsFile, options.RecordHostKeys, options.NodeName))
+	if err != nil {
+		return nil, err
+	}
+	client := &NativeClient{
+		addr:    net.JoinHostPort(host, strconv.Itoa(port)),
+		config:  config,
+		options: options,
+		pool:    defaultPool,
+	}
+	if b := options.Bastion; b != nil {
+		bastionOpts := NativeClientOptions{
+			ConnectTimeout: options.ConnectTimeout,
+			RecordHostKeys: options.RecordHostKeys,
+			ClientOptions:  ClientOptions{KnownHostsFile: options.KnownHostsFile, UseAgent: options.UseAgent},
+		}
+		if client.bastion, err = NewNativeClient(b.Host, b.Port, b.User, b.Key, bastionOpts); err != nil {
+			return nil, fmt.Errorf("bastion: %v", err)
+		}
+	}
+	return client, nil
+}
+
+func clientConfig(user, key string, useAgent bool, timeout time.Duration, hostKeyCallback ssh.HostKeyCallback) (*ssh.ClientConfig, error) {
+	if key != "" {
+		if err := ValidPrivateKey(key); err != nil {
+			return nil, err
+		}
+	}
+	auth, err := authMethods(key, useAgent)
+	if err != nil {
+		return nil, err
+	}
+	return &ssh.ClientConfig{
+		User:            user,
+		Auth:            auth,
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         timeout,
+	}, nil
+}
+
+// Output runs the command and returns the combined stdout and stderr
+func (client *NativeClient) Output(pty bool, args ...string) (string, error) {
+	session, err := client.newSession()
+	if err != nil {
+		return "", err
+	}
+	defer session.Close()
+	if pty {
+		if err = requestPty(session, 80, 40); err != nil {
+			return "", err
+		}
+		// for pseudo-tty and sudo to work correctly Stdin must be set to os.Stdin
+		session.Stdin = os.Stdin
+	}
+	var output bytes.Buffer
+	session.Stdout = &output
+	session.Stderr = &output
+	err = client.run(session, strings.Join(args, " "))
+	return output.String(), err
+}
+
+// Shell runs the command, binding Stdin, Stdout and Stderr. An interactive
+// shell is started when no command is given.
+func (client *NativeClient) Shell(pty bool, args ...string) error {
+	session, err := client.newSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+	session.Stdin = os.Stdin
+	session.Stdout = os.Stdout
+	session.Stderr = os.Stderr
+
+	fd := int(os.Stdin.Fd())
+	if (pty || len(args) == 0) && terminal.IsTerminal(fd) {
+		width, height, err := terminal.GetSize(fd)
+		if err != nil {
+			width, height = 80, 40
+		}
+		if err = requestPty(session, width, height); err != nil {
+			return err
+		}
+		state, err := terminal.MakeRaw(fd)
+		if err != nil {
+			return fmt.Errorf("error setting terminal to raw mode: %v", err)
+		}
+		defer terminal.Restore(fd, state)
+	} else if pty {
+		if err = requestPty(session, 80, 40); err != nil {
+			return err
+		}
+	}
+
+	if len(args) == 0 {
+		if err = session.Shell(); err != nil {
+			return fmt.Errorf("error starting shell: %v", err)
+		}
+		return session.Wait()
+	}
+	return client.run(session, strings.Join(args, " "))
+}
+
+// Upload copies the local file to the remote path using the scp protocol.
+// The file mode of the local file is preserved.
+func (client *NativeClient) Upload(localPath string, remotePath string) error {
+	f, err := os.Open(localPath)
+	if err != nil {
+		return fmt.Errorf("error opening file %q: %v", localPath, err)
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("error reading file %q: %v", localPath, err)
+	}
+	session, err := client.newSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+	stdin, err := session.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("error uploading file: %v", err)
+	}
+	var output bytes.Buffer
+	session.Stdout = &output
+	session.Stderr = &output
+	go func() {
+		defer stdin.Close()
+		fmt.Fprintf(stdin, "C%04o %d %s\n", fi.Mode().Perm(), fi.Size(), path.Base(remotePath))
+		io.Copy(stdin, f)
+		fmt.Fprint(stdin, "\x00")
+	}()
+	if err := client.run(session, "scp -qt "+shellQuote(path.Dir(remotePath))); err != nil {
+		return fmt.Errorf("error uploading %q to %q: %v: %s", localPath, remotePath, err, output.String())
+	}
+	return nil
+}
+
+// run the command in the session, enforcing the command timeout
+func (client *NativeClient) run(session *ssh.Session, cmd string) error {
+	if client.options.CommandTimeout == 0 {
+		return session.Run(cmd)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- session.Run(cmd)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(client.options.CommandTimeout):
+		session.Signal(ssh.SIGKILL)
+		session.Close()
+		return fmt.Errorf("command timed out after %v", client.options.CommandTimeout)
+	}
+}
+
+// Dial connects to the address from the host, tunneling the connection
+// through SSH. It can be used to reach ports that are only accessible
+// from the host.
+func (client *NativeClient) Dial(network, addr string) (net.Conn, error) {
+	conn, err := client.pool.get(client.key(), client.dial)
+	if err != nil {
+		return nil, err
+	}
+	tunnel, err := conn.Dial(network, addr)
+	if err == nil {
+		return tunnel, nil
+	}
+	client.pool.remove(client.key(), conn)
+	if conn, err = client.pool.get(client.key(), client.dial); err != nil {
+		return nil, err
+	}
+	if tunnel, err = conn.Dial(network, addr); err != nil {
+		return nil, fmt.Errorf("error connecting to %s through %s: %v", addr, client.addr, err)
+	}
+	return tunnel, nil
+}
+
+// newSession opens a new session on the pooled connection to the host.
+// If the connection is broken, a new one is established.
+func (client *NativeClient) newSession() (*ssh.Session, error) {
+	conn, err := client.pool.get(client.key(), client.dial)
+	if err != nil {
+		return nil, err
+	}
+	session, err := conn.NewSession()
+	if err == nil {
+		return session, nil
+	}
+	client.pool.remove(client.key(), conn)
+	if conn, err = client.pool.get(client.key(), client.dial); err != nil {
+		return nil, err
+	}
+	if session, err = conn.NewSession(); err != nil {
+		return nil, fmt.Errorf("error opening SSH session to %s: %v", client.addr, err)
+	}
+	return session, nil
+}
+
+// key identifies the connection of the client in the pool
+func (client *NativeClient) key() string {
+	key := client.config.User + "@" + client.addr
+	if client.bastion != nil {
+		key = key + " via " + client.bastion.key()
+	}
+	return key
+}
+
+// dial establishes a new connection to the host, retrying on failure
+func (client *NativeClient) dial() (*ssh.Client, error) {
+	var conn *ssh.Client
+	var err error
+	for i := 0; i < connectionAttempts; i++ {
+		if conn, err = client.dialOnce(); err == nil {
+			return conn, nil
+		}
+	}
+	return nil, fmt.Errorf("error connecting to %s: %v", client.addr, err)
+}
+
+func (client *NativeClient) dialOnce() (*ssh.Client, error) {
+	if client.bastion == nil {
+		return ssh.Dial("tcp", client.addr, client.config)
+	}
+	tunnel, err := client.bastion.Dial("tcp", client.addr)
+	if err != nil {
+		return nil, err
+	}
+	c, chans, reqs, err := ssh.NewClientConn(tunnel, client.addr, client.config)
+	if err != nil {
+		tunnel.Close()
+		return nil, err
+	}
+	return ssh.NewClient(c, chans, reqs), nil
+}
+
+func requestPty(session *ssh.Session, width, height int) error {
+	modes := ssh.TerminalModes{
+		ssh.ECHO:          1,
+		ssh.TTY_OP_ISPEED: 14400,
+		ssh.TTY_OP_OSPEED: 14400,
+	}
+	term := os.Getenv("TERM")
+	if term == "" {
+		term = "xterm"
+	}
+	if err := session.RequestPty(term, height, width, modes); err != nil {
+		return fmt.Errorf("error requesting pseudo-terminal: %v", err)
+	}
+	return nil
+}
+
+// shellQuote quotes the string so that it is interpreted literally by the shell
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+// ConnectionPool keeps a single SSH connection per host and user, that
+// is shared by all the native clients
+type ConnectionPool struct {
+	mu    sync.Mutex
+	conns map[string]*ssh.Client
+}
+
+var defaultPool = NewConnectionPool()
+
+// NewConnectionPool returns an empty connection pool
+func NewConnectionPool() *ConnectionPool {
+	return &ConnectionPool{conns: map[string]*ssh.Client{}}
+}
+
+// CloseConnections closes the connections that were opened by the native clients,
+// and stops the SSH agent started by AgentSocket
+func CloseConnections() {
+	defaultPool.Close()
+	stopLocalAgent()
+}
+
+// get returns the pooled connection with the key, establishing it with dial if needed
+func (p *ConnectionPool) get(key string, dial func() (*ssh.Client, error)) (*ssh.Client, error) {
+	p.mu.Lock()
+	conn, ok := p.conns[key]
+	p.mu.Unlock()
+	if ok {
+		return conn, nil
+	}
+
+	// Dial without holding the lock, so that connections to different hosts
+	// are established in parallel
+	conn, err := dial()
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if existing, ok := p.conns[key]; ok {
+		conn.Close()
+		return existing, nil
+	}
+	p.conns[key] = conn
+	return conn, nil
+}
+
+// remove closes the connection, and removes it from the pool
+func (p *ConnectionPool) remove(key string, conn *ssh.Client) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.conns[key] == conn {
+		delete(p.conns, key)
+	}
+	conn.Close()
+}
+
+// Close all the connections in the pool
+func (p *ConnectionPool) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for key, conn := range p.conns {
+		conn.Close()
+		delete(p.conns, key)
+	}
+}
@@ -0,0 +1,339 @@
+package ssh
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// testServer is an SSH server that echoes the commands it is asked to run,
+// and records the files uploaded with scp
+type testServer struct {
+	listener net.Listener
+	config   *ssh.ServerConfig
+
+	mu          sync.Mutex
+	connections int
+	uploads     map[string]string
+}
+
+func newTestServer(t *testing.T, authorizedKey ssh.PublicKey) *testServer {
+	hostKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("error generating host key: %v", err)
+	}
+	hostSigner, err := ssh.NewSignerFromKey(hostKey)
+	if err != nil {
+		t.Fatalf("error creating host signer: %v", err)
+	}
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if string(key.Marshal()) == string(authorizedKey.Marshal()) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("unknown key for %q", conn.User())
+		},
+	}
+	config.AddHostKey(hostSigner)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("error listening: %v", err)
+	}
+	s := &testServer{listener: l, config: config, uploads: map[string]string{}}
+	go s.serve()
+	return s
+}
+
+func (s *testServer) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *testServer) connectionCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.connections
+}
+
+func (s *testServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *testServer) handle(c net.Conn) {
+	_, chans, reqs, err := ssh.NewServerConn(c, s.config)
+	if err != nil {
+		return
+	}
+	s.mu.Lock()
+	s.connections++
+	s.mu.Unlock()
+	go ssh.DiscardRequests(reqs)
+	for newChannel := range chans {
+		if newChannel.ChannelType() == "direct-tcpip" {
+			go forward(newChannel)
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer channel.Close()
+			for req := range requests {
+				if req.Type != "exec" {
+					req.Reply(req.Type == "pty-req", nil)
+					continue
+				}
+				req.Reply(true, nil)
+				cmd := string(req.Payload[4:])
+				status := 0
+				switch {
+				case strings.HasPrefix(cmd, "scp -qt "):
+					s.receive(channel, strings.Trim(strings.TrimPrefix(cmd, "scp -qt "), "'"))
+				case cmd == "sleep":
+					time.Sleep(time.Minute)
+				case cmd == "false":
+					status = 1
+				default:
+					fmt.Fprintf(channel, "ran: %s", cmd)
+				}
+				channel.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{uint32(status)}))
+				return
+			}
+		}()
+	}
+}
+
+// forward the direct-tcpip channel to the requested address
+func forward(newChannel ssh.NewChannel) {
+	var target struct {
+		Host       string
+		Port       uint32
+		OriginHost string
+		OriginPort uint32
+	}
+	if err := ssh.Unmarshal(newChannel.ExtraData(), &target); err != nil {
+		newChannel.Reject(ssh.ConnectionFailed, err.Error())
+		return
+	}
+	conn, err := net.Dial("tcp", net.JoinHostPort(target.Host, fmt.Sprint(target.Port)))
+	if err != nil {
+		newChannel.Reject(ssh.ConnectionFailed, err.Error())
+		return
+	}
+	channel, requests, err := newChannel.Accept()
+	if err != nil {
+		conn.Close()
+		return
+	}
+	go ssh.DiscardRequests(requests)
+	go func() {
+		io.Copy(channel, conn)
+		channel.Close()
+	}()
+	go func() {
+		io.Copy(conn, channel)
+		conn.Close()
+	}()
+}
+
+// receive a single file sent with the scp protocol
+func (s *testServer) receive(channel ssh.Channel, dir string) {
+	r := bufio.NewReader(channel)
+	header, _ := r.ReadString('\n')
+	var mode, size int
+	var name string
+	fmt.Sscanf(header, "C%o %d %s", &mode, &size, &name)
+	data := make([]byte, size)
+	r.Read(data)
+	s.mu.Lock()
+	s.uploads[dir+"/"+name] = fmt.Sprintf("%04o %s", mode, data)
+	s.mu.Unlock()
+}
+
+func writeTestKey(t *testing.T, dir string) ssh.PublicKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("error generating key: %v", err)
+	}
+	b := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	if err = ioutil.WriteFile(filepath.Join(dir, "id_rsa"), b, 0600); err != nil {
+		t.Fatalf("error writing key: %v", err)
+	}
+	pub, err := ssh.NewPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("error getting public key: %v", err)
+	}
+	return pub
+}
+
+func newTestClient(t *testing.T, opts NativeClientOptions) (*NativeClient, *testServer, func()) {
+	dir, err := ioutil.TempDir("", "native-ssh-test")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	server := newTestServer(t, writeTestKey(t, dir))
+	client, err := NewNativeClient("127.0.0.1", server.port(), "kismatic", filepath.Join(dir, "id_rsa"), opts)
+	if err != nil {
+		t.Fatalf("error creating client: %v", err)
+	}
+	client.pool = NewConnectionPool()
+	cleanup := func() {
+		client.pool.Close()
+		server.listener.Close()
+		os.RemoveAll(dir)
+	}
+	return client, server, cleanup
+}
+
+func TestNativeClientOutputReusesConnection(t *testing.T) {
+	client, server, cleanup := newTestClient(t, NativeClientOptions{})
+	defer cleanup()
+
+	for i := 0; i < 3; i++ {
+		out, err := client.Output(false, "cat", "/etc/kismatic-version")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if out != "ran: cat /etc/kismatic-version" {
+			t.Errorf("unexpected output %q", out)
+		}
+	}
+	if n := server.connectionCount(); n != 1 {
+		t.Errorf("expected a single connection to the server, but got %d", n)
+	}
+}
+
+func TestNativeClientOutputCommandFailure(t *testing.T) {
+	client, _, cleanup := newTestClient(t, NativeClientOptions{})
+	defer cleanup()
+
+	if _, err := client.Output(false, "false"); err == nil {
+		t.Errorf("expected an error, but didn't get one")
+	}
+}
+
+func TestNativeClientCommandTimeout(t *testing.T) {
+	client, _, cleanup := newTestClient(t, NativeClientOptions{CommandTimeout: 100 * time.Millisecond})
+	defer cleanup()
+
+	_, err := client.Output(false, "sleep")
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("expected a timeout error, but got %v", err)
+	}
+}
+
+func TestNativeClientReconnectsBrokenConnection(t *testing.T) {
+	client, server, cleanup := newTestClient(t, NativeClientOptions{})
+	defer cleanup()
+
+	if _, err := client.Output(false, "hostname"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// break the pooled connection
+	for _, conn := range client.pool.conns {
+		conn.Close()
+	}
+	if _, err := client.Output(false, "hostname"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := server.connectionCount(); n != 2 {
+		t.Errorf("expected 2 connections to the server, but got %d", n)
+	}
+}
+
+func TestNativeClientUpload(t *testing.T) {
+	client, server, cleanup := newTestClient(t, NativeClientOptions{})
+	defer cleanup()
+
+	f, err := ioutil.TempFile("", "upload")
+	if err != nil {
+		t.Fatalf("error creating temp file: %v", err)
+	}
+	defer os.Remove(f.Name())
+	f.WriteString("some content")
+	f.Close()
+	os.Chmod(f.Name(), 0640)
+
+	if err := client.Upload(f.Name(), "/etc/kismatic/file.txt"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if got := server.uploads["/etc/kismatic/file.txt"]; got != "0640 some content" {
+		t.Errorf("unexpected upload %q", got)
+	}
+}
+
+func TestNativeClientUnreachableHost(t *testing.T) {
+	client, server, cleanup := newTestClient(t, NativeClientOptions{ConnectTimeout: time.Second})
+	defer cleanup()
+	server.listener.Close()
+
+	if _, err := client.Output(false, "hostname"); err == nil {
+		t.Errorf("expected an error, but didn't get one")
+	}
+}
+
+func TestNativeClientThroughBastion(t *testing.T) {
+	dir, err := ioutil.TempDir("", "native-ssh-test")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	pub := writeTestKey(t, dir)
+	key := filepath.Join(dir, "id_rsa")
+	bastionServer := newTestServer(t, pub)
+	defer bastionServer.listener.Close()
+	targetServer := newTestServer(t, pub)
+	defer targetServer.listener.Close()
+
+	opts := NativeClientOptions{
+		ClientOptions: ClientOptions{
+			Bastion: &Bastion{Host: "127.0.0.1", Port: bastionServer.port(), User: "bastion", Key: key},
+		},
+	}
+	client, err := NewNativeClient("127.0.0.1", targetServer.port(), "kismatic", key, opts)
+	if err != nil {
+		t.Fatalf("error creating client: %v", err)
+	}
+	pool := NewConnectionPool()
+	defer pool.Close()
+	client.pool = pool
+	client.bastion.pool = pool
+
+	for i := 0; i < 2; i++ {
+		out, err := client.Output(false, "hostname")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if out != "ran: hostname" {
+			t.Errorf("unexpected output %q", out)
+		}
+	}
+	if n := bastionServer.connectionCount(); n != 1 {
+		t.Errorf("expected a single connection to the bastion, but got %d", n)
+	}
+	if n := targetServer.connectionCount(); n != 1 {
+		t.Errorf("expected a single connection to the target, but got %d", n)
+	}
+}
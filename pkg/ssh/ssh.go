@@ -7,7 +7,7 @@ import (
 	"io/ioutil"
 	"os"
 	"os/exec"
-	"runtime"
+	"strings"
 
 	"golang.org/x/crypto/ssh"
 )
@@ -24,6 +24,62 @@ var baseSSHArgs = []string{
 	"-o", "ControlPath=none",
 }
 
+// sshArgs returns the base arguments of the ssh command. Host keys are verified
+// against the known hosts file, unless it is empty.
+func sshArgs(knownHostsFile string) []string {
+	args := append([]string{}, baseSSHArgs...)
+	if knownHostsFile == "" {
+		return args
+	}
+	for i, arg := range args {
+		switch arg {
+		case "StrictHostKeyChecking=no":
+			args[i] = "StrictHostKeyChecking=yes"
+		case "UserKnownHostsFile=/dev/null":
+			args[i] = "UserKnownHostsFile=" + knownHostsFile
+		case "LogLevel=quiet":
+			// report host key verification failures
+			args[i] = "LogLevel=error"
+		}
+	}
+	return args
+}
+
+// ClientOptions are the optional settings of the SSH clients
+type ClientOptions struct {
+	// Bastion is the host through which the connection is established, if any
+	Bastion *Bastion
+	// KnownHostsFile is the known_hosts file used for verifying the host keys.
+	// Host keys are not verified when empty.
+	KnownHostsFile string
+	// UseAgent authenticates with the keys of the SSH agent listening on
+	// SSH_AUTH_SOCK, in addition to the key, if any
+	UseAgent bool
+}
+
+// Bastion is a host through which the connections to the nodes are established
+type Bastion struct {
+	Host string
+	Port int
+	User string
+	Key  string
+}
+
+// ProxyCommand returns the ssh command that tunnels a connection to the
+// host and port given by the %h and %p tokens through the bastion. The host key
+// of the bastion is verified against the known hosts file, unless it is empty.
+func (b Bastion) ProxyCommand(knownHostsFile string) string {
+	args := []string{"ssh"}
+	for _, arg := range sshArgs(knownHostsFile) {
+		args = append(args, shellQuote(arg))
+	}
+	if b.Key != "" {
+		args = append(args, "-i", shellQuote(b.Key))
+	}
+	args = append(args, "-p", fmt.Sprintf("%d", b.Port), "-W", "%h:%p", shellQuote(fmt.Sprintf("%s@%s", b.User, b.Host)))
+	return strings.Join(args, " ")
+}
+
 type Client interface {
 	Output(pty bool, args ...string) (string, error)
 	Shell(pty bool, args ...string) error
@@ -33,11 +89,13 @@ type ExternalClient struct {
 	BaseArgs   []string
 	BinaryPath string
 	cmd        *exec.Cmd
+	// env is the environment of the ssh command, defaults to the current environment when nil
+	env []string
 }
 
 // TestConnection connects to ip:port as user with key and immediately exits.
-func TestConnection(ip string, port int, user, key string) error {
-	client, err := NewClient(ip, port, user, key)
+func TestConnection(ip string, port int, user, key string, opts ClientOptions) error {
+	client, err := NewClient(ip, port, user, key, opts)
 	if err != nil {
 		return err
 	}
@@ -46,9 +104,18 @@ func TestConnection(ip string, port int, user, key string) error {
 }
 
 // NewClient verifies ssh is available in the PATH and returns an SSH client
-func NewClient(host string, port int, user string, key string) (Client, error) {
-	if err := ValidUnencryptedPrivateKey(key); err != nil {
-		return nil, err
+func NewClient(host string, port int, user string, key string, opts ClientOptions) (Client, error) {
+	if key != "" {
+		if err := ValidPrivateKey(key); err != nil {
+			return nil, err
+		}
+	}
+	keys := []string{key}
+	if opts.Bastion != nil && opts.Bastion.Key != "" {
+		if err := ValidPrivateKey(opts.Bastion.Key); err != nil {
+			return nil, fmt.Errorf("bastion SSH key: %v", err)
+		}
+		keys = append(keys, opts.Bastion.Key)
 	}
 
 	sshBinaryPath, err := exec.LookPath("ssh")
@@ -56,16 +123,34 @@ func NewClient(host string, port int, user string, key string) (Client, error) {
 		return nil, fmt.Errorf("command not found: ssh")
 	}
 
-	return newExternalClient(sshBinaryPath, user, host, port, key)
+	client, err := newExternalClient(sshBinaryPath, user, host, port, key, opts)
+	if err != nil {
+		return nil, err
+	}
+	// ssh cannot prompt for the passphrase of the keys, serve them with an agent instead
+	sock, err := AgentSocket(keys...)
+	if err != nil {
+		return nil, err
+	}
+	if sock != "" {
+		client.env = append(os.Environ(), "SSH_AUTH_SOCK="+sock)
+	}
+	return client, nil
 }
 
-func newExternalClient(sshBinaryPath string, user string, host string, port int, key string) (*ExternalClient, error) {
+func newExternalClient(sshBinaryPath string, user string, host string, port int, key string, opts ClientOptions) (*ExternalClient, error) {
 	// Get defailt args with user and host
-	args := append(baseSSHArgs, fmt.Sprintf("%s@%s", user, host))
+	args := sshArgs(opts.KnownHostsFile)
+	if opts.Bastion != nil {
+		args = append(args, "-o", "ProxyCommand="+opts.Bastion.ProxyCommand(opts.KnownHostsFile))
+	}
+	args = append(args, fmt.Sprintf("%s@%s", user, host))
 	// set port
 	args = append(args, "-p", fmt.Sprintf("%d", port))
-	// set key
-	args = append(args, "-i", key)
+	// set key, the keys of the agent are used when there is none
+	if key != "" {
+		args = append(args, "-i", key)
+	}
 
 	client := &ExternalClient{
 		BinaryPath: sshBinaryPath,
@@ -79,6 +164,7 @@ func newExternalClient(sshBinaryPath string, user string, host string, port int,
 func (client *ExternalClient) Output(pty bool, args ...string) (string, error) {
 	args = append(client.BaseArgs, args...)
 	cmd := getSSHCmd(client.BinaryPath, pty, args...)
+	cmd.Env = client.env
 	// for pseudo-tty and sudo to work correctly Stdin must be set to os.Stdin
 	if pty {
 		cmd.Stdin = os.Stdin
@@ -91,6 +177,7 @@ func (client *ExternalClient) Output(pty bool, args ...string) (string, error) {
 func (client *ExternalClient) Shell(pty bool, args ...string) error {
 	args = append(client.BaseArgs, args...)
 	cmd := getSSHCmd(client.BinaryPath, pty, args...)
+	cmd.Env = client.env
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
@@ -132,20 +219,7 @@ func ValidUnencryptedPrivateKey(file string) error {
 		return fmt.Errorf("Parse SSH key error: %v", err)
 	}
 
-	if runtime.GOOS != "windows" {
-		mode := fi.Mode()
-
-		// Private key file should have strict permissions
-		perm := mode.Perm()
-		if perm&0400 == 0 {
-			return fmt.Errorf("'%s' is not readable", file)
-		}
-		if perm&0077 != 0 {
-			return fmt.Errorf("permissions %#o for '%s' are too open. Permissions should be set to 0600.", perm, file)
-		}
-	}
-
-	return nil
+	return validKeyPermissions(file, fi)
 }
 
 func isEncrypted(buffer []byte) (bool, error) {
@@ -1,6 +1,37 @@
 package ssh
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
+
+func TestBastionProxyCommand(t *testing.T) {
+	b := Bastion{Host: "bastion.example.com", Port: 2222, User: "alice", Key: "/home/alice/my key"}
+	cmd := b.ProxyCommand("")
+	if !strings.HasPrefix(cmd, "ssh ") {
+		t.Errorf("expected proxy command to run ssh, but got %q", cmd)
+	}
+	if !strings.HasSuffix(cmd, `-i '/home/alice/my key' -p 2222 -W %h:%p 'alice@bastion.example.com'`) {
+		t.Errorf("unexpected proxy command %q", cmd)
+	}
+}
+
+func TestExternalClientBastion(t *testing.T) {
+	b := &Bastion{Host: "bastion", Port: 22, User: "alice", Key: "/key"}
+	client, err := newExternalClient("/usr/bin/ssh", "bob", "10.0.0.1", 22, "/key", ClientOptions{Bastion: b})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	found := false
+	for _, arg := range client.BaseArgs {
+		if arg == "ProxyCommand="+b.ProxyCommand("") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected ProxyCommand option in ssh arguments %v", client.BaseArgs)
+	}
+}
 
 func TestIsEncrypted(t *testing.T) {
 	for _, data := range testData {
@@ -1,13 +1,19 @@
 package tls
 
 import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
 	"encoding/json"
+	"encoding/pem"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/cloudflare/cfssl/csr"
+	"github.com/cloudflare/cfssl/helpers"
 	"github.com/cloudflare/cfssl/initca"
 	"github.com/cloudflare/cfssl/log"
 )
@@ -25,8 +31,25 @@ type Subject struct {
 	OrganizationalUnit string
 }
 
+// CSRName returns the name of a certificate request with the subject fields
+func (s Subject) CSRName() csr.Name {
+	return csr.Name{
+		C:  s.Country,
+		ST: s.State,
+		L:  s.Locality,
+		O:  s.Organization,
+		OU: s.OrganizationalUnit,
+	}
+}
+
 // NewCACert creates a new Certificate Authority and returns it's private key and public certificate.
 func NewCACert(csrFile string, commonName string, expiry string) (key, cert []byte, err error) {
+	return NewCustomCACert(csrFile, commonName, expiry, nil, nil)
+}
+
+// NewCustomCACert creates a new Certificate Authority like NewCACert, but the key
+// request and the subject of the CSR file are replaced when they are not nil.
+func NewCustomCACert(csrFile string, commonName string, expiry string, keyRequest csr.KeyRequest, subject *Subject) (key, cert []byte, err error) {
 	// Open CSR file
 	f, err := os.Open(csrFile)
 	if os.IsNotExist(err) {
@@ -45,6 +68,12 @@ func NewCACert(csrFile string, commonName string, expiry string) (key, cert []by
 	}
 	caCSR.CN = commonName
 	caCSR.CA = &csr.CAConfig{Expiry: expiry}
+	if keyRequest != nil {
+		caCSR.KeyRequest = keyRequest
+	}
+	if subject != nil {
+		caCSR.Names = []csr.Name{subject.CSRName()}
+	}
 	// Generate CA Cert according to CSR
 	cert, _, key, err = initca.New(caCSR)
 	if err != nil {
@@ -67,3 +96,87 @@ func ReadCACert(name, dir string) (key, cert []byte, err error) {
 	}
 	return key, cert, nil
 }
+
+// ReadIntermediateCA reads an existing intermediate Certificate Authority, and
+// verifies that it chains to a root CA through the certificates in the chain file.
+// The key and certificate of the intermediate CA are returned, along with a
+// certificate bundle that contains the intermediate CA certificate followed by its chain.
+func ReadIntermediateCA(certFile, keyFile, chainFile string) (key, cert, bundle []byte, err error) {
+	certPEM, err := ioutil.ReadFile(certFile)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error reading intermediate CA certificate: %v", err)
+	}
+	certs, err := helpers.ParseCertificatesPEM(certPEM)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error parsing intermediate CA certificate: %v", err)
+	}
+	if len(certs) == 0 {
+		return nil, nil, nil, fmt.Errorf("no certificate found in %q", certFile)
+	}
+	caCert := certs[0]
+	if !caCert.BasicConstraintsValid || !caCert.IsCA {
+		return nil, nil, nil, fmt.Errorf("certificate %q is not a CA certificate", certFile)
+	}
+	if time.Now().After(caCert.NotAfter) {
+		return nil, nil, nil, fmt.Errorf("intermediate CA certificate expired on %s", caCert.NotAfter)
+	}
+
+	key, err = ioutil.ReadFile(keyFile)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error reading intermediate CA private key: %v", err)
+	}
+	priv, err := helpers.ParsePrivateKeyPEM(key)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error parsing intermediate CA private key: %v", err)
+	}
+	if !publicKeysEqual(priv.Public(), caCert.PublicKey) {
+		return nil, nil, nil, fmt.Errorf("private key %q does not match the intermediate CA certificate", keyFile)
+	}
+
+	chainPEM, err := ioutil.ReadFile(chainFile)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error reading intermediate CA chain: %v", err)
+	}
+	chain, err := helpers.ParseCertificatesPEM(chainPEM)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error parsing intermediate CA chain: %v", err)
+	}
+	roots := x509.NewCertPool()
+	intermediates := x509.NewCertPool()
+	for _, c := range chain {
+		if bytes.Equal(c.RawIssuer, c.RawSubject) && c.CheckSignatureFrom(c) == nil {
+			roots.AddCert(c)
+			continue
+		}
+		intermediates.AddCert(c)
+	}
+	opts := x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}
+	if _, err := caCert.Verify(opts); err != nil {
+		return nil, nil, nil, fmt.Errorf("intermediate CA certificate does not chain to a root CA in %q: %v", chainFile, err)
+	}
+
+	// The bundle is only used to verify the certificates presented by the cluster,
+	// starting with the CA that signs the cluster certificates
+	cert = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caCert.Raw})
+	bundle = append([]byte{}, cert...)
+	for _, c := range chain {
+		bundle = append(bundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.Raw})...)
+	}
+	return key, cert, bundle, nil
+}
+
+func publicKeysEqual(a, b crypto.PublicKey) bool {
+	aDER, err := x509.MarshalPKIXPublicKey(a)
+	if err != nil {
+		return false
+	}
+	bDER, err := x509.MarshalPKIXPublicKey(b)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(aDER, bDER)
+}
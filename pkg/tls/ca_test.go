@@ -1,10 +1,20 @@
 package tls
 
 import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
 	"reflect"
 	"testing"
 	"time"
 
+	"github.com/cloudflare/cfssl/csr"
 	"github.com/cloudflare/cfssl/helpers"
 )
 
@@ -46,3 +56,169 @@ func TestNewCACert(t *testing.T) {
 		t.Errorf("expected expiration date %q, got %q", expectedExpiration, parsedCert.NotAfter)
 	}
 }
+
+// newIntermediateCA creates an intermediate CA signed by the given root CA
+func newIntermediateCA(t *testing.T, rootKey, rootCert []byte) (key, cert []byte) {
+	root, err := helpers.ParseCertificatePEM(rootCert)
+	if err != nil {
+		t.Fatalf("error parsing root CA certificate: %v", err)
+	}
+	rootPriv, err := helpers.ParsePrivateKeyPEM(rootKey)
+	if err != nil {
+		t.Fatalf("error parsing root CA key: %v", err)
+	}
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("error generating intermediate CA key: %v", err)
+	}
+	tpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(2),
+		Subject:               pkix.Name{CommonName: "intermediate"},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tpl, root, priv.Public(), rootPriv)
+	if err != nil {
+		t.Fatalf("error creating intermediate CA certificate: %v", err)
+	}
+	key = pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(priv)})
+	cert = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	return key, cert
+}
+
+func writeTestFile(t *testing.T, dir, name string, data []byte) string {
+	file := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(file, data, 0600); err != nil {
+		t.Fatalf("error writing file: %v", err)
+	}
+	return file
+}
+
+func TestReadIntermediateCA(t *testing.T) {
+	dir, err := ioutil.TempDir("", "intermediate-ca")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer cleanup(dir, t)
+
+	rootKey, rootCert, err := NewCACert("test/ca-csr.json", "root", "1h")
+	if err != nil {
+		t.Fatalf("error creating root CA: %v", err)
+	}
+	_, otherRootCert, err := NewCACert("test/ca-csr.json", "other-root", "1h")
+	if err != nil {
+		t.Fatalf("error creating root CA: %v", err)
+	}
+	key, cert := newIntermediateCA(t, rootKey, rootCert)
+	otherKey, _ := newIntermediateCA(t, rootKey, rootCert)
+
+	certFile := writeTestFile(t, dir, "intermediate.pem", cert)
+	keyFile := writeTestFile(t, dir, "intermediate-key.pem", key)
+	chainFile := writeTestFile(t, dir, "chain.pem", rootCert)
+
+	gotKey, gotCert, bundle, err := ReadIntermediateCA(certFile, keyFile, chainFile)
+	if err != nil {
+		t.Fatalf("unexpected error reading intermediate CA: %v", err)
+	}
+	if !reflect.DeepEqual(gotKey, key) {
+		t.Errorf("returned key is not the intermediate CA key")
+	}
+	if !reflect.DeepEqual(gotCert, cert) {
+		t.Errorf("returned certificate is not the intermediate CA certificate")
+	}
+	certs, err := helpers.ParseCertificatesPEM(bundle)
+	if err != nil {
+		t.Fatalf("error parsing bundle: %v", err)
+	}
+	if len(certs) != 2 {
+		t.Fatalf("expected 2 certificates in the bundle, but got %d", len(certs))
+	}
+	if certs[0].Subject.CommonName != "intermediate" || certs[1].Subject.CommonName != "root" {
+		t.Errorf("unexpected bundle: %q, %q", certs[0].Subject.CommonName, certs[1].Subject.CommonName)
+	}
+
+	tests := []struct {
+		name      string
+		certFile  string
+		keyFile   string
+		chainFile string
+	}{
+		{
+			name:      "key does not match",
+			certFile:  certFile,
+			keyFile:   writeTestFile(t, dir, "other-key.pem", otherKey),
+			chainFile: chainFile,
+		},
+		{
+			name:      "chain does not contain the root",
+			certFile:  certFile,
+			keyFile:   keyFile,
+			chainFile: writeTestFile(t, dir, "other-chain.pem", otherRootCert),
+		},
+		{
+			name:      "not a CA certificate",
+			certFile:  writeTestFile(t, dir, "leaf.pem", mustNewLeafCert(t, key, cert)),
+			keyFile:   keyFile,
+			chainFile: chainFile,
+		},
+		{
+			name:      "empty certificate",
+			certFile:  writeTestFile(t, dir, "empty.pem", []byte{}),
+			keyFile:   keyFile,
+			chainFile: chainFile,
+		},
+		{
+			name:      "missing chain",
+			certFile:  certFile,
+			keyFile:   keyFile,
+			chainFile: filepath.Join(dir, "missing.pem"),
+		},
+	}
+	for _, test := range tests {
+		if _, _, _, err := ReadIntermediateCA(test.certFile, test.keyFile, test.chainFile); err == nil {
+			t.Errorf("%s: expected an error, but didn't get one", test.name)
+		}
+	}
+}
+
+func mustNewLeafCert(t *testing.T, caKey, caCert []byte) []byte {
+	_, cert, err := NewCert(&CA{Key: caKey, Cert: caCert}, *buildReq("leaf", nil, nil), time.Hour)
+	if err != nil {
+		t.Fatalf("error creating certificate: %v", err)
+	}
+	return cert
+}
+
+func TestNewCustomCACert(t *testing.T) {
+	subject := &Subject{Country: "CA", Locality: "Toronto", Organization: "Acme", OrganizationalUnit: "Platform"}
+	_, cert, err := NewCustomCACert("test/ca-csr.json", "someCommonName", "1h", &csr.BasicKeyRequest{A: "ecdsa", S: 384}, subject)
+	if err != nil {
+		t.Fatalf("error creating CA cert: %v", err)
+	}
+	parsedCert, err := helpers.ParseCertificatePEM(cert)
+	if err != nil {
+		t.Fatalf("error parsing certificate: %v", err)
+	}
+	pub, ok := parsedCert.PublicKey.(*ecdsa.PublicKey)
+	if !ok {
+		t.Fatalf("expected an ECDSA key, but got %T", parsedCert.PublicKey)
+	}
+	if pub.Curve.Params().BitSize != 384 {
+		t.Errorf("expected a P-384 key, but got %d bits", pub.Curve.Params().BitSize)
+	}
+	expected := pkix.Name{
+		CommonName:         "someCommonName",
+		Country:            []string{"CA"},
+		Locality:           []string{"Toronto"},
+		Organization:       []string{"Acme"},
+		OrganizationalUnit: []string{"Platform"},
+	}
+	got := parsedCert.Subject
+	got.Names = nil
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected subject %v, but got %v", expected, got)
+	}
+}
@@ -1,6 +1,8 @@
 package tls
 
 import (
+	"crypto/ecdsa"
+	"crypto/rsa"
 	"crypto/x509"
 	"fmt"
 	"io/ioutil"
@@ -11,11 +13,8 @@ import (
 
 	"github.com/apprenda/kismatic/pkg/util"
 	"github.com/cloudflare/cfssl/cli/genkey"
-	"github.com/cloudflare/cfssl/config"
 	"github.com/cloudflare/cfssl/csr"
 	"github.com/cloudflare/cfssl/helpers"
-	"github.com/cloudflare/cfssl/signer"
-	"github.com/cloudflare/cfssl/signer/local"
 )
 
 // CA contains information about the Certificate Authority
@@ -26,6 +25,16 @@ type CA struct {
 	Password string
 	// Cert is the CA's public certificate.
 	Cert []byte
+	// Signer issues the certificates of the CA. When nil, the certificates are
+	// signed locally using the CA's private key.
+	Signer Signer
+}
+
+func (ca *CA) signer() Signer {
+	if ca.Signer != nil {
+		return ca.Signer
+	}
+	return LocalSigner{CA: ca}
 }
 
 // NewCert creates a new certificate/key pair using the CertificateAuthority provided
@@ -35,37 +44,23 @@ func NewCert(ca *CA, req csr.CertificateRequest, expiry time.Duration) (key, cer
 	if err != nil {
 		return nil, nil, fmt.Errorf("error processing CSR: %v", err)
 	}
-	// Get CA private key
-	caPriv, err := helpers.ParsePrivateKeyPEMWithPassword(ca.Key, []byte(ca.Password))
-	if err != nil {
-		return nil, nil, fmt.Errorf("error parsing private key: %v", err)
-	}
-	// Parse CA Cert
-	caCert, err := helpers.ParseCertificatePEM(ca.Cert)
+	cert, err = ca.signer().Sign(csrBytes, expiry)
 	if err != nil {
-		return nil, nil, fmt.Errorf("error parsing CA cert: %v", err)
+		return nil, nil, err
 	}
-	sigAlgo := signer.DefaultSigAlgo(caPriv)
-	// Build CA configuration
-	caConfig := &config.Signing{
-		Default: config.DefaultConfig(),
-	}
-	caConfig.Default.Expiry = expiry
-	caConfig.Default.ExpiryString = expiry.String()
-	// Create signer using CA
-	s, err := local.NewSigner(caPriv, caCert, sigAlgo, caConfig)
-	if err != nil {
-		return nil, nil, fmt.Errorf("error creating signer: %v", err)
-	}
-	// Generate cert using CA signer
-	signReq := signer.SignRequest{
-		Request: string(csrBytes),
+	return key, cert, nil
+}
+
+// WriteCACert writes the certificate of a CA whose private key is not
+// available locally
+func WriteCACert(cert []byte, name, dir string) error {
+	if err := util.CreateDir(dir, 0744); err != nil {
+		return err
 	}
-	cert, err = s.Sign(signReq)
-	if err != nil {
-		return nil, nil, fmt.Errorf("error signing certificate: %v", err)
+	if err := ioutil.WriteFile(filepath.Join(dir, certName(name)), cert, 0644); err != nil {
+		return fmt.Errorf("error writing certificate: %v", err)
 	}
-	return key, cert, nil
+	return nil
 }
 
 // WriteCert writes cert and key files
@@ -89,17 +84,33 @@ func WriteCert(key, cert []byte, name, dir string) error {
 }
 
 // ReadCert reads the certificate with the given name in the provided directory.
+// If the file contains a certificate bundle, the first certificate is returned.
 func ReadCert(name, dir string) (*x509.Certificate, error) {
 	certPath := filepath.Join(dir, certName(name))
 	certBytes, err := ioutil.ReadFile(certPath)
 	if err != nil {
 		return nil, err
 	}
-	cert, err := helpers.ParseCertificatePEM(certBytes)
+	certs, err := helpers.ParseCertificatesPEM(certBytes)
 	if err != nil {
 		return nil, err
 	}
-	return cert, nil
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no certificate found in %q", certPath)
+	}
+	return certs[0], nil
+}
+
+// CertExists returns true if the certificate with the given name exists in the directory
+func CertExists(name, dir string) (bool, error) {
+	_, err := os.Stat(filepath.Join(dir, certName(name)))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
 // CertKeyPairExists returns true if a key and matching certificate exist.
@@ -189,6 +200,55 @@ func CertValid(commonName string, SANs []string, organizations []string, name, d
 	return warn, nil
 }
 
+// CertKeyAndSubjectValid returns a list of validation warnings if the key or the
+// subject of the certificate do not match the expected values.
+// Validation rules:
+// - key algorithm and size: must match exactly
+// - subject: the fields that are set in the expected subject must be in the cert's subject
+// Returns an error if there is an issue reading or parsing the certificate
+func CertKeyAndSubjectValid(keyRequest csr.KeyRequest, subject *Subject, name, dir string) (warn []error, err error) {
+	cert, err := ReadCert(name, dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading cert %s: %v", name, err)
+	}
+	cn := certName(name)
+
+	var algo string
+	var size int
+	switch pub := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		algo, size = "rsa", pub.N.BitLen()
+	case *ecdsa.PublicKey:
+		algo, size = "ecdsa", pub.Curve.Params().BitSize
+	default:
+		algo = "unknown"
+	}
+	if algo != keyRequest.Algo() || size != keyRequest.Size() {
+		warn = append(warn, fmt.Errorf("Certificate %q: key validation failed\n    expected %s %d, instead got %s %d", cn, keyRequest.Algo(), keyRequest.Size(), algo, size))
+	}
+
+	if subject == nil {
+		return warn, nil
+	}
+	fields := []struct {
+		name     string
+		expected string
+		actual   []string
+	}{
+		{"country", subject.Country, cert.Subject.Country},
+		{"state", subject.State, cert.Subject.Province},
+		{"locality", subject.Locality, cert.Subject.Locality},
+		{"organization", subject.Organization, cert.Subject.Organization},
+		{"organizational unit", subject.OrganizationalUnit, cert.Subject.OrganizationalUnit},
+	}
+	for _, f := range fields {
+		if f.expected != "" && !util.Subset([]string{f.expected}, f.actual) {
+			warn = append(warn, fmt.Errorf("Certificate %q: %s validation failed\n    expected %q, instead got %v", cn, f.name, f.expected, f.actual))
+		}
+	}
+	return warn, nil
+}
+
 func keyName(s string) string { return fmt.Sprintf("%s-key.pem", s) }
 
 func certName(s string) string { return fmt.Sprintf("%s.pem", s) }
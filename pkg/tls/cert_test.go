@@ -350,3 +350,99 @@ func cleanup(dir string, t *testing.T) {
 		t.Fatalf("failed cleaning up temp directory: %v", err)
 	}
 }
+
+func TestCertKeyAndSubjectValid(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "cert-tests")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer cleanup(tempDir, t)
+
+	key, caCert, err := NewCACert("test/ca-csr.json", "someCN", "12345h")
+	if err != nil {
+		t.Fatalf("error creating CA: %v", err)
+	}
+	ca := &CA{
+		Key:  key,
+		Cert: caCert,
+	}
+	req := buildReq("someCN", nil, []string{"system:nodes"})
+	req.KeyRequest = &csr.BasicKeyRequest{A: "ecdsa", S: 256}
+	req.Names = append(req.Names, csr.Name{C: "CA", L: "Toronto", O: "Acme"})
+	key, cert, err := NewCert(ca, *req, time.Hour)
+	if err != nil {
+		t.Fatalf("error creating certificate: %v", err)
+	}
+	if err := WriteCert(key, cert, "cert", tempDir); err != nil {
+		t.Fatalf("error writing certificate: %v", err)
+	}
+
+	tests := []struct {
+		keyRequest *csr.BasicKeyRequest
+		subject    *Subject
+		valid      bool
+	}{
+		{
+			keyRequest: &csr.BasicKeyRequest{A: "ecdsa", S: 256},
+			valid:      true,
+		},
+		{
+			keyRequest: &csr.BasicKeyRequest{A: "ecdsa", S: 256},
+			subject:    &Subject{Country: "CA", Organization: "Acme"},
+			valid:      true,
+		},
+		{
+			keyRequest: &csr.BasicKeyRequest{A: "ecdsa", S: 384},
+		},
+		{
+			keyRequest: &csr.BasicKeyRequest{A: "rsa", S: 2048},
+		},
+		{
+			keyRequest: &csr.BasicKeyRequest{A: "ecdsa", S: 256},
+			subject:    &Subject{OrganizationalUnit: "Platform"},
+		},
+		{
+			keyRequest: &csr.BasicKeyRequest{A: "ecdsa", S: 256},
+			subject:    &Subject{Locality: "Troy"},
+		},
+	}
+	for i, test := range tests {
+		warn, err := CertKeyAndSubjectValid(test.keyRequest, test.subject, "cert", tempDir)
+		if err != nil {
+			t.Errorf("Unexpected error for %d: %v", i, err)
+		}
+		if test.valid && len(warn) > 0 {
+			t.Errorf("Test %d - Expected a valid certificate, but got validation warnings: %v", i, warn)
+		}
+		if !test.valid && len(warn) == 0 {
+			t.Errorf("Test %d - Expected an invalid certificate, but did not get validation warnings", i)
+		}
+	}
+
+	if _, err := CertKeyAndSubjectValid(tests[0].keyRequest, nil, "doesnotexist", tempDir); err == nil {
+		t.Errorf("expected an error, as the certificate does not exist.")
+	}
+}
+
+func TestReadCertWithoutCertificate(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "cert-tests")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer cleanup(tempDir, t)
+
+	writeTestFile(t, tempDir, "empty.pem", []byte{})
+	if _, err := ReadCert("empty", tempDir); err == nil {
+		t.Errorf("expected an error when the file does not contain a certificate")
+	}
+
+	// Signing with a CA without a certificate must fail
+	key, _, err := NewCACert("test/ca-csr.json", "root", "1h")
+	if err != nil {
+		t.Fatalf("error creating CA: %v", err)
+	}
+	ca := &CA{Key: key, Cert: []byte{}}
+	if _, _, err := NewCert(ca, *buildReq("leaf", nil, nil), time.Hour); err == nil {
+		t.Errorf("expected an error when the CA does not contain a certificate")
+	}
+}
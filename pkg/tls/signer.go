@@ -0,0 +1,177 @@
+package tls
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cloudflare/cfssl/config"
+	"github.com/cloudflare/cfssl/helpers"
+	"github.com/cloudflare/cfssl/signer"
+	"github.com/cloudflare/cfssl/signer/local"
+)
+
+// A Signer issues certificates for certificate signing requests
+type Signer interface {
+	// Sign returns the PEM encoded certificate for the PEM encoded certificate
+	// signing request. The certificate is valid for the given duration, unless
+	// the validity period is controlled by the signer.
+	Sign(csrPEM []byte, expiry time.Duration) ([]byte, error)
+}
+
+// LocalSigner signs certificates in-process using the private key of the CA
+type LocalSigner struct {
+	CA *CA
+}
+
+// Sign the certificate signing request with the private key of the CA
+func (ls LocalSigner) Sign(csrPEM []byte, expiry time.Duration) ([]byte, error) {
+	// Get CA private key
+	caPriv, err := helpers.ParsePrivateKeyPEMWithPassword(ls.CA.Key, []byte(ls.CA.Password))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing private key: %v", err)
+	}
+	// Parse CA Cert, which might be followed by its chain
+	caCerts, err := helpers.ParseCertificatesPEM(ls.CA.Cert)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing CA cert: %v", err)
+	}
+	if len(caCerts) == 0 {
+		return nil, fmt.Errorf("error parsing CA cert: no certificate found")
+	}
+	caCert := caCerts[0]
+	sigAlgo := signer.DefaultSigAlgo(caPriv)
+	// Build CA configuration
+	caConfig := &config.Signing{
+		Default: config.DefaultConfig(),
+	}
+	caConfig.Default.Expiry = expiry
+	caConfig.Default.ExpiryString = expiry.String()
+	// Create signer using CA
+	s, err := local.NewSigner(caPriv, caCert, sigAlgo, caConfig)
+	if err != nil {
+		return nil, fmt.Errorf("error creating signer: %v", err)
+	}
+	// Generate cert using CA signer
+	signReq := signer.SignRequest{
+		Request: string(csrPEM),
+	}
+	cert, err := s.Sign(signReq)
+	if err != nil {
+		return nil, fmt.Errorf("error signing certificate: %v", err)
+	}
+	return cert, nil
+}
+
+// RemoteSigner signs certificates using the API of a remote CFSSL server, so
+// that the private key of the CA is never available locally. The validity
+// period of the certificates is controlled by the signing profile of the server.
+type RemoteSigner struct {
+	// URL of the CFSSL server, e.g. https://cfssl.example.com:8888
+	URL string
+	// Profile is the signing profile to use. The default profile of the
+	// server is used when empty.
+	Profile string
+	// AuthKey is the hex encoded key used to authenticate the signing requests.
+	// The requests are not authenticated when empty.
+	AuthKey string
+	// Client is the HTTP client used to reach the server. The default client
+	// is used when nil.
+	Client *http.Client
+}
+
+// cfsslResponse is the envelope of the responses of the CFSSL API
+type cfsslResponse struct {
+	Success bool `json:"success"`
+	Result  struct {
+		Certificate string `json:"certificate"`
+	} `json:"result"`
+	Errors []struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// Sign the certificate signing request using the sign endpoint of the CFSSL
+// API, or the authsign endpoint when an AuthKey is set
+func (rs RemoteSigner) Sign(csrPEM []byte, expiry time.Duration) ([]byte, error) {
+	req, err := json.Marshal(map[string]string{
+		"certificate_request": string(csrPEM),
+		"profile":             rs.Profile,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling sign request: %v", err)
+	}
+	endpoint := "sign"
+	if rs.AuthKey != "" {
+		key, err := hex.DecodeString(rs.AuthKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid auth key: %v", err)
+		}
+		mac := hmac.New(sha256.New, key)
+		mac.Write(req)
+		// []byte fields are base64 encoded when marshalled
+		req, err = json.Marshal(struct {
+			Token   []byte `json:"token"`
+			Request []byte `json:"request"`
+		}{mac.Sum(nil), req})
+		if err != nil {
+			return nil, fmt.Errorf("error marshalling authenticated sign request: %v", err)
+		}
+		endpoint = "authsign"
+	}
+	cert, err := rs.post(endpoint, req)
+	if err != nil {
+		return nil, fmt.Errorf("error signing certificate: %v", err)
+	}
+	return cert, nil
+}
+
+// Certificate returns the PEM encoded certificate of the CA of the CFSSL server
+func (rs RemoteSigner) Certificate() ([]byte, error) {
+	req, err := json.Marshal(map[string]string{"profile": rs.Profile})
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling info request: %v", err)
+	}
+	cert, err := rs.post("info", req)
+	if err != nil {
+		return nil, fmt.Errorf("error getting CA certificate: %v", err)
+	}
+	return cert, nil
+}
+
+// post sends the request to the CFSSL API endpoint, and returns the certificate
+// found in the response
+func (rs RemoteSigner) post(endpoint string, req []byte) ([]byte, error) {
+	client := rs.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	url := strings.TrimSuffix(rs.URL, "/") + "/api/v1/cfssl/" + endpoint
+	resp, err := client.Post(url, "application/json", bytes.NewReader(req))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var r cfsslResponse
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, fmt.Errorf("error decoding response from %s (status %d): %v", url, resp.StatusCode, err)
+	}
+	if !r.Success {
+		msgs := []string{}
+		for _, e := range r.Errors {
+			msgs = append(msgs, fmt.Sprintf("%s (code %d)", e.Message, e.Code))
+		}
+		return nil, fmt.Errorf("request to %s failed: %s", url, strings.Join(msgs, ", "))
+	}
+	if r.Result.Certificate == "" {
+		return nil, fmt.Errorf("response from %s does not contain a certificate", url)
+	}
+	return []byte(r.Result.Certificate), nil
+}
@@ -0,0 +1,136 @@
+package tls
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cloudflare/cfssl/helpers"
+)
+
+// newFakeCFSSL starts an HTTP server that implements the info, sign and authsign
+// endpoints of the CFSSL API, using the given CA. Signing requests must be
+// authenticated when the authKey is not empty.
+func newFakeCFSSL(t *testing.T, ca *CA, authKey []byte) *httptest.Server {
+	respond := func(w http.ResponseWriter, cert []byte, err error) {
+		resp := map[string]interface{}{"success": err == nil, "result": map[string]string{"certificate": string(cert)}}
+		if err != nil {
+			resp["errors"] = []map[string]interface{}{{"code": 1000, "message": err.Error()}}
+			w.WriteHeader(http.StatusBadRequest)
+		}
+		json.NewEncoder(w).Encode(resp)
+	}
+	sign := func(w http.ResponseWriter, body []byte) {
+		var req struct {
+			CertificateRequest string `json:"certificate_request"`
+		}
+		if err := json.Unmarshal(body, &req); err != nil {
+			respond(w, nil, err)
+			return
+		}
+		cert, err := LocalSigner{CA: ca}.Sign([]byte(req.CertificateRequest), time.Hour)
+		respond(w, cert, err)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/cfssl/info", func(w http.ResponseWriter, r *http.Request) {
+		respond(w, ca.Cert, nil)
+	})
+	mux.HandleFunc("/api/v1/cfssl/sign", func(w http.ResponseWriter, r *http.Request) {
+		if authKey != nil {
+			respond(w, nil, errUnauthorized)
+			return
+		}
+		var body json.RawMessage
+		json.NewDecoder(r.Body).Decode(&body)
+		sign(w, body)
+	})
+	mux.HandleFunc("/api/v1/cfssl/authsign", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Token   []byte `json:"token"`
+			Request []byte `json:"request"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		mac := hmac.New(sha256.New, authKey)
+		mac.Write(req.Request)
+		if !hmac.Equal(mac.Sum(nil), req.Token) {
+			respond(w, nil, errUnauthorized)
+			return
+		}
+		sign(w, req.Request)
+	})
+	return httptest.NewServer(mux)
+}
+
+var errUnauthorized = errors.New("invalid token")
+
+func TestRemoteSigner(t *testing.T) {
+	key, cert, err := NewCACert("test/ca-csr.json", "remote", "1h")
+	if err != nil {
+		t.Fatalf("error creating CA: %v", err)
+	}
+	authKey := []byte("0123456789abcdef")
+	tests := []struct {
+		name        string
+		serverKey   []byte
+		clientKey   string
+		expectError bool
+	}{
+		{
+			name: "unauthenticated",
+		},
+		{
+			name:      "authenticated",
+			serverKey: authKey,
+			clientKey: hex.EncodeToString(authKey),
+		},
+		{
+			name:        "missing auth key",
+			serverKey:   authKey,
+			expectError: true,
+		},
+		{
+			name:        "wrong auth key",
+			serverKey:   authKey,
+			clientKey:   hex.EncodeToString([]byte("fedcba9876543210")),
+			expectError: true,
+		},
+	}
+	for _, test := range tests {
+		server := newFakeCFSSL(t, &CA{Key: key, Cert: cert}, test.serverKey)
+		rs := RemoteSigner{URL: server.URL, AuthKey: test.clientKey}
+
+		caCert, err := rs.Certificate()
+		if err != nil {
+			t.Errorf("%s: error getting CA certificate: %v", test.name, err)
+		} else if string(caCert) != string(cert) {
+			t.Errorf("%s: got the wrong CA certificate", test.name)
+		}
+
+		_, leaf, err := NewCert(&CA{Cert: cert, Signer: rs}, *buildReq("leaf", []string{"10.0.0.1"}, nil), time.Hour)
+		server.Close()
+		if test.expectError {
+			if err == nil {
+				t.Errorf("%s: expected an error, but didn't get one", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		parsed, err := helpers.ParseCertificatePEM(leaf)
+		if err != nil {
+			t.Errorf("%s: error parsing certificate: %v", test.name, err)
+			continue
+		}
+		if parsed.Issuer.CommonName != "remote" {
+			t.Errorf("%s: expected the certificate to be issued by the remote CA, but got %q", test.name, parsed.Issuer.CommonName)
+		}
+	}
+}
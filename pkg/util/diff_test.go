@@ -0,0 +1,44 @@
+package util
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		old      string
+		new      string
+		expected []DiffLine
+	}{
+		{
+			old:      "",
+			new:      "",
+			expected: []DiffLine{},
+		},
+		{
+			old:      "a\nb\n",
+			new:      "a\nb\n",
+			expected: []DiffLine{{" ", "a"}, {" ", "b"}},
+		},
+		{
+			old:      "a\nb\nc\n",
+			new:      "a\nc\nd\n",
+			expected: []DiffLine{{" ", "a"}, {"-", "b"}, {" ", "c"}, {"+", "d"}},
+		},
+		{
+			old:      "",
+			new:      "a\n",
+			expected: []DiffLine{{"+", "a"}},
+		},
+	}
+	for i, test := range tests {
+		diff := DiffLines(test.old, test.new)
+		if !reflect.DeepEqual(diff, test.expected) {
+			t.Errorf("test %d: expected %v, got %v", i, test.expected, diff)
+		}
+		if HasChanges(diff) != (test.old != test.new) {
+			t.Errorf("test %d: unexpected result from HasChanges", i)
+		}
+	}
+}
@@ -72,3 +72,13 @@ func Base64String(path string) (string, error) {
 
 	return fileEncoded, nil
 }
+
+// MessageWriter returns the writer for the human-readable messages printed
+// while running ansible. When using the json output format, out is reserved
+// for the json records, and the messages are written to errOut instead.
+func MessageWriter(out, errOut io.Writer, outputFormat string) io.Writer {
+	if outputFormat == "json" {
+		return errOut
+	}
+	return out
+}